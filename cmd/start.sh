@@ -2,4 +2,5 @@
 sleep 10
 npm install
 node esbuild.js &
-/go/bin/air
+# exec repassa os sinais (como o SIGTERM do Docker) diretamente para o processo da aplicação.
+exec /go/bin/air
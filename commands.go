@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"lucienne/config"
+	"lucienne/internal/infra/database"
+	"os"
+	"text/tabwriter"
+)
+
+// seed aplica o conjunto de seeds informado, ou o conjunto padrão do ambiente atual.
+func seed(args []string) {
+	seeder := database.NewSeeder(subFS(SeedsPath))
+
+	if len(args) > 0 && args[0] == "--list" {
+		sets, err := seeder.Sets()
+		if err != nil {
+			log.Fatalf("Erro ao listar conjuntos de seeds: %v", err)
+		}
+		for _, set := range sets {
+			fmt.Println(set)
+		}
+		return
+	}
+
+	set, ok := database.SeedSetFor(config.Application.Environment)
+	if len(args) > 0 {
+		set, ok = args[0], true
+	}
+	if !ok {
+		log.Fatalf("Nenhum conjunto de seeds definido para o ambiente %q. Informe um conjunto: lucienne seed <set>", config.Application.Environment)
+	}
+
+	setupDatabase()
+	applySeedSet(set)
+}
+
+func applySeedSet(set string) {
+	seeder := database.NewSeeder(subFS(SeedsPath))
+	if err := seeder.Apply(context.Background(), set); err != nil {
+		log.Fatalf("Erro ao aplicar seeds: %v", err)
+	}
+}
+
+// configCommand executa os subcomandos de inspeção da configuração.
+func configCommand(args []string) {
+	if len(args) == 0 {
+		log.Fatalf("Informe um subcomando de config.\n%s", usage)
+	}
+
+	// O erro é tratado por cada subcomando, já que a configuração pode ser inspecionada mesmo quando inválida.
+	loadErr := config.EnvVariables.Load()
+
+	switch args[0] {
+	case "check":
+		if loadErr != nil {
+			fmt.Fprintln(os.Stderr, loadErr)
+			os.Exit(1)
+		}
+		fmt.Println("Configuração válida.")
+	case "print":
+		printConfig()
+		if loadErr != nil {
+			os.Exit(1)
+		}
+	case "example":
+		example, err := config.EnvVariables.Example()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Print(example)
+	default:
+		log.Fatalf("Subcomando de config desconhecido: %s\n%s", args[0], usage)
+	}
+}
+
+// printConfig lista cada variável com sua origem e valor, ocultando segredos.
+func printConfig() {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tSOURCE\tVALUE\tPROBLEM")
+	for _, variable := range config.EnvVariables.Describe() {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", variable.Name, variable.Source, variable.RedactedValue(), variable.Problem)
+	}
+	writer.Flush()
+}
@@ -1,41 +1,59 @@
 package config
 
 import (
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
 )
 
 type application struct {
 	RootPath    string
 	Environment string
+	// FS é o sistema de arquivos de onde a aplicação lê migrações, seeds, views e assets compilados.
+	// Por padrão aponta para RootPath, mas pode ser trocado por arquivos embutidos no binário.
+	FS fs.FS
 }
 
 var Application = application{}
 
 func (app *application) Configure(environment string) {
 	app.RootPath = getRootPath()
+	app.FS = os.DirFS(app.RootPath)
 	app.Environment = environment
 	if app.Environment == "" {
 		app.Environment = "development"
 	}
 }
 
+// UseFS substitui o sistema de arquivos da aplicação, como quando os arquivos estão embutidos no binário.
+func (app *application) UseFS(fsys fs.FS) {
+	app.FS = fsys
+}
+
 func (app *application) IsDevelopment() bool {
 	return app.Environment == "development"
 }
 
+// getRootPath procura o go.mod a partir do diretório atual, subindo pelos diretórios pai.
+// Quando não o encontra, como ao executar um binário fora do código-fonte, usa o diretório atual.
 func getRootPath() string {
-	path, err := os.Getwd()
-	for {
-		_, err := os.Stat(path + "/go.mod")
-		if err == nil {
-			break
-		}
-		os.Chdir("..")
-		path, _ = os.Getwd()
-	}
+	workingDir, err := os.Getwd()
 	if err != nil {
 		log.Fatalf("An error ocurred when trying to get application root path: %s", err.Error())
 	}
-	return path
+
+	path := workingDir
+	for {
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+			os.Chdir(path)
+			return path
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return workingDir
+		}
+		path = parent
+	}
 }
@@ -2,8 +2,8 @@ package config
 
 import (
 	"encoding/json"
+	"io/fs"
 	"log"
-	"os"
 	"path"
 	"strings"
 )
@@ -19,20 +19,22 @@ type assetsConfig struct {
 
 var Assets = assetsConfig{}
 
+// Configure sets the assets paths and loads the build mapping file.
+// The build file is read through Application.FS, so buildFilePath must be relative to the application root.
 func (assets *assetsConfig) Configure(assetsPath string, compiledAssetsPath string, buildFilePath string) {
 	assets.OriginRelativePath = removeEndingSlash(assetsPath)
 	assets.CompiledRelativePath = removeEndingSlash(compiledAssetsPath)
 	assets.OriginFullPath = path.Join(Application.RootPath, assets.OriginRelativePath)
 	assets.CompiledFullPath = path.Join(Application.RootPath, assets.CompiledRelativePath)
 	assets.BuildFilePath = path.Join(Application.RootPath, buildFilePath)
-	assets.AssetsMapping = loadAssetsMapping()
+	assets.AssetsMapping = loadAssetsMapping(path.Clean(buildFilePath))
 }
 
-func loadAssetsMapping() map[string]string {
+func loadAssetsMapping(buildFilePath string) map[string]string {
 	jsonAssets := map[string]string{}
 	assetsWithPath := make(map[string]string)
 
-	buildFile, err := os.ReadFile(Assets.BuildFilePath)
+	buildFile, err := fs.ReadFile(Application.FS, buildFilePath)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"testing/fstest"
 )
 
 func TestAssetsConfigure(t *testing.T) {
@@ -94,4 +95,17 @@ func TestAssetsConfigure(t *testing.T) {
 			t.Errorf("Expected: %#v, Got: %#v", expectedResult, Assets.AssetsMapping)
 		}
 	})
+
+	t.Run("loads build json mapping file from the application file system", func(t *testing.T) {
+		Application.UseFS(fstest.MapFS{
+			"public/build.json": {Data: []byte(`{"assets/app.js": "public/assets/app-XYZ.js"}`)},
+		})
+		t.Cleanup(func() { Application.Configure("test") })
+
+		Assets.Configure("assets", "public/assets", "public/build.json")
+		expectedResult := map[string]string{"app.js": "app-XYZ.js"}
+		if !maps.Equal(expectedResult, Assets.AssetsMapping) {
+			t.Errorf("Expected: %#v, Got: %#v", expectedResult, Assets.AssetsMapping)
+		}
+	})
 }
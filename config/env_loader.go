@@ -0,0 +1,388 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LoadError lists every environment variable that could not be loaded.
+type LoadError struct {
+	Problems []string
+}
+
+func (e *LoadError) Error() string {
+	return "Error on trying to load these variables:\n- " + strings.Join(e.Problems, "\n- ")
+}
+
+type structTags struct {
+	IsRequired   bool
+	IsSecret     bool
+	DefaultValue string
+	EnvName      string
+	Validate     string
+	Separator    string
+}
+
+// envField is a leaf field of a config struct bound to an environment variable.
+type envField struct {
+	Tags  structTags
+	Value reflect.Value
+}
+
+var (
+	durationType = reflect.TypeFor[time.Duration]()
+	urlType      = reflect.TypeFor[url.URL]()
+)
+
+// loadEnv fills the struct pointed by target with values from the environment, falling back to the dotenv values.
+// It returns a description of every variable, even when some of them are invalid.
+func loadEnv(target any, dotenv map[string]string) ([]EnvVariable, error) {
+	fields, err := collectEnvFields(reflect.ValueOf(target).Elem(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	variables := make([]EnvVariable, 0, len(fields))
+	for _, field := range fields {
+		variable, err := loadEnvField(field, dotenv)
+		if err != nil {
+			variable.Problem = err.Error()
+			problems = append(problems, fmt.Sprintf("%s: %s", field.Tags.EnvName, err))
+		}
+		variables = append(variables, variable)
+	}
+
+	if len(problems) > 0 {
+		return variables, &LoadError{Problems: problems}
+	}
+	return variables, nil
+}
+
+// collectEnvFields walks the struct, descending into nested structs tagged with a prefix.
+func collectEnvFields(structValue reflect.Value, prefix string) ([]envField, error) {
+	var fields []envField
+	structType := structValue.Type()
+
+	for i := range structType.NumField() {
+		structField := structType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		fieldValue := structValue.Field(i)
+		if nestedPrefix, ok := structField.Tag.Lookup("prefix"); ok && structField.Type.Kind() == reflect.Struct {
+			nested, err := collectEnvFields(fieldValue, prefix+nestedPrefix)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+
+		tags, err := extractStructTags(structField, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if tags.EnvName == "" {
+			continue
+		}
+		fields = append(fields, envField{Tags: tags, Value: fieldValue})
+	}
+
+	return fields, nil
+}
+
+func extractStructTags(structField reflect.StructField, prefix string) (structTags structTags, err error) {
+	structTags.IsRequired = false
+	isRequiredStructTag := structField.Tag.Get("required")
+	if isRequiredStructTag != "" {
+		structTags.IsRequired, err = strconv.ParseBool(isRequiredStructTag)
+		if err != nil {
+			return structTags, fmt.Errorf("invalid required tag on %s: %w", structField.Name, err)
+		}
+	}
+
+	if isSecretStructTag := structField.Tag.Get("secret"); isSecretStructTag != "" {
+		structTags.IsSecret, err = strconv.ParseBool(isSecretStructTag)
+		if err != nil {
+			return structTags, fmt.Errorf("invalid secret tag on %s: %w", structField.Name, err)
+		}
+	}
+
+	structTags.DefaultValue = structField.Tag.Get("default")
+	structTags.Validate = structField.Tag.Get("validate")
+	structTags.Separator = structField.Tag.Get("separator")
+	if structTags.Separator == "" {
+		structTags.Separator = ","
+	}
+	if name := structField.Tag.Get("name"); name != "" {
+		structTags.EnvName = prefix + name
+	}
+
+	return structTags, nil
+}
+
+func loadEnvField(field envField, dotenv map[string]string) (EnvVariable, error) {
+	variable := EnvVariable{
+		Name:     field.Tags.EnvName,
+		Default:  field.Tags.DefaultValue,
+		Required: field.Tags.IsRequired,
+		Secret:   field.Tags.IsSecret,
+		Source:   SourceUnset,
+	}
+
+	envValue, source, err := lookupEnv(field.Tags.EnvName, dotenv)
+	if err != nil {
+		return variable, err
+	}
+	variable.Source = source
+
+	if envValue == "" {
+		if field.Tags.IsRequired {
+			return variable, fmt.Errorf("is required")
+		}
+		envValue = field.Tags.DefaultValue
+		if envValue != "" {
+			variable.Source = SourceDefault
+		}
+	}
+	variable.Value = envValue
+
+	if err := setFieldValue(field.Value, envValue, field.Tags.Separator); err != nil {
+		return variable, err
+	}
+	if envValue == "" {
+		return variable, nil
+	}
+	return variable, validateField(field.Value, field.Tags.Validate)
+}
+
+// lookupEnv returns the variable value and where it came from.
+// The environment takes precedence over the dotenv values, and both accept a <name>_FILE variant pointing to a file with the value.
+func lookupEnv(name string, dotenv map[string]string) (string, string, error) {
+	lookups := []struct {
+		get    func(key string) string
+		source string
+	}{
+		{get: os.Getenv, source: SourceEnv},
+		{get: func(key string) string { return dotenv[key] }, source: SourceDotEnv},
+	}
+
+	for _, lookup := range lookups {
+		if value := lookup.get(name); value != "" {
+			return value, lookup.source, nil
+		}
+
+		filePath := lookup.get(name + "_FILE")
+		if filePath == "" {
+			continue
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", SourceFile, fmt.Errorf("could not read %s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), SourceFile, nil
+	}
+
+	return "", SourceUnset, nil
+}
+
+func setFieldValue(fieldValue reflect.Value, value string, separator string) error {
+	switch fieldValue.Type() {
+	case durationType:
+		if value == "" {
+			fieldValue.SetInt(0)
+			return nil
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		fieldValue.SetInt(int64(duration))
+		return nil
+	case urlType:
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid url %q", value)
+		}
+		fieldValue.Set(reflect.ValueOf(*parsed))
+		return nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.String:
+		fieldValue.SetString(value)
+	case reflect.Bool:
+		if value == "" {
+			fieldValue.SetBool(false)
+			return nil
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		fieldValue.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value == "" {
+			fieldValue.SetInt(0)
+			return nil
+		}
+		parsed, err := strconv.ParseInt(value, 10, fieldValue.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		fieldValue.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value == "" {
+			fieldValue.SetUint(0)
+			return nil
+		}
+		parsed, err := strconv.ParseUint(value, 10, fieldValue.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", value)
+		}
+		fieldValue.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		if value == "" {
+			fieldValue.SetFloat(0)
+			return nil
+		}
+		parsed, err := strconv.ParseFloat(value, fieldValue.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		fieldValue.SetFloat(parsed)
+	case reflect.Slice:
+		items := reflect.MakeSlice(fieldValue.Type(), 0, 0)
+		if value != "" {
+			for _, item := range strings.Split(value, separator) {
+				itemValue := reflect.New(fieldValue.Type().Elem()).Elem()
+				if err := setFieldValue(itemValue, strings.TrimSpace(item), separator); err != nil {
+					return err
+				}
+				items = reflect.Append(items, itemValue)
+			}
+		}
+		fieldValue.Set(items)
+	default:
+		return fmt.Errorf("unsupported type %s", fieldValue.Type())
+	}
+
+	return nil
+}
+
+// validateField applies the rules of the validate tag to an already loaded field.
+func validateField(fieldValue reflect.Value, rules string) error {
+	if rules == "" {
+		return nil
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, argument, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		var err error
+		switch name {
+		case "min", "max":
+			err = validateBoundary(fieldValue, name, argument)
+		case "oneof":
+			err = validateOneOf(fieldValue, strings.Fields(argument))
+		case "url":
+			err = validateURL(fieldValue)
+		case "dsn":
+			err = validateDSN(fieldValue)
+		default:
+			return fmt.Errorf("unknown validation rule %q", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateBoundary(fieldValue reflect.Value, rule string, argument string) error {
+	var value, limit float64
+	switch {
+	case fieldValue.Type() == durationType:
+		duration, err := time.ParseDuration(argument)
+		if err != nil {
+			return fmt.Errorf("invalid %s rule %q", rule, argument)
+		}
+		value, limit = float64(fieldValue.Int()), float64(duration)
+	default:
+		parsed, err := strconv.ParseFloat(argument, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s rule %q", rule, argument)
+		}
+		limit = parsed
+
+		switch fieldValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value = float64(fieldValue.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value = float64(fieldValue.Uint())
+		case reflect.Float32, reflect.Float64:
+			value = fieldValue.Float()
+		case reflect.String, reflect.Slice:
+			value = float64(fieldValue.Len())
+		default:
+			return fmt.Errorf("%s rule is not supported for %s", rule, fieldValue.Type())
+		}
+	}
+
+	if rule == "min" && value < limit {
+		return fmt.Errorf("must be at least %s", argument)
+	}
+	if rule == "max" && value > limit {
+		return fmt.Errorf("must be at most %s", argument)
+	}
+	return nil
+}
+
+func validateOneOf(fieldValue reflect.Value, options []string) error {
+	values := []string{fmt.Sprint(fieldValue.Interface())}
+	if fieldValue.Kind() == reflect.Slice {
+		values = values[:0]
+		for i := range fieldValue.Len() {
+			values = append(values, fmt.Sprint(fieldValue.Index(i).Interface()))
+		}
+	}
+
+	for _, value := range values {
+		if !slices.Contains(options, value) {
+			return fmt.Errorf("%q must be one of: %s", value, strings.Join(options, ", "))
+		}
+	}
+	return nil
+}
+
+// validateDSN accepts the PostgreSQL connection strings understood by pgxpool, both URLs and keyword/value
+// strings such as "host=db user=app". The parse error is not returned because it may echo the password.
+func validateDSN(fieldValue reflect.Value) error {
+	if _, err := pgxpool.ParseConfig(fieldValue.String()); err != nil {
+		return fmt.Errorf("must be a valid PostgreSQL connection string")
+	}
+	return nil
+}
+
+func validateURL(fieldValue reflect.Value) error {
+	raw := fieldValue.String()
+	if fieldValue.Type() == urlType {
+		parsed := fieldValue.Interface().(url.URL)
+		raw = parsed.String()
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("must be an absolute url")
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testServerVariables struct {
+	Host    string        `name:"HOST" default:"localhost"`
+	Timeout time.Duration `name:"TIMEOUT" default:"5s" validate:"min=1s,max=1m"`
+}
+
+type testVariables struct {
+	Name     string              `name:"NAME" required:"true"`
+	Workers  int                 `name:"WORKERS" default:"4" validate:"min=1,max=16"`
+	Debug    bool                `name:"DEBUG" default:"false"`
+	Ratio    float64             `name:"RATIO" default:"0.5"`
+	Origins  []string            `name:"ORIGINS" default:"a.com, b.com"`
+	Ports    []int               `name:"PORTS" separator:";"`
+	Level    string              `name:"LEVEL" default:"info" validate:"oneof=debug info warn error"`
+	Endpoint string              `name:"ENDPOINT" validate:"url"`
+	Database string              `name:"DATABASE" default:"postgres://localhost/app" validate:"dsn"`
+	Server   testServerVariables `prefix:"SERVER_"`
+	ignored  string
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Run("loads typed values and defaults", func(t *testing.T) {
+		t.Setenv("NAME", "lucienne")
+		t.Setenv("WORKERS", "8")
+		t.Setenv("DEBUG", "true")
+		t.Setenv("PORTS", "80;443")
+
+		var variables testVariables
+		if _, err := loadEnv(&variables, nil); err != nil {
+			t.Fatalf("Expected: no error, Got: %s", err)
+		}
+
+		if variables.Name != "lucienne" || variables.Workers != 8 || !variables.Debug || variables.Ratio != 0.5 {
+			t.Errorf("Unexpected scalar values: %#v", variables)
+		}
+		if !slices.Equal(variables.Origins, []string{"a.com", "b.com"}) {
+			t.Errorf("Expected: [a.com b.com], Got: %#v", variables.Origins)
+		}
+		if !slices.Equal(variables.Ports, []int{80, 443}) {
+			t.Errorf("Expected: [80 443], Got: %#v", variables.Ports)
+		}
+	})
+
+	t.Run("loads nested structs using the prefix", func(t *testing.T) {
+		t.Setenv("NAME", "lucienne")
+		t.Setenv("SERVER_TIMEOUT", "30s")
+
+		var variables testVariables
+		if _, err := loadEnv(&variables, nil); err != nil {
+			t.Fatalf("Expected: no error, Got: %s", err)
+		}
+
+		if variables.Server.Host != "localhost" || variables.Server.Timeout != 30*time.Second {
+			t.Errorf("Unexpected nested values: %#v", variables.Server)
+		}
+	})
+
+	t.Run("accepts keyword/value database connection strings", func(t *testing.T) {
+		t.Setenv("NAME", "lucienne")
+		t.Setenv("DATABASE", "host=db user=app password=secret dbname=app sslmode=disable")
+
+		var variables testVariables
+		if _, err := loadEnv(&variables, nil); err != nil {
+			t.Fatalf("Expected: no error, Got: %s", err)
+		}
+	})
+
+	t.Run("reads the value from the _FILE variant", func(t *testing.T) {
+		secretPath := path.Join(t.TempDir(), "name")
+		os.WriteFile(secretPath, []byte("from-secret\n"), 0600)
+		t.Setenv("NAME", "")
+		t.Setenv("NAME_FILE", secretPath)
+
+		var variables testVariables
+		if _, err := loadEnv(&variables, nil); err != nil {
+			t.Fatalf("Expected: no error, Got: %s", err)
+		}
+		if variables.Name != "from-secret" {
+			t.Errorf("Expected: from-secret, Got: %s", variables.Name)
+		}
+	})
+
+	t.Run("returns every invalid variable", func(t *testing.T) {
+		t.Setenv("NAME", "")
+		t.Setenv("WORKERS", "32")
+		t.Setenv("DEBUG", "maybe")
+		t.Setenv("LEVEL", "verbose")
+		t.Setenv("ENDPOINT", "not a url")
+		t.Setenv("DATABASE", "postgres://app:secret@db:notaport/app")
+		t.Setenv("SERVER_TIMEOUT", "0s")
+
+		var variables testVariables
+		_, err := loadEnv(&variables, nil)
+
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("Expected: *LoadError, Got: %v", err)
+		}
+		for _, expected := range []string{
+			"NAME: is required",
+			"WORKERS: must be at most 16",
+			`DEBUG: invalid boolean "maybe"`,
+			`LEVEL: "verbose" must be one of: debug, info, warn, error`,
+			"ENDPOINT: must be an absolute url",
+			"DATABASE: must be a valid PostgreSQL connection string",
+			"SERVER_TIMEOUT: must be at least 1s",
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expected error to contain %q, Got: %s", expected, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Sources an environment variable value can come from.
+const (
+	SourceEnv     = "env"
+	SourceDotEnv  = ".env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+	SourceUnset   = "unset"
+)
+
+const redactedValue = "********"
+
+// EnvVariable describes a loaded environment variable and where its value came from.
+type EnvVariable struct {
+	Name     string
+	Value    string
+	Default  string
+	Source   string
+	Required bool
+	Secret   bool
+	Problem  string
+}
+
+// RedactedValue returns the value safe to be displayed: secrets are hidden and URL passwords are masked.
+func (variable EnvVariable) RedactedValue() string {
+	return redact(variable.Value, variable.Secret)
+}
+
+func redact(value string, secret bool) string {
+	if value == "" {
+		return ""
+	}
+	if secret {
+		return redactedValue
+	}
+	if parsed, err := url.Parse(value); err == nil && parsed.User != nil {
+		if _, hasPassword := parsed.User.Password(); hasPassword {
+			return parsed.Redacted()
+		}
+	}
+	return value
+}
+
+// Describe returns every variable loaded by the last call to Load.
+func (envs *envVariables) Describe() []EnvVariable {
+	return append([]EnvVariable(nil), envs.loaded...)
+}
+
+// Example builds the content of a .env.example file from the struct tags.
+func (envs *envVariables) Example() (string, error) {
+	fields, err := collectEnvFields(reflect.ValueOf(envs).Elem(), "")
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("# Generated by `lucienne config example`. Do not edit by hand.\n")
+	builder.WriteString("# Every variable can also be read from a file pointed by <NAME>_FILE.\n")
+	for _, field := range fields {
+		var notes []string
+		if field.Tags.IsRequired {
+			notes = append(notes, "required")
+		}
+		if field.Tags.DefaultValue != "" {
+			notes = append(notes, "default: "+redact(field.Tags.DefaultValue, field.Tags.IsSecret))
+		}
+		if field.Tags.Validate != "" {
+			notes = append(notes, "validate: "+field.Tags.Validate)
+		}
+		if field.Tags.IsSecret {
+			notes = append(notes, "secret")
+		}
+
+		builder.WriteString("\n")
+		if len(notes) > 0 {
+			fmt.Fprintf(&builder, "# %s\n", strings.Join(notes, "; "))
+		}
+		fmt.Fprintf(&builder, "%s=\n", field.Tags.EnvName)
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,10 @@
+-- O id é o hash SHA-256 do token enviado no cookie, para que um vazamento da tabela não permita usar as sessões.
+CREATE TABLE sessions (
+    id CHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
@@ -0,0 +1,55 @@
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE user_roles (
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role_id INTEGER NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role_id)
+);
+
+-- Os papéis e permissões fazem parte do modelo da aplicação, por isso são criados aqui e não nos seeds.
+INSERT INTO roles (name, description) VALUES
+('member', 'Membro: navega pelo catálogo'),
+('librarian', 'Bibliotecário: gerencia autores, editoras e livros'),
+('admin', 'Administrador: gerencia usuários e configurações');
+
+INSERT INTO permissions (name, description) VALUES
+('authors.manage', 'Criar, editar e remover autores'),
+('publishers.manage', 'Criar, editar e remover editoras'),
+('books.manage', 'Criar, editar e remover livros'),
+('users.manage', 'Gerenciar usuários e seus papéis'),
+('settings.manage', 'Alterar as configurações da aplicação');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM (VALUES
+    ('librarian', 'authors.manage'),
+    ('librarian', 'publishers.manage'),
+    ('librarian', 'books.manage'),
+    ('admin', 'authors.manage'),
+    ('admin', 'publishers.manage'),
+    ('admin', 'books.manage'),
+    ('admin', 'users.manage'),
+    ('admin', 'settings.manage')
+) AS grants (role_name, permission_name)
+JOIN roles ON roles.name = grants.role_name
+JOIN permissions ON permissions.name = grants.permission_name;
+
+-- Os usuários criados antes dos papéis passam a ser membros.
+INSERT INTO user_roles (user_id, role_id)
+SELECT users.id, roles.id FROM users, roles WHERE roles.name = 'member';
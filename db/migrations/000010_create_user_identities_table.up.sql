@@ -0,0 +1,14 @@
+-- Usuários criados pelo login único (OIDC) não têm senha.
+ALTER TABLE users ALTER COLUMN password_hash DROP NOT NULL;
+
+-- Liga o usuário à conta no provedor de identidade, identificada pelo emissor e pelo subject do ID token.
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (issuer, subject)
+);
+
+CREATE INDEX user_identities_user_id_idx ON user_identities (user_id);
@@ -0,0 +1,17 @@
+-- Verificação em duas etapas (TOTP). O segredo é gravado no início da ativação, e totp_enabled_at só é
+-- preenchido depois que o usuário confirma um código do aplicativo autenticador.
+-- totp_last_step é o último intervalo de 30 segundos aceito, para que o mesmo código não seja usado duas vezes.
+ALTER TABLE users
+    ADD COLUMN totp_secret VARCHAR(64),
+    ADD COLUMN totp_enabled_at TIMESTAMPTZ,
+    ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- Códigos de recuperação, de uso único, para quem perdeu o aplicativo. Apenas o hash SHA-256 é guardado.
+CREATE TABLE user_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    used_at TIMESTAMPTZ,
+    UNIQUE (user_id, code_hash)
+);
@@ -0,0 +1,17 @@
+-- Falhas de login recentes por conta (o email digitado, em minúsculas) e por IP. Emails não cadastrados também
+-- são contados, para que a resposta não revele quais contas existem.
+-- locked_until é até quando novas tentativas são recusadas: um atraso que cresce a cada falha e, depois de muitas
+-- falhas, o bloqueio temporário, marcado em locked_at.
+CREATE TABLE login_throttles (
+    id SERIAL PRIMARY KEY,
+    scope VARCHAR(16) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_ip VARCHAR(64) NOT NULL,
+    last_failure_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    locked_at TIMESTAMPTZ,
+    UNIQUE (scope, subject)
+);
+
+CREATE INDEX login_throttles_locked_idx ON login_throttles (locked_until) WHERE locked_at IS NOT NULL;
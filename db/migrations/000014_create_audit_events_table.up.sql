@@ -0,0 +1,38 @@
+-- Histórico das alterações do catálogo, gravado pelos repositórios na mesma transação que a alteração.
+-- A tabela só aceita inserções. actor_id não referencia users para que o histórico sobreviva à remoção do
+-- usuário; actor_email guarda quem era ele.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor_id INTEGER,
+    actor_email VARCHAR(255),
+    action VARCHAR(16) NOT NULL,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id BIGINT NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id VARCHAR(128)
+);
+
+CREATE INDEX audit_events_entity_idx ON audit_events (entity_type, entity_id, id DESC);
+
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events aceita apenas inserções';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+INSERT INTO permissions (name, description) VALUES
+('audit.view', 'Consultar o histórico de alterações do catálogo');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles, permissions
+WHERE roles.name = 'admin' AND permissions.name = 'audit.view';
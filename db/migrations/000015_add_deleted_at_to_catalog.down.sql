@@ -0,0 +1,13 @@
+-- O que está na lixeira é apagado de vez, já que sem deleted_at voltaria a aparecer como ativo.
+DELETE FROM books WHERE deleted_at IS NOT NULL;
+DELETE FROM authors WHERE deleted_at IS NOT NULL;
+DELETE FROM publishers WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS authors_name_key;
+DROP INDEX IF EXISTS publishers_name_key;
+ALTER TABLE authors ADD CONSTRAINT authors_name_key UNIQUE (name);
+ALTER TABLE publishers ADD CONSTRAINT publishers_name_key UNIQUE (name);
+
+ALTER TABLE books DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE publishers DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE authors DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,16 @@
+-- Autores, editoras e livros removidos vão para a lixeira: deleted_at marca a remoção, e a linha só é apagada
+-- de vez pela lixeira ou depois do prazo de retenção.
+ALTER TABLE authors ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE publishers ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE books ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Os nomes continuam únicos apenas entre os registros ativos, para que um nome na lixeira possa ser cadastrado
+-- de novo.
+ALTER TABLE authors DROP CONSTRAINT authors_name_key;
+CREATE UNIQUE INDEX authors_name_key ON authors (name) WHERE deleted_at IS NULL;
+ALTER TABLE publishers DROP CONSTRAINT publishers_name_key;
+CREATE UNIQUE INDEX publishers_name_key ON publishers (name) WHERE deleted_at IS NULL;
+
+CREATE INDEX authors_deleted_at_idx ON authors (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX publishers_deleted_at_idx ON publishers (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX books_deleted_at_idx ON books (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS books_record_version ON books;
+DROP TRIGGER IF EXISTS authors_record_version ON authors;
+DROP FUNCTION IF EXISTS record_book_version();
+DROP FUNCTION IF EXISTS record_author_version();
+DROP TABLE IF EXISTS book_versions;
+DROP TABLE IF EXISTS author_versions;
@@ -0,0 +1,65 @@
+-- Versões de autores e livros. Cada linha guarda o estado do registro de valid_from até valid_to; a versão
+-- atual tem valid_to nulo. Os triggers gravam uma versão a cada inserção e a cada alteração, inclusive a ida e
+-- a volta da lixeira, de modo que qualquer escrita, pelos repositórios ou não, fica no histórico. As versões
+-- são apagadas com o registro.
+CREATE TABLE author_versions (
+    author_id INTEGER NOT NULL REFERENCES authors (id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to TIMESTAMPTZ,
+    PRIMARY KEY (author_id, version)
+);
+
+CREATE TABLE book_versions (
+    book_id INTEGER NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    author_id INTEGER NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to TIMESTAMPTZ,
+    PRIMARY KEY (book_id, version)
+);
+
+CREATE FUNCTION record_author_version() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW IS NOT DISTINCT FROM OLD THEN
+        RETURN NEW;
+    END IF;
+    UPDATE author_versions SET valid_to = now() WHERE author_id = NEW.id AND valid_to IS NULL;
+    INSERT INTO author_versions (author_id, version, name, deleted_at, valid_from)
+    SELECT NEW.id, COALESCE(MAX(version), 0) + 1, NEW.name, NEW.deleted_at, now()
+    FROM author_versions WHERE author_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION record_book_version() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW IS NOT DISTINCT FROM OLD THEN
+        RETURN NEW;
+    END IF;
+    UPDATE book_versions SET valid_to = now() WHERE book_id = NEW.id AND valid_to IS NULL;
+    INSERT INTO book_versions (book_id, version, name, author_id, deleted_at, valid_from)
+    SELECT NEW.id, COALESCE(MAX(version), 0) + 1, NEW.name, NEW.author_id, NEW.deleted_at, now()
+    FROM book_versions WHERE book_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER authors_record_version
+    AFTER INSERT OR UPDATE ON authors
+    FOR EACH ROW EXECUTE FUNCTION record_author_version();
+
+CREATE TRIGGER books_record_version
+    AFTER INSERT OR UPDATE ON books
+    FOR EACH ROW EXECUTE FUNCTION record_book_version();
+
+-- Os registros existentes começam o histórico na data da migração.
+INSERT INTO author_versions (author_id, version, name, deleted_at, valid_from)
+SELECT id, 1, name, deleted_at, now() FROM authors;
+
+INSERT INTO book_versions (book_id, version, name, author_id, deleted_at, valid_from)
+SELECT id, 1, name, author_id, deleted_at, now() FROM books;
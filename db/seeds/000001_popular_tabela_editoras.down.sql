@@ -1,2 +0,0 @@
-DELETE FROM publishers
-WHERE name IN ('Passa Palavra', 'Companhia das Letras', 'Faisca');
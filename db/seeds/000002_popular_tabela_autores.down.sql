@@ -1,2 +0,0 @@
-DELETE FROM authors
-WHERE name IN ('Neil Gaiman', 'J.R.R. Tolkien', 'George R.R. Martin');
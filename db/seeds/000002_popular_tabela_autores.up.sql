@@ -1,5 +0,0 @@
-INSERT INTO authors (name)
-VALUES
-('Neil Gaiman'),
-('J.R.R. Tolkien'),
-('George R.R. Martin');
@@ -1 +0,0 @@
-DELETE FROM books WHERE name IN ('O Hobbit', 'A Revolução dos Bichos');
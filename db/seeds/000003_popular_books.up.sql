@@ -1,2 +0,0 @@
-INSERT INTO books (name, author_id) VALUES ('O Hobbit', 2);
-INSERT INTO books (name, author_id) VALUES ('A Revolução dos Bichos', 3);
@@ -0,0 +1,9 @@
+INSERT INTO publishers (name)
+VALUES
+('Passa Palavra'),
+('Companhia das Letras'),
+('Faisca'),
+('Editora Rocco'),
+('Intrínseca'),
+('Suma')
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING;
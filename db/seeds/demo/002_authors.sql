@@ -0,0 +1,11 @@
+INSERT INTO authors (name)
+VALUES
+('Neil Gaiman'),
+('J.R.R. Tolkien'),
+('George R.R. Martin'),
+('George Orwell'),
+('Ursula K. Le Guin'),
+('Machado de Assis'),
+('Clarice Lispector'),
+('Octavia E. Butler')
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING;
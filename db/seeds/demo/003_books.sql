@@ -0,0 +1,21 @@
+-- Os livros referenciam os autores pelo nome, que é a chave natural da tabela.
+INSERT INTO books (name, author_id)
+SELECT seed.name, authors.id
+FROM (VALUES
+    ('Sandman: Prelúdios e Noturnos', 'Neil Gaiman'),
+    ('Deuses Americanos', 'Neil Gaiman'),
+    ('O Hobbit', 'J.R.R. Tolkien'),
+    ('O Senhor dos Anéis: A Sociedade do Anel', 'J.R.R. Tolkien'),
+    ('A Guerra dos Tronos', 'George R.R. Martin'),
+    ('A Revolução dos Bichos', 'George Orwell'),
+    ('1984', 'George Orwell'),
+    ('A Mão Esquerda da Escuridão', 'Ursula K. Le Guin'),
+    ('Dom Casmurro', 'Machado de Assis'),
+    ('Memórias Póstumas de Brás Cubas', 'Machado de Assis'),
+    ('A Hora da Estrela', 'Clarice Lispector'),
+    ('Kindred: Laços de Sangue', 'Octavia E. Butler')
+) AS seed (name, author_name)
+JOIN authors ON authors.name = seed.author_name AND authors.deleted_at IS NULL
+WHERE NOT EXISTS (
+    SELECT 1 FROM books WHERE books.name = seed.name AND books.author_id = authors.id
+);
@@ -2,4 +2,5 @@ INSERT INTO publishers (name)
 VALUES
 ('Passa Palavra'),
 ('Companhia das Letras'),
-('Faisca');
+('Faisca')
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING;
@@ -0,0 +1,7 @@
+INSERT INTO authors (name)
+VALUES
+('Neil Gaiman'),
+('J.R.R. Tolkien'),
+('George R.R. Martin'),
+('George Orwell')
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING;
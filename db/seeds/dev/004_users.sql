@@ -0,0 +1,12 @@
+-- Usuário para entrar na interface em desenvolvimento: dev@lucienne.local / lucienne-dev-password.
+INSERT INTO users (email, name, password_hash)
+VALUES
+('dev@lucienne.local', 'Dev', '$argon2id$v=19$m=65536,t=3,p=2$j19SQF4o2OnwjxEB0dyZMw$y3AE5NOK9rfPK0U/76mXqVAOtrYsMsSkTMXsKKspDbI')
+ON CONFLICT DO NOTHING;
+
+-- O usuário de desenvolvimento é administrador, para ter acesso a todas as páginas.
+INSERT INTO user_roles (user_id, role_id)
+SELECT users.id, roles.id
+FROM users, roles
+WHERE users.email = 'dev@lucienne.local' AND roles.name IN ('member', 'admin')
+ON CONFLICT DO NOTHING;
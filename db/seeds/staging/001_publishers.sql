@@ -0,0 +1,5 @@
+INSERT INTO publishers (name)
+VALUES
+('Companhia das Letras'),
+('Editora Rocco')
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING;
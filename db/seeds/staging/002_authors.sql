@@ -0,0 +1,5 @@
+INSERT INTO authors (name)
+VALUES
+('Neil Gaiman'),
+('Machado de Assis')
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING;
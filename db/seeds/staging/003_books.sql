@@ -0,0 +1,11 @@
+-- Os livros referenciam os autores pelo nome, que é a chave natural da tabela.
+INSERT INTO books (name, author_id)
+SELECT seed.name, authors.id
+FROM (VALUES
+    ('Coraline', 'Neil Gaiman'),
+    ('Dom Casmurro', 'Machado de Assis')
+) AS seed (name, author_name)
+JOIN authors ON authors.name = seed.author_name AND authors.deleted_at IS NULL
+WHERE NOT EXISTS (
+    SELECT 1 FROM books WHERE books.name = seed.name AND books.author_id = authors.id
+);
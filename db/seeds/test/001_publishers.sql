@@ -0,0 +1,4 @@
+INSERT INTO publishers (name)
+VALUES
+('Editora de Teste')
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING;
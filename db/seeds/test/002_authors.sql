@@ -0,0 +1,5 @@
+INSERT INTO authors (name)
+VALUES
+('Autor de Teste'),
+('Autor Sem Livros')
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING;
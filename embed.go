@@ -0,0 +1,19 @@
+//go:build embed
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// embeddedFiles contém tudo o que a aplicação lê em tempo de execução, permitindo distribuir apenas o binário.
+// Os assets precisam ser compilados (node esbuild.js) antes do build com a tag embed.
+//
+//go:embed db/migrations db/seeds internal/views public
+var embeddedFiles embed.FS
+
+// embeddedFS retorna os arquivos embutidos no binário.
+func embeddedFS() (fs.FS, bool) {
+	return embeddedFiles, true
+}
@@ -0,0 +1,10 @@
+//go:build !embed
+
+package main
+
+import "io/fs"
+
+// embeddedFS indica que o binário não possui arquivos embutidos; eles são lidos do diretório raiz da aplicação.
+func embeddedFS() (fs.FS, bool) {
+	return nil, false
+}
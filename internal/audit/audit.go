@@ -0,0 +1,70 @@
+// Package audit identifica quem faz cada alteração e calcula o que mudou, para o histórico gravado pelos
+// repositórios em audit_events.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// Actor é o usuário que faz as alterações da requisição.
+type Actor struct {
+	UserID int64
+	Email  string
+}
+
+type actorKey struct{}
+
+// WithActor retorna uma cópia de ctx com o autor das alterações. É chamado por auth.WithUser, de modo que os
+// repositórios conheçam o usuário autenticado sem depender do pacote auth.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom retorna o autor das alterações, ou false para as alterações feitas fora de uma requisição
+// autenticada, como os seeds.
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// Diff compara os campos do registro antes e depois da alteração e retorna, em JSON, apenas os que mudaram.
+// Na criação before é nil e, na remoção, after é nil; os dois lados trazem então todos os campos do outro.
+// changed é falso quando nenhum campo mudou.
+func Diff(before map[string]any, after map[string]any) (beforeJSON []byte, afterJSON []byte, changed bool, err error) {
+	changedBefore := map[string]any{}
+	changedAfter := map[string]any{}
+	for field, value := range before {
+		if other, ok := after[field]; !ok || !equal(value, other) {
+			changedBefore[field] = value
+		}
+	}
+	for field, value := range after {
+		if other, ok := before[field]; !ok || !equal(value, other) {
+			changedAfter[field] = value
+		}
+	}
+	if len(changedBefore) == 0 && len(changedAfter) == 0 {
+		return nil, nil, false, nil
+	}
+
+	if before != nil {
+		if beforeJSON, err = json.Marshal(changedBefore); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	if after != nil {
+		if afterJSON, err = json.Marshal(changedAfter); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	return beforeJSON, afterJSON, true, nil
+}
+
+// equal compara os valores pela representação em JSON, que é a gravada no histórico.
+func equal(a any, b any) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
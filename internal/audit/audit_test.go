@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestActor(t *testing.T) {
+	if _, ok := ActorFrom(context.Background()); ok {
+		t.Error("Não esperava um autor em um contexto sem usuário")
+	}
+
+	ctx := WithActor(context.Background(), Actor{UserID: 3, Email: "ana@example.com"})
+	actor, ok := ActorFrom(ctx)
+	if !ok || actor.UserID != 3 || actor.Email != "ana@example.com" {
+		t.Errorf("Esperava o autor informado, mas obteve %+v", actor)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	testCases := []struct {
+		name           string
+		before         map[string]any
+		after          map[string]any
+		expectedBefore string
+		expectedAfter  string
+		changed        bool
+	}{
+		{
+			name:          "deve gravar todos os campos na criação",
+			after:         map[string]any{"name": "Neil Gaiman"},
+			expectedAfter: `{"name":"Neil Gaiman"}`,
+			changed:       true,
+		},
+		{
+			name:           "deve gravar todos os campos na remoção",
+			before:         map[string]any{"name": "Neil Gaiman"},
+			expectedBefore: `{"name":"Neil Gaiman"}`,
+			changed:        true,
+		},
+		{
+			name:           "deve gravar apenas os campos alterados",
+			before:         map[string]any{"name": "Neil Gaiman", "year": 1960},
+			after:          map[string]any{"name": "Terry Pratchett", "year": 1960},
+			expectedBefore: `{"name":"Neil Gaiman"}`,
+			expectedAfter:  `{"name":"Terry Pratchett"}`,
+			changed:        true,
+		},
+		{
+			name:   "deve ignorar uma atualização sem mudanças",
+			before: map[string]any{"name": "Neil Gaiman"},
+			after:  map[string]any{"name": "Neil Gaiman"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before, after, changed, err := Diff(tc.before, tc.after)
+			if err != nil {
+				t.Fatalf("Diff retornou um erro inesperado: %v", err)
+			}
+			if changed != tc.changed {
+				t.Errorf("Esperava changed %v, mas obteve %v", tc.changed, changed)
+			}
+			if string(before) != tc.expectedBefore || string(after) != tc.expectedAfter {
+				t.Errorf("Esperava %s e %s, mas obteve %s e %s", tc.expectedBefore, tc.expectedAfter, before, after)
+			}
+		})
+	}
+}
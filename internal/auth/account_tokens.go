@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/mailer"
+	"lucienne/pkg/password"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidUserToken é retornado quando o link enviado por email não existe, já foi usado ou expirou.
+	ErrInvalidUserToken = errors.New("link inválido ou expirado")
+
+	// ErrPasswordResetQueueFull é retornado quando há pedidos de redefinição demais aguardando o envio.
+	ErrPasswordResetQueueFull = errors.New("fila de redefinição de senha cheia")
+)
+
+// passwordResetQueueSize é quantos pedidos de redefinição podem aguardar o envio ao mesmo tempo.
+const passwordResetQueueSize = 100
+
+// AccountTokenOptions configura os links enviados por email.
+type AccountTokenOptions struct {
+	// BaseURL é o endereço público da aplicação, usado para montar os links.
+	BaseURL              string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+}
+
+// AccountTokens envia e consome os links de uso único de redefinição de senha e de confirmação de email.
+type AccountTokens struct {
+	users   repository.UserRepository
+	tokens  repository.UserTokenRepository
+	mailer  mailer.Mailer
+	options AccountTokenOptions
+	now     func() time.Time
+	// resets são os emails que pediram a redefinição de senha, enviados por Run.
+	resets chan string
+}
+
+// NewAccountTokens cria um AccountTokens com suas dependências. Os links de redefinição de senha só são
+// enviados enquanto Run estiver rodando.
+func NewAccountTokens(users repository.UserRepository, tokens repository.UserTokenRepository, mail mailer.Mailer, options AccountTokenOptions) *AccountTokens {
+	options.BaseURL = strings.TrimSuffix(options.BaseURL, "/")
+	return &AccountTokens{
+		users: users, tokens: tokens, mailer: mail, options: options, now: time.Now,
+		resets: make(chan string, passwordResetQueueSize),
+	}
+}
+
+// RequestPasswordReset agenda o envio do link de redefinição de senha para o email, se ele estiver cadastrado.
+// O email é buscado e o link é enviado depois, por Run, para que nem a resposta nem o tempo dela revelem quais
+// emails estão cadastrados.
+func (a *AccountTokens) RequestPasswordReset(ctx context.Context, email string) error {
+	select {
+	case a.resets <- email:
+		return nil
+	default:
+		return ErrPasswordResetQueueFull
+	}
+}
+
+// Run envia os links de redefinição de senha pedidos até ctx ser cancelado. Um erro no envio não interrompe
+// os seguintes e é apenas registrado no log.
+func (a *AccountTokens) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case email := <-a.resets:
+			if err := a.sendPasswordReset(ctx, email); err != nil {
+				slog.ErrorContext(ctx, "erro ao enviar o link de redefinição de senha", "error", err)
+			}
+		}
+	}
+}
+
+// sendPasswordReset envia o link de redefinição de senha para o email, se ele estiver cadastrado.
+// Um email desconhecido não é um erro.
+func (a *AccountTokens) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := a.users.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		slog.InfoContext(ctx, "redefinição de senha pedida para um email não cadastrado")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.HasPassword() {
+		// Os usuários do login único não têm senha para redefinir.
+		slog.InfoContext(ctx, "redefinição de senha pedida para um usuário do login único", "user_id", user.ID)
+		return nil
+	}
+
+	link, err := a.issue(ctx, user, domain.TokenPurposePasswordReset, a.options.PasswordResetTTL, "/password/reset")
+	if err != nil {
+		return err
+	}
+	return a.send(ctx, user, "Redefinição de senha do lucienne", fmt.Sprintf(
+		"Olá, %s.\n\nRecebemos um pedido para redefinir a senha da sua conta. Para escolher uma nova senha, abra o link abaixo em até %s:\n\n%s\n\nSe você não fez o pedido, ignore este email: sua senha continua a mesma.\n",
+		user.Name, formatTTL(a.options.PasswordResetTTL), link,
+	))
+}
+
+// CheckPasswordReset verifica, sem usá-lo, se o link de redefinição ainda é válido.
+func (a *AccountTokens) CheckPasswordReset(ctx context.Context, plain string) error {
+	_, err := a.tokens.GetUserToken(ctx, domain.TokenPurposePasswordReset, hashToken(plain))
+	if errors.Is(err, repository.ErrUserTokenNotFound) {
+		return ErrInvalidUserToken
+	}
+	return err
+}
+
+// ResetPassword usa o link de redefinição para trocar a senha e encerra todas as sessões do usuário,
+// inclusive a de quem tenha descoberto a senha antiga. O link só é usado se a senha também for trocada.
+func (a *AccountTokens) ResetPassword(ctx context.Context, plain string, newPassword string) error {
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+	hash, err := password.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	token, err := a.tokens.ResetPassword(ctx, hashToken(plain), hash)
+	if errors.Is(err, repository.ErrUserTokenNotFound) {
+		return ErrInvalidUserToken
+	}
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "senha redefinida pelo link enviado por email", "user_id", token.UserID)
+	return nil
+}
+
+// SendEmailVerification envia o link de confirmação para o email atual do usuário.
+func (a *AccountTokens) SendEmailVerification(ctx context.Context, user *domain.User) error {
+	link, err := a.issue(ctx, user, domain.TokenPurposeEmailVerification, a.options.EmailVerificationTTL, "/email/verify")
+	if err != nil {
+		return err
+	}
+	return a.send(ctx, user, "Confirme seu email no lucienne", fmt.Sprintf(
+		"Olá, %s.\n\nPara confirmar o email da sua conta no lucienne, abra o link abaixo em até %s:\n\n%s\n\nSe você não criou uma conta no lucienne, ignore este email.\n",
+		user.Name, formatTTL(a.options.EmailVerificationTTL), link,
+	))
+}
+
+// VerifyEmail usa o link de confirmação. O link deixa de valer se o email do usuário mudou depois do envio.
+func (a *AccountTokens) VerifyEmail(ctx context.Context, plain string) error {
+	token, err := a.tokens.ConsumeUserToken(ctx, domain.TokenPurposeEmailVerification, hashToken(plain))
+	if errors.Is(err, repository.ErrUserTokenNotFound) {
+		return ErrInvalidUserToken
+	}
+	if err != nil {
+		return err
+	}
+
+	err = a.users.MarkEmailVerified(ctx, token.UserID, token.Email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrInvalidUserToken
+	}
+	return err
+}
+
+// issue guarda o hash de um novo token e retorna o link com o token, que só existe no email enviado.
+func (a *AccountTokens) issue(ctx context.Context, user *domain.User, purpose string, ttl time.Duration, path string) (string, error) {
+	plain := randomString()
+	token := &domain.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(plain),
+		Email:     user.Email,
+		ExpiresAt: a.now().Add(ttl),
+	}
+	if err := a.tokens.CreateUserToken(ctx, token); err != nil {
+		return "", err
+	}
+	return a.options.BaseURL + path + "?" + url.Values{"token": {plain}}.Encode(), nil
+}
+
+func (a *AccountTokens) send(ctx context.Context, user *domain.User, subject string, text string) error {
+	to := (&mail.Address{Name: user.Name, Address: user.Email}).String()
+	return a.mailer.Send(ctx, mailer.Message{To: to, Subject: subject, Text: text})
+}
+
+// formatTTL escreve o prazo do link em horas ou, abaixo de uma hora, em minutos.
+func formatTTL(ttl time.Duration) string {
+	switch hours := int(ttl.Hours()); {
+	case hours == 1:
+		return "1 hora"
+	case hours > 1:
+		return fmt.Sprintf("%d horas", hours)
+	}
+	return fmt.Sprintf("%d minutos", int(ttl.Minutes()))
+}
+
+// hashToken é o hash guardado no banco para os tokens entregues ao usuário.
+func hashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,278 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/mailer"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryUserTokenRepository guarda os tokens enviados por email em memória.
+type memoryUserTokenRepository struct {
+	tokens   map[string]*domain.UserToken
+	now      func() time.Time
+	users    *memoryUserRepository
+	sessions *memorySessionRepository
+}
+
+func (m *memoryUserTokenRepository) CreateUserToken(ctx context.Context, token *domain.UserToken) error {
+	for hash, existing := range m.tokens {
+		if existing.UserID == token.UserID && existing.Purpose == token.Purpose && existing.UsedAt == nil {
+			delete(m.tokens, hash)
+		}
+	}
+	copied := *token
+	m.tokens[token.TokenHash] = &copied
+	return nil
+}
+
+func (m *memoryUserTokenRepository) GetUserToken(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+	token, ok := m.tokens[hash]
+	if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(m.now()) {
+		return nil, repository.ErrUserTokenNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (m *memoryUserTokenRepository) ConsumeUserToken(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+	token, err := m.GetUserToken(ctx, purpose, hash)
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	m.tokens[hash].UsedAt = &now
+	return token, nil
+}
+
+func (m *memoryUserTokenRepository) ResetPassword(ctx context.Context, hash string, passwordHash string) (*domain.UserToken, error) {
+	token, err := m.ConsumeUserToken(ctx, domain.TokenPurposePasswordReset, hash)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.users.UpdateUserPassword(ctx, token.UserID, passwordHash); err != nil {
+		return nil, err
+	}
+	return token, m.sessions.DeleteUserSessions(ctx, token.UserID)
+}
+
+// outbox guarda as mensagens em vez de enviá-las.
+type outbox struct {
+	messages []mailer.Message
+}
+
+func (o *outbox) Send(ctx context.Context, msg mailer.Message) error {
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+// lastToken retorna o token do link da última mensagem.
+func (o *outbox) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(o.messages) == 0 {
+		t.Fatal("esperava um email enviado")
+	}
+	match := regexp.MustCompile(`\?token=(\S+)`).FindStringSubmatch(o.messages[len(o.messages)-1].Text)
+	if match == nil {
+		t.Fatalf("esperava um link no email: %q", o.messages[len(o.messages)-1].Text)
+	}
+	token, _ := url.QueryUnescape(match[1])
+	return token
+}
+
+func newTestAccountTokens(t *testing.T) (*AccountTokens, *memoryUserRepository, *memorySessionRepository, *outbox, *time.Time) {
+	t.Helper()
+	useCheapParams(t)
+	users := newMemoryUserRepository()
+	if _, err := Register(context.Background(), users, "Ana", "ana@example.com", "uma senha bem longa"); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	sessions := &memorySessionRepository{sessions: map[string]domain.Session{}}
+	tokens := &memoryUserTokenRepository{tokens: map[string]*domain.UserToken{}, now: func() time.Time { return now }, users: users, sessions: sessions}
+	mail := &outbox{}
+	accounts := NewAccountTokens(users, tokens, mail, AccountTokenOptions{
+		BaseURL:              "https://lucienne.test/",
+		PasswordResetTTL:     time.Hour,
+		EmailVerificationTTL: 48 * time.Hour,
+	})
+	accounts.now = func() time.Time { return now }
+	return accounts, users, sessions, mail, &now
+}
+
+// requestPasswordReset pede o link de redefinição e o envia na hora, como Run faria depois.
+func requestPasswordReset(t *testing.T, accounts *AccountTokens, email string) {
+	t.Helper()
+	if err := accounts.RequestPasswordReset(context.Background(), email); err != nil {
+		t.Fatal(err)
+	}
+	if err := accounts.sendPasswordReset(context.Background(), <-accounts.resets); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deve trocar a senha uma única vez e encerrar as sessões", func(t *testing.T) {
+		accounts, users, sessions, mail, _ := newTestAccountTokens(t)
+		sessions.sessions["a"] = domain.Session{ID: "a", UserID: 1}
+		sessions.sessions["b"] = domain.Session{ID: "b", UserID: 2}
+
+		requestPasswordReset(t, accounts, " ANA@example.com ")
+		if !strings.Contains(mail.messages[0].Text, "https://lucienne.test/password/reset?token=") || !strings.Contains(mail.messages[0].Text, "1 hora") {
+			t.Errorf("email inesperado: %q", mail.messages[0].Text)
+		}
+		token := mail.lastToken(t)
+
+		if err := accounts.CheckPasswordReset(ctx, token); err != nil {
+			t.Errorf("esperava o link válido, obteve %v", err)
+		}
+		if err := accounts.ResetPassword(ctx, token, "uma nova senha longa"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Authenticate(ctx, users, "ana@example.com", "uma nova senha longa"); err != nil {
+			t.Errorf("esperava entrar com a nova senha, obteve %v", err)
+		}
+		if _, ok := sessions.sessions["a"]; ok {
+			t.Error("esperava as sessões do usuário encerradas")
+		}
+		if _, ok := sessions.sessions["b"]; !ok {
+			t.Error("as sessões de outros usuários não deveriam ser encerradas")
+		}
+		if err := accounts.ResetPassword(ctx, token, "mais uma senha longa"); !errors.Is(err, ErrInvalidUserToken) {
+			t.Errorf("esperava %v ao reusar o link, obteve %v", ErrInvalidUserToken, err)
+		}
+	})
+
+	t.Run("deve recusar um link expirado", func(t *testing.T) {
+		accounts, _, _, mail, now := newTestAccountTokens(t)
+		requestPasswordReset(t, accounts, "ana@example.com")
+		*now = now.Add(61 * time.Minute)
+
+		if err := accounts.ResetPassword(ctx, mail.lastToken(t), "uma nova senha longa"); !errors.Is(err, ErrInvalidUserToken) {
+			t.Errorf("esperava %v, obteve %v", ErrInvalidUserToken, err)
+		}
+	})
+
+	t.Run("deve valer apenas o último link enviado", func(t *testing.T) {
+		accounts, _, _, mail, _ := newTestAccountTokens(t)
+		requestPasswordReset(t, accounts, "ana@example.com")
+		first := mail.lastToken(t)
+		requestPasswordReset(t, accounts, "ana@example.com")
+
+		if err := accounts.CheckPasswordReset(ctx, first); !errors.Is(err, ErrInvalidUserToken) {
+			t.Errorf("esperava %v para o primeiro link, obteve %v", ErrInvalidUserToken, err)
+		}
+		if err := accounts.CheckPasswordReset(ctx, mail.lastToken(t)); err != nil {
+			t.Errorf("esperava o último link válido, obteve %v", err)
+		}
+	})
+
+	t.Run("não deve enviar nada para um email desconhecido", func(t *testing.T) {
+		accounts, _, _, mail, _ := newTestAccountTokens(t)
+		requestPasswordReset(t, accounts, "ninguem@example.com")
+		if len(mail.messages) != 0 {
+			t.Errorf("esperava nenhum email, obteve %d", len(mail.messages))
+		}
+	})
+
+	t.Run("deve validar a nova senha antes de usar o link", func(t *testing.T) {
+		accounts, _, _, mail, _ := newTestAccountTokens(t)
+		requestPasswordReset(t, accounts, "ana@example.com")
+		token := mail.lastToken(t)
+
+		if err := accounts.ResetPassword(ctx, token, "curta"); !errors.Is(err, ErrWeakPassword) {
+			t.Errorf("esperava %v, obteve %v", ErrWeakPassword, err)
+		}
+		if err := accounts.CheckPasswordReset(ctx, token); err != nil {
+			t.Errorf("o link não deveria ter sido usado: %v", err)
+		}
+	})
+
+	t.Run("deve responder sem buscar o email e recusar quando a fila estiver cheia", func(t *testing.T) {
+		accounts, _, _, mail, _ := newTestAccountTokens(t)
+		for range passwordResetQueueSize {
+			if err := accounts.RequestPasswordReset(ctx, "ana@example.com"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(mail.messages) != 0 {
+			t.Errorf("esperava o envio só depois da resposta, mas obteve %d emails", len(mail.messages))
+		}
+		if err := accounts.RequestPasswordReset(ctx, "ana@example.com"); !errors.Is(err, ErrPasswordResetQueueFull) {
+			t.Errorf("esperava %v, obteve %v", ErrPasswordResetQueueFull, err)
+		}
+	})
+
+	t.Run("deve enviar os links pedidos enquanto Run estiver rodando", func(t *testing.T) {
+		accounts, _, _, mail, _ := newTestAccountTokens(t)
+		// Sem a fila, cada pedido só é entregue quando Run terminou o anterior.
+		accounts.resets = make(chan string)
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- accounts.Run(runCtx) }()
+
+		accounts.resets <- "ana@example.com"
+		accounts.resets <- "ninguem@example.com"
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("esperava %v, obteve %v", context.Canceled, err)
+		}
+		if len(mail.messages) != 1 {
+			t.Errorf("esperava 1 email, obteve %d", len(mail.messages))
+		}
+	})
+}
+
+func TestEmailVerification(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deve confirmar o email", func(t *testing.T) {
+		accounts, users, _, mail, _ := newTestAccountTokens(t)
+		user, _ := users.GetUserByEmail(ctx, "ana@example.com")
+		if err := accounts.SendEmailVerification(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(mail.messages[0].Text, "/email/verify?token=") || !strings.Contains(mail.messages[0].Text, "48 horas") {
+			t.Errorf("email inesperado: %q", mail.messages[0].Text)
+		}
+
+		if err := accounts.VerifyEmail(ctx, mail.lastToken(t)); err != nil {
+			t.Fatal(err)
+		}
+		if !users.users["ana@example.com"].EmailVerified() {
+			t.Error("esperava o email confirmado")
+		}
+	})
+
+	t.Run("deve recusar o link de um email que mudou depois do envio", func(t *testing.T) {
+		accounts, users, _, mail, _ := newTestAccountTokens(t)
+		user, _ := users.GetUserByEmail(ctx, "ana@example.com")
+		user.Email = "antigo@example.com"
+		accounts.SendEmailVerification(ctx, user)
+
+		if err := accounts.VerifyEmail(ctx, mail.lastToken(t)); !errors.Is(err, ErrInvalidUserToken) {
+			t.Errorf("esperava %v, obteve %v", ErrInvalidUserToken, err)
+		}
+		if users.users["ana@example.com"].EmailVerified() {
+			t.Error("o email atual não deveria ser confirmado")
+		}
+	})
+
+	t.Run("não deve aceitar um link de redefinição de senha", func(t *testing.T) {
+		accounts, _, _, mail, _ := newTestAccountTokens(t)
+		requestPasswordReset(t, accounts, "ana@example.com")
+
+		if err := accounts.VerifyEmail(ctx, mail.lastToken(t)); !errors.Is(err, ErrInvalidUserToken) {
+			t.Errorf("esperava %v, obteve %v", ErrInvalidUserToken, err)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/password"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MinPasswordLength é o tamanho mínimo de uma senha, em caracteres.
+	MinPasswordLength = 12
+	// MaxPasswordLength limita o custo do hash de senhas enormes.
+	MaxPasswordLength = 128
+)
+
+var (
+	// ErrInvalidCredentials é retornado quando o email não existe ou a senha não confere, sem distinguir os casos.
+	ErrInvalidCredentials = errors.New("email ou senha inválidos")
+
+	// ErrInvalidEmail é retornado para um email em formato inválido.
+	ErrInvalidEmail = errors.New("email inválido")
+
+	// ErrNameRequired é retornado quando o nome do usuário está em branco.
+	ErrNameRequired = errors.New(`o campo "name" é obrigatório`)
+
+	// ErrWeakPassword é retornado quando a senha não respeita os limites de tamanho.
+	ErrWeakPassword = fmt.Errorf("a senha deve ter entre %d e %d caracteres", MinPasswordLength, MaxPasswordLength)
+
+	// ErrPasswordNotSet é retornado ao trocar a senha de um usuário que só entra pelo login único.
+	ErrPasswordNotSet = errors.New("o usuário não tem senha")
+)
+
+// dummyHash é verificado quando o email não existe, para que o tempo de resposta não revele quais emails estão cadastrados.
+var dummyHash, _ = password.Hash("lucienne-dummy-password")
+
+// NormalizeEmail remove os espaços ao redor do email e valida o formato. Endereços com nome ("Ana <ana@x.com>") são recusados.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+// ValidatePassword verifica os limites de tamanho da senha.
+func ValidatePassword(plain string) error {
+	length := utf8.RuneCountInString(plain)
+	if length < MinPasswordLength || length > MaxPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// Register valida os dados e cria um novo usuário com a senha em hash.
+func Register(ctx context.Context, repo repository.UserRepository, name string, email string, plain string) (*domain.User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrNameRequired
+	}
+	email, err := NormalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidatePassword(plain); err != nil {
+		return nil, err
+	}
+
+	hash, err := password.Hash(plain)
+	if err != nil {
+		return nil, err
+	}
+	user := &domain.User{Name: name, Email: email, PasswordHash: hash}
+	if err := repo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Authenticate retorna o usuário dono do email se a senha conferir. Quando o hash foi gerado com parâmetros
+// antigos, ele é refeito com os atuais, aproveitando que a senha está disponível apenas neste momento.
+func Authenticate(ctx context.Context, repo repository.UserRepository, email string, plain string) (*domain.User, error) {
+	user, err := repo.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		password.Verify(plain, dummyHash)
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasPassword() {
+		password.Verify(plain, dummyHash)
+		return nil, ErrInvalidCredentials
+	}
+
+	match, needsRehash, err := password.Verify(plain, user.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	if !match {
+		return nil, ErrInvalidCredentials
+	}
+
+	if needsRehash {
+		if hash, err := password.Hash(plain); err == nil {
+			if err := repo.UpdateUserPassword(ctx, user.ID, hash); err != nil {
+				// O login continua válido; o hash será atualizado em um próximo login.
+				slog.WarnContext(ctx, "erro ao atualizar o hash da senha", "error", err, "user_id", user.ID)
+			} else {
+				user.PasswordHash = hash
+			}
+		}
+	}
+	return user, nil
+}
+
+// ChangePassword troca a senha do usuário depois de confirmar a senha atual.
+func ChangePassword(ctx context.Context, repo repository.UserRepository, user *domain.User, current string, plain string) error {
+	if !user.HasPassword() {
+		return ErrPasswordNotSet
+	}
+	match, _, err := password.Verify(current, user.PasswordHash)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrInvalidCredentials
+	}
+	if err := ValidatePassword(plain); err != nil {
+		return err
+	}
+
+	hash, err := password.Hash(plain)
+	if err != nil {
+		return err
+	}
+	if err := repo.UpdateUserPassword(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	user.PasswordHash = hash
+	return nil
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/password"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryUserRepository guarda os usuários em memória, indexados pelo email em minúsculas.
+type memoryUserRepository struct {
+	users         map[string]*domain.User
+	passwordSaved int
+}
+
+func newMemoryUserRepository() *memoryUserRepository {
+	return &memoryUserRepository{users: map[string]*domain.User{}}
+}
+
+func (m *memoryUserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	key := strings.ToLower(user.Email)
+	if _, ok := m.users[key]; ok {
+		return repository.ErrUserAlreadyExists
+	}
+	user.ID = int64(len(m.users) + 1)
+	copied := *user
+	m.users[key] = &copied
+	return nil
+}
+
+func (m *memoryUserRepository) GetUsers(ctx context.Context) ([]domain.User, error) {
+	return nil, errors.New("não implementado")
+}
+
+func (m *memoryUserRepository) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
+	for _, user := range m.users {
+		if user.ID == id {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (m *memoryUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, ok := m.users[strings.ToLower(email)]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (m *memoryUserRepository) UpdateUserProfile(ctx context.Context, id int64, name string, email string) error {
+	return errors.New("não implementado")
+}
+
+func (m *memoryUserRepository) UpdateUserPassword(ctx context.Context, id int64, passwordHash string) error {
+	for _, user := range m.users {
+		if user.ID == id {
+			user.PasswordHash = passwordHash
+			m.passwordSaved++
+			return nil
+		}
+	}
+	return repository.ErrUserNotFound
+}
+
+func (m *memoryUserRepository) MarkEmailVerified(ctx context.Context, id int64, email string) error {
+	for _, user := range m.users {
+		if user.ID == id && strings.EqualFold(user.Email, email) {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+			return nil
+		}
+	}
+	return repository.ErrUserNotFound
+}
+
+// useCheapParams reduz o custo do argon2id durante o teste.
+func useCheapParams(t *testing.T) {
+	t.Helper()
+	original := password.DefaultParams
+	password.DefaultParams = password.Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	t.Cleanup(func() { password.DefaultParams = original })
+}
+
+func TestRegister(t *testing.T) {
+	useCheapParams(t)
+
+	tests := []struct {
+		name     string
+		userName string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{name: "Cadastro válido", userName: "Ana", email: " ana@example.com ", password: "uma senha bem longa"},
+		{name: "Email duplicado com outra caixa", userName: "Ana", email: "ANA@example.com", password: "uma senha bem longa", wantErr: repository.ErrUserAlreadyExists},
+		{name: "Nome em branco", userName: "  ", email: "bia@example.com", password: "uma senha bem longa", wantErr: ErrNameRequired},
+		{name: "Email com nome", userName: "Bia", email: "Bia <bia@example.com>", password: "uma senha bem longa", wantErr: ErrInvalidEmail},
+		{name: "Senha curta", userName: "Bia", email: "bia@example.com", password: "curta", wantErr: ErrWeakPassword},
+	}
+
+	repo := newMemoryUserRepository()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := Register(context.Background(), repo, tt.userName, tt.email, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("erro inesperado: got %v want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if user.Email != "ana@example.com" {
+				t.Errorf("email não foi normalizado: got %q", user.Email)
+			}
+			if user.PasswordHash == tt.password || !strings.HasPrefix(user.PasswordHash, "$argon2id$") {
+				t.Errorf("senha não foi guardada em hash: %q", user.PasswordHash)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	useCheapParams(t)
+	repo := newMemoryUserRepository()
+	if _, err := Register(context.Background(), repo, "Ana", "ana@example.com", "uma senha bem longa"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Authenticate(context.Background(), repo, "ANA@example.com", "uma senha bem longa"); err != nil {
+		t.Errorf("login com a senha correta falhou: %v", err)
+	}
+	if _, err := Authenticate(context.Background(), repo, "ana@example.com", "outra senha qualquer"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("senha errada: got %v want %v", err, ErrInvalidCredentials)
+	}
+	if _, err := Authenticate(context.Background(), repo, "ninguem@example.com", "uma senha bem longa"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("email inexistente: got %v want %v", err, ErrInvalidCredentials)
+	}
+	if repo.passwordSaved != 0 {
+		t.Errorf("hash não deveria ser refeito com os parâmetros atuais, foi refeito %d vezes", repo.passwordSaved)
+	}
+
+	// Usuários do login único não têm senha e não entram pelo formulário.
+	sso := &domain.User{Name: "Bia", Email: "bia@example.com"}
+	repo.CreateUser(context.Background(), sso)
+	if _, err := Authenticate(context.Background(), repo, "bia@example.com", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("usuário sem senha: got %v want %v", err, ErrInvalidCredentials)
+	}
+	if err := ChangePassword(context.Background(), repo, sso, "", "uma nova senha longa"); !errors.Is(err, ErrPasswordNotSet) {
+		t.Errorf("troca de senha sem senha: got %v want %v", err, ErrPasswordNotSet)
+	}
+}
+
+func TestAuthenticateRehashesOutdatedParams(t *testing.T) {
+	useCheapParams(t)
+	repo := newMemoryUserRepository()
+	if _, err := Register(context.Background(), repo, "Ana", "ana@example.com", "uma senha bem longa"); err != nil {
+		t.Fatal(err)
+	}
+	oldHash := repo.users["ana@example.com"].PasswordHash
+
+	// Simula o aumento do custo do hash em uma nova versão da aplicação.
+	password.DefaultParams.Iterations = 2
+
+	user, err := Authenticate(context.Background(), repo, "ana@example.com", "uma senha bem longa")
+	if err != nil {
+		t.Fatalf("login falhou: %v", err)
+	}
+	if repo.passwordSaved != 1 {
+		t.Fatalf("esperava o hash refeito uma vez, foi refeito %d vezes", repo.passwordSaved)
+	}
+	if user.PasswordHash == oldHash || !strings.Contains(user.PasswordHash, "t=2") {
+		t.Errorf("hash não foi atualizado com os novos parâmetros: %q", user.PasswordHash)
+	}
+	if _, needsRehash, _ := password.Verify("uma senha bem longa", repo.users["ana@example.com"].PasswordHash); needsRehash {
+		t.Error("o hash salvo ainda usa os parâmetros antigos")
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	useCheapParams(t)
+	repo := newMemoryUserRepository()
+	user, err := Register(context.Background(), repo, "Ana", "ana@example.com", "uma senha bem longa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ChangePassword(context.Background(), repo, user, "senha errada!!", "uma nova senha longa"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("senha atual errada: got %v want %v", err, ErrInvalidCredentials)
+	}
+	if err := ChangePassword(context.Background(), repo, user, "uma senha bem longa", "curta"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("nova senha curta: got %v want %v", err, ErrWeakPassword)
+	}
+	if err := ChangePassword(context.Background(), repo, user, "uma senha bem longa", "uma nova senha longa"); err != nil {
+		t.Fatalf("troca de senha falhou: %v", err)
+	}
+	if _, err := Authenticate(context.Background(), repo, "ana@example.com", "uma nova senha longa"); err != nil {
+		t.Errorf("login com a nova senha falhou: %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// APITokenPrefix identifica os tokens da aplicação, por exemplo para scanners de segredos vazados.
+	APITokenPrefix = "lct_"
+
+	apiTokenLength = 32
+	// MaxAPITokenLifetime é a validade máxima de um token; tokens sem expiração não são permitidos.
+	MaxAPITokenLifetime = 365 * 24 * time.Hour
+)
+
+// Escopos que limitam o que um token de API pode fazer, além das permissões do próprio usuário.
+const (
+	ScopeAuthorsRead     = "authors:read"
+	ScopeAuthorsWrite    = "authors:write"
+	ScopePublishersRead  = "publishers:read"
+	ScopePublishersWrite = "publishers:write"
+	ScopeAuditRead       = "audit:read"
+)
+
+// Scope descreve um escopo de token de API.
+type Scope struct {
+	Name        string
+	Description string
+	// Permission é a permissão que o usuário precisa ter para criar e usar um token com o escopo.
+	Permission string
+}
+
+// Scopes lista os escopos disponíveis, na ordem em que são exibidos.
+var Scopes = []Scope{
+	{Name: ScopeAuthorsRead, Description: "Listar autores"},
+	{Name: ScopeAuthorsWrite, Description: "Criar autores", Permission: PermissionManageAuthors},
+	{Name: ScopePublishersRead, Description: "Listar editoras"},
+	{Name: ScopePublishersWrite, Description: "Criar editoras", Permission: PermissionManagePublishers},
+	{Name: ScopeAuditRead, Description: "Consultar o histórico de alterações", Permission: PermissionViewAudit},
+}
+
+var (
+	// ErrInvalidAPIToken é retornado para um token inexistente, revogado ou expirado.
+	ErrInvalidAPIToken = errors.New("token de API inválido")
+
+	// ErrAPITokenNameRequired é retornado quando o nome do token está em branco.
+	ErrAPITokenNameRequired = errors.New(`o campo "name" é obrigatório`)
+
+	// ErrInvalidScopes é retornado quando nenhum escopo é informado, algum não existe ou exige uma permissão que o usuário não tem.
+	ErrInvalidScopes = errors.New("escopos inválidos")
+
+	// ErrInvalidAPITokenLifetime é retornado quando a validade não está entre um dia e MaxAPITokenLifetime.
+	ErrInvalidAPITokenLifetime = errors.New("validade do token inválida")
+)
+
+type apiTokenKey struct{}
+
+// WithAPIToken retorna uma cópia de ctx com o token que autenticou a requisição.
+func WithAPIToken(ctx context.Context, token *domain.APIToken) context.Context {
+	return context.WithValue(ctx, apiTokenKey{}, token)
+}
+
+// CurrentAPIToken retorna o token que autenticou a requisição, ou nil se ela não foi autenticada por token.
+func CurrentAPIToken(ctx context.Context) *domain.APIToken {
+	token, _ := ctx.Value(apiTokenKey{}).(*domain.APIToken)
+	return token
+}
+
+// CreateAPIToken gera um novo token para o usuário e o guarda pelo hash. O token em texto é retornado
+// apenas aqui, para ser exibido uma única vez.
+func CreateAPIToken(ctx context.Context, repo repository.APITokenRepository, user *domain.User, name string, scopes []string, lifetime time.Duration) (string, *domain.APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, ErrAPITokenNameRequired
+	}
+	if lifetime < 24*time.Hour || lifetime > MaxAPITokenLifetime {
+		return "", nil, ErrInvalidAPITokenLifetime
+	}
+	scopes = slices.Compact(slices.Sorted(slices.Values(scopes)))
+	if len(scopes) == 0 || slices.ContainsFunc(scopes, func(scope string) bool { return !canGrant(user, scope) }) {
+		return "", nil, ErrInvalidScopes
+	}
+
+	secret := make([]byte, apiTokenLength)
+	rand.Read(secret)
+	plain := APITokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	token := &domain.APIToken{
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: HashAPIToken(plain),
+		Prefix:    plain[:len(APITokenPrefix)+6],
+		Scopes:    scopes,
+		ExpiresAt: time.Now().Add(lifetime),
+	}
+	if err := repo.CreateAPIToken(ctx, token); err != nil {
+		return "", nil, err
+	}
+	return plain, token, nil
+}
+
+// HashAPIToken retorna o hash guardado no banco para o token.
+func HashAPIToken(plain string) string {
+	return hashToken(plain)
+}
+
+// LookupScope retorna o escopo com o nome informado.
+func LookupScope(name string) (Scope, bool) {
+	index := slices.IndexFunc(Scopes, func(scope Scope) bool { return scope.Name == name })
+	if index < 0 {
+		return Scope{}, false
+	}
+	return Scopes[index], true
+}
+
+// canGrant informa se o escopo existe e o usuário tem a permissão que ele exige.
+func canGrant(user *domain.User, name string) bool {
+	scope, ok := LookupScope(name)
+	return ok && (scope.Permission == "" || user.Can(scope.Permission))
+}
+
+// APITokenAuthenticator autentica as requisições da API pelo cabeçalho Authorization: Bearer.
+type APITokenAuthenticator struct {
+	tokens repository.APITokenRepository
+	users  repository.UserRepository
+	now    func() time.Time
+}
+
+// NewAPITokenAuthenticator cria um APITokenAuthenticator com suas dependências.
+func NewAPITokenAuthenticator(tokens repository.APITokenRepository, users repository.UserRepository) *APITokenAuthenticator {
+	return &APITokenAuthenticator{tokens: tokens, users: users, now: time.Now}
+}
+
+// Authenticate retorna o usuário e o token correspondentes ao token em texto, registrando o uso do token.
+func (a *APITokenAuthenticator) Authenticate(ctx context.Context, plain string) (*domain.User, *domain.APIToken, error) {
+	if !strings.HasPrefix(plain, APITokenPrefix) {
+		return nil, nil, ErrInvalidAPIToken
+	}
+	token, err := a.tokens.GetAPITokenByHash(ctx, HashAPIToken(plain))
+	if errors.Is(err, repository.ErrAPITokenNotFound) {
+		return nil, nil, ErrInvalidAPIToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := a.now()
+	if !token.Active(now) {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	user, err := a.users.GetUserByID(ctx, token.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, nil, ErrInvalidAPIToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Como nas sessões, o último uso só é gravado quando o registrado é mais antigo que touchInterval.
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > touchInterval {
+		if err := a.tokens.TouchAPIToken(ctx, token.ID, now); err != nil {
+			slog.WarnContext(ctx, "erro ao registrar o uso do token de API", "error", err, "token_id", token.ID)
+		}
+		token.LastUsedAt = &now
+	}
+	return user, token, nil
+}
+
+// Middleware carrega no contexto o usuário e o token enviados em Authorization: Bearer, disponíveis por
+// CurrentUser e CurrentAPIToken. Requisições sem o cabeçalho seguem anônimas, como o preflight de CORS; um
+// token inválido recebe 401. A exigência de token fica a cargo de middleware.RequireAPIScope.
+func (a *APITokenAuthenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		plain, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			writeInvalidToken(w, "Use o cabeçalho Authorization: Bearer <token>")
+			return
+		}
+		user, token, err := a.Authenticate(r.Context(), strings.TrimSpace(plain))
+		if errors.Is(err, ErrInvalidAPIToken) {
+			writeInvalidToken(w, "Token de API inválido, revogado ou expirado")
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(r.Context(), "erro ao autenticar o token de API", "error", err)
+			http.Error(w, "Erro interno ao autenticar", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := WithAPIToken(WithUser(r.Context(), user), token)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func writeInvalidToken(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"error": "invalid_token", "message": message})
+}
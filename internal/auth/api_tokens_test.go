@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryAPITokenRepository guarda os tokens em memória, indexados pelo hash.
+type memoryAPITokenRepository struct {
+	tokens  map[string]*domain.APIToken
+	touches int
+}
+
+func (m *memoryAPITokenRepository) CreateAPIToken(ctx context.Context, token *domain.APIToken) error {
+	token.ID = int64(len(m.tokens) + 1)
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *memoryAPITokenRepository) GetUserAPITokens(ctx context.Context, userID int64) ([]domain.APIToken, error) {
+	return nil, errors.New("não implementado")
+}
+
+func (m *memoryAPITokenRepository) GetAPITokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	token, ok := m.tokens[hash]
+	if !ok {
+		return nil, repository.ErrAPITokenNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (m *memoryAPITokenRepository) TouchAPIToken(ctx context.Context, id int64, lastUsedAt time.Time) error {
+	m.touches++
+	for _, token := range m.tokens {
+		if token.ID == id {
+			token.LastUsedAt = &lastUsedAt
+		}
+	}
+	return nil
+}
+
+func (m *memoryAPITokenRepository) RevokeAPIToken(ctx context.Context, userID int64, id int64) error {
+	for _, token := range m.tokens {
+		if token.ID == id && token.UserID == userID {
+			now := time.Now()
+			token.RevokedAt = &now
+			return nil
+		}
+	}
+	return repository.ErrAPITokenNotFound
+}
+
+func TestCreateAPIToken(t *testing.T) {
+	member := &domain.User{ID: 1, Roles: []string{RoleMember}}
+	librarian := &domain.User{ID: 2, Roles: []string{RoleLibrarian}, Permissions: []string{PermissionManageAuthors}}
+
+	tests := []struct {
+		name     string
+		user     *domain.User
+		scopes   []string
+		lifetime time.Duration
+		wantErr  error
+	}{
+		{name: "Escopo de leitura para membro", user: member, scopes: []string{ScopeAuthorsRead}, lifetime: 30 * 24 * time.Hour},
+		{name: "Escopo de escrita para bibliotecário", user: librarian, scopes: []string{ScopeAuthorsRead, ScopeAuthorsWrite}, lifetime: 90 * 24 * time.Hour},
+		{name: "Escopo de escrita sem a permissão", user: member, scopes: []string{ScopeAuthorsWrite}, lifetime: 30 * 24 * time.Hour, wantErr: ErrInvalidScopes},
+		{name: "Escopo desconhecido", user: librarian, scopes: []string{"books:delete"}, lifetime: 30 * 24 * time.Hour, wantErr: ErrInvalidScopes},
+		{name: "Sem escopos", user: librarian, lifetime: 30 * 24 * time.Hour, wantErr: ErrInvalidScopes},
+		{name: "Validade acima do máximo", user: librarian, scopes: []string{ScopeAuthorsRead}, lifetime: 2 * MaxAPITokenLifetime, wantErr: ErrInvalidAPITokenLifetime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryAPITokenRepository{tokens: map[string]*domain.APIToken{}}
+			plain, token, err := CreateAPIToken(context.Background(), repo, tt.user, "sincronização", tt.scopes, tt.lifetime)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("erro inesperado: got %v want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !strings.HasPrefix(plain, APITokenPrefix) || !strings.HasPrefix(plain, token.Prefix) {
+				t.Errorf("token com formato inesperado: %q (prefixo %q)", plain, token.Prefix)
+			}
+			if _, ok := repo.tokens[HashAPIToken(plain)]; !ok {
+				t.Error("o token deveria ser guardado pelo hash")
+			}
+			if strings.Contains(token.TokenHash, plain) {
+				t.Error("o token em texto não deveria ser guardado")
+			}
+		})
+	}
+}
+
+func TestAPITokenAuthenticator(t *testing.T) {
+	useCheapParams(t)
+	users := newMemoryUserRepository()
+	user, err := Register(context.Background(), users, "Ana", "ana@example.com", "uma senha bem longa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := &memoryAPITokenRepository{tokens: map[string]*domain.APIToken{}}
+	plain, token, err := CreateAPIToken(context.Background(), tokens, user, "script", []string{ScopeAuthorsRead}, 30*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator := NewAPITokenAuthenticator(tokens, users)
+
+	serve := func(header string) (*httptest.ResponseRecorder, *domain.APIToken) {
+		var current *domain.APIToken
+		req := httptest.NewRequest("GET", "/api/authors", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rr := httptest.NewRecorder()
+		authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current = CurrentAPIToken(r.Context())
+		})).ServeHTTP(rr, req)
+		return rr, current
+	}
+
+	t.Run("deve autenticar o token e registrar o uso", func(t *testing.T) {
+		rr, current := serve("Bearer " + plain)
+		if rr.Code != http.StatusOK || current == nil || current.ID != token.ID {
+			t.Fatalf("esperava o token %d no contexto, obteve %+v (status %d)", token.ID, current, rr.Code)
+		}
+		serve("Bearer " + plain)
+		if tokens.touches != 1 {
+			t.Errorf("o uso deveria ser gravado uma vez por minuto, foi gravado %d vezes", tokens.touches)
+		}
+	})
+
+	t.Run("deve seguir anônimo sem o cabeçalho", func(t *testing.T) {
+		if rr, current := serve(""); rr.Code != http.StatusOK || current != nil {
+			t.Errorf("esperava a requisição anônima, obteve %+v (status %d)", current, rr.Code)
+		}
+	})
+
+	t.Run("deve recusar tokens inválidos", func(t *testing.T) {
+		for _, header := range []string{"Bearer lct_desconhecido", "Bearer outro", "Basic " + plain} {
+			rr, _ := serve(header)
+			if rr.Code != http.StatusUnauthorized || !strings.Contains(rr.Header().Get("WWW-Authenticate"), "invalid_token") {
+				t.Errorf("%q: esperava 401 com invalid_token, obteve %d", header, rr.Code)
+			}
+		}
+	})
+
+	t.Run("deve recusar o token expirado", func(t *testing.T) {
+		authenticator.now = func() time.Time { return time.Now().Add(31 * 24 * time.Hour) }
+		t.Cleanup(func() { authenticator.now = time.Now })
+		if rr, _ := serve("Bearer " + plain); rr.Code != http.StatusUnauthorized {
+			t.Errorf("esperava 401, obteve %d", rr.Code)
+		}
+	})
+
+	t.Run("deve recusar o token revogado", func(t *testing.T) {
+		if err := tokens.RevokeAPIToken(context.Background(), user.ID, token.ID); err != nil {
+			t.Fatal(err)
+		}
+		if rr, _ := serve("Bearer " + plain); rr.Code != http.StatusUnauthorized {
+			t.Errorf("esperava 401, obteve %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+	"lucienne/internal/audit"
+	"lucienne/internal/domain"
+)
+
+type userKey struct{}
+
+// WithUser retorna uma cópia de ctx com o usuário autenticado na requisição. O usuário passa também a ser o autor
+// das alterações registradas no histórico.
+func WithUser(ctx context.Context, user *domain.User) context.Context {
+	if user != nil {
+		ctx = audit.WithActor(ctx, audit.Actor{UserID: user.ID, Email: user.Email})
+	}
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// CurrentUser retorna o usuário autenticado na requisição, ou nil para visitantes anônimos.
+func CurrentUser(ctx context.Context) *domain.User {
+	user, _ := ctx.Value(userKey{}).(*domain.User)
+	return user
+}
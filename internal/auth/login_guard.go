@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/mailer"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// LoginThrottledError é retornado quando as tentativas de login da conta ou do IP estão sendo recusadas.
+type LoginThrottledError struct {
+	// RetryAfter é quanto falta para uma nova tentativa ser aceita.
+	RetryAfter time.Duration
+	// Locked informa se há um bloqueio, e não apenas o atraso entre as tentativas.
+	Locked bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("login bloqueado por %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("nova tentativa de login em %s", e.RetryAfter)
+}
+
+// LoginGuardOptions configura a proteção contra tentativas de adivinhar senhas.
+type LoginGuardOptions struct {
+	// DelayAfter é a quantidade de falhas de uma conta ou de um IP aceitas sem atraso.
+	DelayAfter int
+	// BaseDelay é o atraso depois de DelayAfter falhas, dobrado a cada nova falha.
+	BaseDelay time.Duration
+	// MaxFailures é a quantidade de falhas que bloqueia a conta por LockDuration.
+	MaxFailures int
+	// IPMaxFailures é a quantidade de falhas, somadas todas as contas, que bloqueia o IP por LockDuration.
+	IPMaxFailures int
+	LockDuration  time.Duration
+	// FailureWindow é por quanto tempo uma falha é lembrada.
+	FailureWindow time.Duration
+	// BaseURL é o endereço público da aplicação, usado no link do email de aviso do bloqueio.
+	BaseURL string
+}
+
+// LoginGuard conta as falhas de login por conta e por IP, atrasa as tentativas seguintes e, depois de muitas
+// falhas, bloqueia a conta ou o IP por um tempo, avisando o dono da conta por email.
+type LoginGuard struct {
+	repo    repository.LoginThrottleRepository
+	users   repository.UserRepository
+	mailer  mailer.Mailer
+	options LoginGuardOptions
+	now     func() time.Time
+}
+
+// NewLoginGuard cria um LoginGuard com suas dependências.
+func NewLoginGuard(repo repository.LoginThrottleRepository, users repository.UserRepository, mail mailer.Mailer, options LoginGuardOptions) *LoginGuard {
+	options.BaseURL = strings.TrimSuffix(options.BaseURL, "/")
+	return &LoginGuard{repo: repo, users: users, mailer: mail, options: options, now: time.Now}
+}
+
+// LoginAttempt é uma tentativa de login reservada por LoginGuard.Begin, já contada como falha. Depois de conferir
+// a senha ou o código, chame Failed, Release ou Succeeded.
+type LoginAttempt struct {
+	guard   *LoginGuard
+	subject string
+	ip      string
+	at      time.Time
+	account *domain.LoginThrottle
+	address *domain.LoginThrottle
+}
+
+// Begin reserva a tentativa de login para a conta e para o IP, contando-a como uma falha antes de a senha ou o
+// código ser conferido, e retorna um *LoginThrottledError se as tentativas estiverem sendo recusadas. Como a
+// reserva é atômica, tentativas simultâneas não passam todas pelo atraso antes de a primeira falha ser gravada.
+func (g *LoginGuard) Begin(ctx context.Context, email string, ip string) (*LoginAttempt, error) {
+	// O banco guarda os horários em microssegundos; a reserva é reconhecida depois pelo horário.
+	attempt := &LoginAttempt{guard: g, subject: normalizeLoginEmail(email), ip: ip, at: g.now().Truncate(time.Microsecond)}
+
+	account, err := g.repo.ReserveLoginAttempt(ctx, domain.LoginThrottleAccount, attempt.subject, ip, attempt.at, g.policy(g.options.MaxFailures))
+	if errors.Is(err, repository.ErrLoginThrottled) {
+		return nil, g.throttled(attempt.at, account)
+	}
+	if err != nil {
+		return nil, err
+	}
+	attempt.account = account
+
+	address, err := g.repo.ReserveLoginAttempt(ctx, domain.LoginThrottleIP, ip, ip, attempt.at, g.policy(g.options.IPMaxFailures))
+	if errors.Is(err, repository.ErrLoginThrottled) {
+		if err := g.repo.ReleaseLoginAttempt(ctx, account.ID, attempt.at); err != nil {
+			return nil, err
+		}
+		return nil, g.throttled(attempt.at, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	attempt.address = address
+	return attempt, nil
+}
+
+// Failed confirma a tentativa como uma senha ou um código da verificação em duas etapas errado. Se ela bloqueou
+// a conta, o dono é avisado por email.
+func (a *LoginAttempt) Failed(ctx context.Context) {
+	// Só o primeiro bloqueio da janela é avisado, para não mandar um email a cada nova tentativa.
+	if a.account.LockedBy(a.at) {
+		slog.WarnContext(ctx, "conta bloqueada por excesso de falhas de login", "failures", a.account.Failures, "ip", a.ip)
+		a.guard.notify(ctx, a.subject, a.account.Failures, a.ip)
+	}
+	if a.address.LockedBy(a.at) {
+		slog.WarnContext(ctx, "IP bloqueado por excesso de falhas de login", "failures", a.address.Failures, "ip", a.ip)
+	}
+}
+
+// Release desfaz a reserva de uma tentativa que não errou a senha nem o código, como a senha certa de um login que
+// ainda aguarda o código da verificação em duas etapas. As falhas anteriores da conta continuam valendo.
+func (a *LoginAttempt) Release(ctx context.Context) error {
+	if err := a.guard.repo.ReleaseLoginAttempt(ctx, a.account.ID, a.at); err != nil {
+		return err
+	}
+	return a.guard.repo.ReleaseLoginAttempt(ctx, a.address.ID, a.at)
+}
+
+// Succeeded esquece as falhas da conta depois de um login completo. As do IP continuam valendo, para que quem
+// conhece uma senha não as use para continuar testando outras contas; só a reserva desta tentativa é desfeita.
+func (a *LoginAttempt) Succeeded(ctx context.Context) error {
+	if err := a.guard.repo.ClearLoginThrottle(ctx, domain.LoginThrottleAccount, a.subject); err != nil {
+		return err
+	}
+	return a.guard.repo.ReleaseLoginAttempt(ctx, a.address.ID, a.at)
+}
+
+// Locked lista as contas e os IPs bloqueados agora.
+func (g *LoginGuard) Locked(ctx context.Context) ([]domain.LoginThrottle, error) {
+	return g.repo.GetLockedLogins(ctx)
+}
+
+// Unlock remove o bloqueio e as falhas de uma conta ou de um IP, a pedido de um administrador.
+func (g *LoginGuard) Unlock(ctx context.Context, admin *domain.User, id int64) error {
+	if err := g.repo.UnlockLogin(ctx, id); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "bloqueio de login removido por um administrador", "throttle_id", id, "admin_id", admin.ID)
+	return nil
+}
+
+// policy retorna as regras de atraso e bloqueio de um escopo, que só diferem na quantidade de falhas do bloqueio.
+func (g *LoginGuard) policy(maxFailures int) domain.LoginThrottlePolicy {
+	return domain.LoginThrottlePolicy{
+		DelayAfter:   g.options.DelayAfter,
+		BaseDelay:    g.options.BaseDelay,
+		MaxFailures:  maxFailures,
+		LockDuration: g.options.LockDuration,
+		Window:       g.options.FailureWindow,
+	}
+}
+
+// throttled descreve a recusa de uma tentativa pela conta ou pelo IP.
+func (g *LoginGuard) throttled(now time.Time, throttle *domain.LoginThrottle) error {
+	var retryAfter time.Duration
+	if throttle.LockedUntil != nil {
+		retryAfter = throttle.LockedUntil.Sub(now)
+	}
+	return &LoginThrottledError{RetryAfter: retryAfter, Locked: throttle.Locked(now)}
+}
+
+// notify avisa o dono da conta do bloqueio. Um erro no envio não impede o bloqueio e é apenas registrado.
+func (g *LoginGuard) notify(ctx context.Context, email string, failures int, ip string) {
+	user, err := g.users.GetUserByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao buscar o dono da conta bloqueada", "error", err)
+		return
+	}
+	if !user.HasPassword() {
+		// Os usuários do login único não entram com senha; as tentativas não os afetam.
+		return
+	}
+
+	to := (&mail.Address{Name: user.Name, Address: user.Email}).String()
+	err = g.mailer.Send(ctx, mailer.Message{To: to, Subject: "Sua conta no lucienne foi bloqueada", Text: fmt.Sprintf(
+		"Olá, %s.\n\nDepois de %d tentativas de login com a senha errada, a última do IP %s, bloqueamos sua conta por %s.\n\nSe foi você, espere e tente de novo, ou redefina a senha em %s/password/forgot.\nSe não foi você, sua senha continua protegida, mas recomendamos trocá-la por uma que você não use em outros sites e ativar a verificação em duas etapas no seu perfil.\n",
+		user.Name, failures, ip, formatTTL(g.options.LockDuration), g.options.BaseURL,
+	)})
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao enviar o aviso de conta bloqueada", "error", err, "user_id", user.ID)
+	}
+}
+
+// normalizeLoginEmail conta as falhas do email sem diferenciar maiúsculas e minúsculas, como no cadastro.
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryLoginThrottleRepository guarda as falhas de login em memória.
+type memoryLoginThrottleRepository struct {
+	throttles map[[2]string]*domain.LoginThrottle
+	nextID    int64
+}
+
+func (m *memoryLoginThrottleRepository) GetLoginThrottle(ctx context.Context, scope string, subject string) (*domain.LoginThrottle, error) {
+	throttle, ok := m.throttles[[2]string{scope, subject}]
+	if !ok {
+		return nil, repository.ErrLoginThrottleNotFound
+	}
+	copied := *throttle
+	return &copied, nil
+}
+
+func (m *memoryLoginThrottleRepository) ReserveLoginAttempt(ctx context.Context, scope string, subject string, ip string, at time.Time, policy domain.LoginThrottlePolicy) (*domain.LoginThrottle, error) {
+	key := [2]string{scope, subject}
+	throttle, ok := m.throttles[key]
+	if !ok {
+		m.nextID++
+		throttle = &domain.LoginThrottle{ID: m.nextID, Scope: scope, Subject: subject}
+		m.throttles[key] = throttle
+	}
+	reserved := throttle.Reserve(policy, ip, at)
+	copied := *throttle
+	if !reserved {
+		return &copied, repository.ErrLoginThrottled
+	}
+	return &copied, nil
+}
+
+func (m *memoryLoginThrottleRepository) ReleaseLoginAttempt(ctx context.Context, id int64, at time.Time) error {
+	for _, throttle := range m.throttles {
+		if throttle.ID == id {
+			throttle.Release(at)
+		}
+	}
+	return nil
+}
+
+func (m *memoryLoginThrottleRepository) ClearLoginThrottle(ctx context.Context, scope string, subject string) error {
+	delete(m.throttles, [2]string{scope, subject})
+	return nil
+}
+
+func (m *memoryLoginThrottleRepository) GetLockedLogins(ctx context.Context) ([]domain.LoginThrottle, error) {
+	throttles := []domain.LoginThrottle{}
+	for _, throttle := range m.throttles {
+		if throttle.LockedAt != nil {
+			throttles = append(throttles, *throttle)
+		}
+	}
+	return throttles, nil
+}
+
+func (m *memoryLoginThrottleRepository) UnlockLogin(ctx context.Context, id int64) error {
+	for key, throttle := range m.throttles {
+		if throttle.ID == id {
+			delete(m.throttles, key)
+			return nil
+		}
+	}
+	return repository.ErrLoginThrottleNotFound
+}
+
+func newTestLoginGuard(t *testing.T) (*LoginGuard, *outbox, *time.Time) {
+	t.Helper()
+	useCheapParams(t)
+	users := newMemoryUserRepository()
+	if _, err := Register(context.Background(), users, "Ana", "ana@example.com", "uma senha bem longa"); err != nil {
+		t.Fatal(err)
+	}
+	mail := &outbox{}
+	guard := NewLoginGuard(&memoryLoginThrottleRepository{throttles: map[[2]string]*domain.LoginThrottle{}}, users, mail, LoginGuardOptions{
+		DelayAfter:    3,
+		BaseDelay:     2 * time.Second,
+		MaxFailures:   6,
+		IPMaxFailures: 10,
+		LockDuration:  15 * time.Minute,
+		FailureWindow: time.Hour,
+		BaseURL:       "http://lucienne.test/",
+	})
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time { return now }
+	return guard, mail, &now
+}
+
+// throttledFor retorna a espera exigida pelo guard, ou zero se a tentativa for aceita. Uma tentativa aceita é
+// desfeita, para não contar como falha.
+func throttledFor(t *testing.T, guard *LoginGuard, email string, ip string) (time.Duration, bool) {
+	t.Helper()
+	attempt, err := guard.Begin(context.Background(), email, ip)
+	if err == nil {
+		if err := attempt.Release(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return 0, false
+	}
+	var throttled *LoginThrottledError
+	if !errors.As(err, &throttled) {
+		t.Fatalf("Esperava um *LoginThrottledError, mas obteve %v", err)
+	}
+	return throttled.RetryAfter, throttled.Locked
+}
+
+// fail registra uma senha errada, que precisa ser aceita pelo guard.
+func fail(t *testing.T, guard *LoginGuard, email string, ip string) {
+	t.Helper()
+	attempt, err := guard.Begin(context.Background(), email, ip)
+	if err != nil {
+		t.Fatalf("Begin retornou um erro inesperado: %v", err)
+	}
+	attempt.Failed(context.Background())
+}
+
+// failAfterWaiting espera o atraso em vigor, se houver, antes de registrar a senha errada.
+func failAfterWaiting(t *testing.T, guard *LoginGuard, now *time.Time, email string, ip string) {
+	t.Helper()
+	if wait, _ := throttledFor(t, guard, email, ip); wait > 0 {
+		*now = now.Add(wait)
+	}
+	fail(t, guard, email, ip)
+}
+
+func TestLoginGuardProgressiveDelay(t *testing.T) {
+	guard, mail, now := newTestLoginGuard(t)
+
+	expected := []time.Duration{0, 0, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+	for i, delay := range expected {
+		fail(t, guard, "Ana@Example.com", "10.0.0.1")
+		wait, locked := throttledFor(t, guard, "ana@example.com", "10.0.0.2")
+		if wait != delay || locked {
+			t.Errorf("Falha %d: esperava o atraso de %s, mas obteve %s (bloqueio %v)", i+1, delay, wait, locked)
+		}
+		*now = now.Add(wait)
+	}
+
+	fail(t, guard, "ana@example.com", "10.0.0.1")
+	wait, locked := throttledFor(t, guard, "ana@example.com", "10.0.0.2")
+	if wait != 15*time.Minute || !locked {
+		t.Errorf("Esperava o bloqueio de 15 minutos, mas obteve %s (bloqueio %v)", wait, locked)
+	}
+	if len(mail.messages) != 1 || !strings.Contains(mail.messages[0].Text, "10.0.0.1") || !strings.Contains(mail.messages[0].Text, "http://lucienne.test/password/forgot") {
+		t.Fatalf("Esperava um aviso do bloqueio, mas obteve %+v", mail.messages)
+	}
+
+	*now = now.Add(15 * time.Minute)
+	if wait, _ := throttledFor(t, guard, "ana@example.com", "10.0.0.2"); wait != 0 {
+		t.Errorf("Esperava o fim do bloqueio, mas obteve a espera de %s", wait)
+	}
+	fail(t, guard, "ana@example.com", "10.0.0.1")
+	if _, locked := throttledFor(t, guard, "ana@example.com", "10.0.0.2"); !locked {
+		t.Error("Esperava um novo bloqueio na falha seguinte")
+	}
+	if len(mail.messages) != 1 {
+		t.Errorf("Esperava um único aviso na janela, mas obteve %d", len(mail.messages))
+	}
+}
+
+func TestLoginGuardConcurrentAttempts(t *testing.T) {
+	guard, _, _ := newTestLoginGuard(t)
+	ctx := context.Background()
+
+	// As tentativas são reservadas antes de a senha ser conferida, então as que chegam juntas já contam como
+	// falhas: a partir de DelayAfter, as seguintes são recusadas mesmo sem nenhuma falha confirmada.
+	var attempts []*LoginAttempt
+	for range 3 {
+		attempt, err := guard.Begin(ctx, "ana@example.com", "10.0.0.1")
+		if err != nil {
+			t.Fatalf("Begin retornou um erro inesperado: %v", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+	if wait, _ := throttledFor(t, guard, "ana@example.com", "10.0.0.2"); wait != 2*time.Second {
+		t.Errorf("Esperava a quarta tentativa simultânea recusada por 2s, mas obteve %s", wait)
+	}
+
+	for _, attempt := range attempts {
+		attempt.Failed(ctx)
+	}
+	if wait, _ := throttledFor(t, guard, "ana@example.com", "10.0.0.2"); wait != 2*time.Second {
+		t.Errorf("Esperava o atraso mantido depois das falhas, mas obteve %s", wait)
+	}
+}
+
+func TestLoginGuardSucceeded(t *testing.T) {
+	guard, _, now := newTestLoginGuard(t)
+	ctx := context.Background()
+
+	for range 3 {
+		fail(t, guard, "ana@example.com", "10.0.0.1")
+	}
+	*now = now.Add(2 * time.Second)
+	attempt, err := guard.Begin(ctx, "ANA@example.com", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Begin retornou um erro inesperado: %v", err)
+	}
+	if err := attempt.Succeeded(ctx); err != nil {
+		t.Fatalf("Succeeded retornou um erro inesperado: %v", err)
+	}
+	if wait, _ := throttledFor(t, guard, "ana@example.com", "10.0.0.2"); wait != 0 {
+		t.Errorf("Esperava as falhas da conta esquecidas, mas obteve a espera de %s", wait)
+	}
+	address, err := guard.repo.GetLoginThrottle(ctx, domain.LoginThrottleIP, "10.0.0.1")
+	if err != nil || address.Failures != 3 {
+		t.Errorf("Esperava as 3 falhas do IP mantidas depois do login, mas obteve %+v (%v)", address, err)
+	}
+}
+
+func TestLoginGuardRelease(t *testing.T) {
+	guard, _, now := newTestLoginGuard(t)
+	ctx := context.Background()
+
+	for range 2 {
+		fail(t, guard, "ana@example.com", "10.0.0.1")
+	}
+	// A senha certa de um login que aguarda o código não conta como falha, nem atrasa o código.
+	attempt, err := guard.Begin(ctx, "ana@example.com", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := attempt.Release(ctx); err != nil {
+		t.Fatalf("Release retornou um erro inesperado: %v", err)
+	}
+	if wait, _ := throttledFor(t, guard, "ana@example.com", "10.0.0.1"); wait != 0 {
+		t.Errorf("Esperava a tentativa desfeita sem atraso, mas obteve a espera de %s", wait)
+	}
+	fail(t, guard, "ana@example.com", "10.0.0.1")
+	if wait, _ := throttledFor(t, guard, "ana@example.com", "10.0.0.2"); wait != 2*time.Second {
+		t.Errorf("Esperava as falhas anteriores mantidas, mas obteve a espera de %s", wait)
+	}
+	*now = now.Add(2 * time.Second)
+}
+
+func TestLoginGuardFailureWindow(t *testing.T) {
+	guard, _, now := newTestLoginGuard(t)
+
+	for range 2 {
+		fail(t, guard, "ana@example.com", "10.0.0.1")
+	}
+	*now = now.Add(2 * time.Hour)
+	fail(t, guard, "ana@example.com", "10.0.0.1")
+	if wait, _ := throttledFor(t, guard, "ana@example.com", "10.0.0.1"); wait != 0 {
+		t.Errorf("Esperava a contagem recomeçada depois da janela, mas obteve a espera de %s", wait)
+	}
+}
+
+func TestLoginGuardIPProgressiveDelay(t *testing.T) {
+	guard, _, now := newTestLoginGuard(t)
+
+	// Cada falha é de uma conta diferente, então só o IP acumula falhas.
+	expected := []time.Duration{0, 0, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+	for i, delay := range expected {
+		fail(t, guard, "conta"+string(rune('a'+i))+"@example.com", "10.0.0.1")
+		wait, locked := throttledFor(t, guard, "outra@example.com", "10.0.0.1")
+		if wait != delay || locked {
+			t.Errorf("Falha %d: esperava o atraso de %s para o IP, mas obteve %s (bloqueio %v)", i+1, delay, wait, locked)
+		}
+		*now = now.Add(wait)
+	}
+
+	if wait, _ := throttledFor(t, guard, "outra@example.com", "10.0.0.2"); wait != 0 {
+		t.Errorf("Esperava outro IP liberado, mas obteve a espera de %s", wait)
+	}
+}
+
+func TestLoginGuardIPLockout(t *testing.T) {
+	guard, mail, now := newTestLoginGuard(t)
+	ctx := context.Background()
+
+	// Uma senha por conta, como em um ataque que testa muitas contas, não atrasa nenhuma delas, só o IP.
+	for i := range 10 {
+		failAfterWaiting(t, guard, now, "conta"+string(rune('a'+i))+"@example.com", "10.0.0.1")
+	}
+
+	wait, locked := throttledFor(t, guard, "ana@example.com", "10.0.0.1")
+	if wait != 15*time.Minute || !locked {
+		t.Errorf("Esperava o IP bloqueado, mas obteve %s (bloqueio %v)", wait, locked)
+	}
+	if wait, _ := throttledFor(t, guard, "ana@example.com", "10.0.0.2"); wait != 0 {
+		t.Errorf("Esperava outro IP liberado, mas obteve a espera de %s", wait)
+	}
+	if len(mail.messages) != 0 {
+		t.Errorf("Não esperava avisos por email, mas obteve %d", len(mail.messages))
+	}
+
+	locks, err := guard.Locked(ctx)
+	if err != nil || len(locks) != 1 || locks[0].Scope != domain.LoginThrottleIP {
+		t.Fatalf("Esperava o IP na lista de bloqueios, mas obteve %+v (%v)", locks, err)
+	}
+	if err := guard.Unlock(ctx, &domain.User{ID: 1}, locks[0].ID); err != nil {
+		t.Fatalf("Unlock retornou um erro inesperado: %v", err)
+	}
+	if wait, _ := throttledFor(t, guard, "ana@example.com", "10.0.0.1"); wait != 0 {
+		t.Errorf("Esperava o IP desbloqueado, mas obteve a espera de %s", wait)
+	}
+}
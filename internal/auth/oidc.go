@@ -0,0 +1,363 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// oidcFlowLifetime é o prazo para o usuário voltar do provedor de identidade depois de iniciar o login.
+const oidcFlowLifetime = 10 * time.Minute
+
+var (
+	// ErrOIDCLogin é retornado quando o login único não pode ser concluído: o fluxo expirou ou foi adulterado,
+	// o usuário recusou o acesso no provedor ou o ID token é inválido.
+	ErrOIDCLogin = errors.New("login único recusado")
+
+	// ErrOIDCEmailNotVerified é retornado ao criar um usuário cujo email não foi confirmado pelo provedor.
+	ErrOIDCEmailNotVerified = errors.New("o provedor de identidade não confirmou o email")
+)
+
+// OIDCOptions configura o login único com um provedor OpenID Connect.
+type OIDCOptions struct {
+	// Name é o nome do provedor exibido no botão de login.
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL é o endereço de /login/oidc/callback registrado no provedor.
+	RedirectURL string
+	Scopes      []string
+	// RolesClaim é a claim do ID token com os grupos do usuário no provedor.
+	RolesClaim string
+	// RoleMapping associa os valores de RolesClaim aos papéis da aplicação. Quando definido, os papéis dos
+	// usuários do login único são refeitos a partir dele a cada login.
+	RoleMapping map[string]string
+	// Secure envia o cookie do fluxo apenas por HTTPS, com o prefixo __Host-.
+	Secure bool
+}
+
+// OIDCProvider conduz o login pelo fluxo authorization code com PKCE e cria os usuários no primeiro login.
+type OIDCProvider struct {
+	options    OIDCOptions
+	oauth      oauth2.Config
+	verifier   *oidc.IDTokenVerifier
+	users      repository.UserRepository
+	identities repository.IdentityRepository
+	roles      repository.RoleRepository
+	cookieName string
+}
+
+// oidcFlow é guardado em um cookie entre o início do login e a volta do provedor.
+type oidcFlow struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Next     string `json:"next"`
+}
+
+// oidcClaims são as claims do ID token usadas para criar o usuário.
+type oidcClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// NewOIDCProvider busca a configuração do provedor no endereço de descoberta do emissor.
+func NewOIDCProvider(ctx context.Context, options OIDCOptions, users repository.UserRepository, identities repository.IdentityRepository, roles repository.RoleRepository) (*OIDCProvider, error) {
+	if options.ClientID == "" || options.RedirectURL == "" {
+		return nil, errors.New("o client ID e a URL de retorno são obrigatórios para o login único")
+	}
+	if err := checkRoleMapping(ctx, roles, options.RoleMapping); err != nil {
+		return nil, err
+	}
+
+	provider, err := oidc.NewProvider(ctx, options.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar o provedor de identidade: %w", err)
+	}
+
+	scopes := options.Scopes
+	if !slices.Contains(scopes, oidc.ScopeOpenID) {
+		scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+	}
+	cookieName := "oidc"
+	if options.Secure {
+		cookieName = "__Host-oidc"
+	}
+
+	return &OIDCProvider{
+		options: options,
+		oauth: oauth2.Config{
+			ClientID:     options.ClientID,
+			ClientSecret: options.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  options.RedirectURL,
+			Scopes:       scopes,
+		},
+		verifier:   provider.Verifier(&oidc.Config{ClientID: options.ClientID}),
+		users:      users,
+		identities: identities,
+		roles:      roles,
+		cookieName: cookieName,
+	}, nil
+}
+
+// ParseRoleMapping lê o mapeamento de grupos do provedor para papéis, no formato "grupo=papel".
+// Sem entradas, retorna nil, e os papéis ficam a cargo dos administradores.
+func ParseRoleMapping(entries []string) (map[string]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	mapping := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		group, role, ok := strings.Cut(entry, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("mapeamento de papel inválido %q: use grupo=papel", entry)
+		}
+		mapping[group] = role
+	}
+	return mapping, nil
+}
+
+// checkRoleMapping confere se os papéis do mapeamento existem, para que um papel digitado errado impeça a
+// aplicação de iniciar em vez de falhar no login de cada usuário do grupo.
+func checkRoleMapping(ctx context.Context, roles repository.RoleRepository, mapping map[string]string) error {
+	if len(mapping) == 0 {
+		return nil
+	}
+	existing, err := roles.GetRoles(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar os papéis do mapeamento: %w", err)
+	}
+	names := make(map[string]bool, len(existing))
+	for _, role := range existing {
+		names[role.Name] = true
+	}
+	for _, group := range slices.Sorted(maps.Keys(mapping)) {
+		if !names[mapping[group]] {
+			return fmt.Errorf("o papel %q, mapeado para o grupo %q, não existe", mapping[group], group)
+		}
+	}
+	return nil
+}
+
+// Name retorna o nome do provedor exibido no botão de login.
+func (p *OIDCProvider) Name() string {
+	return p.options.Name
+}
+
+// BeginLogin guarda o state, o nonce e o code verifier do PKCE em um cookie e retorna o endereço
+// de autorização do provedor, para onde o usuário deve ser redirecionado.
+func (p *OIDCProvider) BeginLogin(w http.ResponseWriter, next string) string {
+	flow := oidcFlow{
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: oauth2.GenerateVerifier(),
+		Next:     next,
+	}
+	value, _ := json.Marshal(flow)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     p.cookieName,
+		Value:    base64.RawURLEncoding.EncodeToString(value),
+		Path:     "/",
+		MaxAge:   int(oidcFlowLifetime.Seconds()),
+		Secure:   p.options.Secure,
+		HttpOnly: true,
+		// Lax para que o cookie acompanhe o redirecionamento de volta do provedor.
+		SameSite: http.SameSiteLaxMode,
+	})
+	return p.oauth.AuthCodeURL(flow.State, oidc.Nonce(flow.Nonce), oauth2.S256ChallengeOption(flow.Verifier))
+}
+
+// CompleteLogin confere a volta do provedor, troca o código pelo ID token e retorna o usuário ligado
+// à conta do provedor, criando-o no primeiro login, e a página pedida antes do login.
+func (p *OIDCProvider) CompleteLogin(w http.ResponseWriter, r *http.Request) (*domain.User, string, error) {
+	flow, ok := p.readFlow(r)
+	p.clearCookie(w)
+	query := r.URL.Query()
+	if !ok || subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(flow.State)) != 1 {
+		return nil, "", fmt.Errorf("%w: state ausente ou diferente do enviado", ErrOIDCLogin)
+	}
+	if e := query.Get("error"); e != "" {
+		return nil, "", fmt.Errorf("%w: %s %s", ErrOIDCLogin, e, query.Get("error_description"))
+	}
+
+	ctx := r.Context()
+	token, err := p.oauth.Exchange(ctx, query.Get("code"), oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			return nil, "", fmt.Errorf("%w: %v", ErrOIDCLogin, err)
+		}
+		return nil, "", err
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: a resposta do provedor não tem ID token", ErrOIDCLogin)
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrOIDCLogin, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(flow.Nonce)) != 1 {
+		return nil, "", fmt.Errorf("%w: nonce diferente do enviado", ErrOIDCLogin)
+	}
+
+	var claims oidcClaims
+	var allClaims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrOIDCLogin, err)
+	}
+	if err := idToken.Claims(&allClaims); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrOIDCLogin, err)
+	}
+
+	user, err := p.provision(ctx, idToken.Subject, claims, claimValues(allClaims[p.options.RolesClaim]))
+	if err != nil {
+		return nil, "", err
+	}
+	return user, flow.Next, nil
+}
+
+// provision retorna o usuário ligado à conta do provedor. No primeiro login a conta é ligada ao usuário
+// com o mesmo email, se houver, ou a um novo usuário sem senha (just-in-time provisioning).
+func (p *OIDCProvider) provision(ctx context.Context, subject string, claims oidcClaims, groups []string) (*domain.User, error) {
+	user, err := p.identities.GetUserByIdentity(ctx, p.options.Issuer, subject)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		user, err = p.linkUser(ctx, subject, claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if p.options.RoleMapping == nil {
+		return user, nil
+	}
+	roles := mappedRoles(p.options.RoleMapping, groups)
+	if slices.Equal(roles, user.Roles) {
+		return user, nil
+	}
+	if err := p.roles.SetUserRoles(ctx, user.ID, roles); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "papéis do usuário atualizados pelo provedor de identidade", "user_id", user.ID, "roles", roles)
+	return p.users.GetUserByID(ctx, user.ID)
+}
+
+// linkUser liga a conta do provedor ao usuário do email. O email só é usado se o provedor o confirmou,
+// para que uma conta do provedor não tome o usuário de outra pessoa.
+func (p *OIDCProvider) linkUser(ctx context.Context, subject string, claims oidcClaims) (*domain.User, error) {
+	if !claims.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+	email, err := NormalizeEmail(claims.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := p.users.GetUserByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		user = &domain.User{Email: email, Name: displayName(claims, email)}
+		err = p.users.CreateUser(ctx, user)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.identities.LinkIdentity(ctx, user.ID, p.options.Issuer, subject); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "conta do provedor de identidade ligada ao usuário", "user_id", user.ID, "issuer", p.options.Issuer)
+	return user, nil
+}
+
+func (p *OIDCProvider) readFlow(r *http.Request) (oidcFlow, bool) {
+	var flow oidcFlow
+	cookie, err := r.Cookie(p.cookieName)
+	if err != nil {
+		return flow, false
+	}
+	value, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || json.Unmarshal(value, &flow) != nil || flow.State == "" {
+		return flow, false
+	}
+	return flow, true
+}
+
+func (p *OIDCProvider) clearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     p.cookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   p.options.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// mappedRoles retorna, em ordem alfabética como os papéis carregados do banco, o papel member e os papéis
+// associados aos grupos do usuário.
+func mappedRoles(mapping map[string]string, groups []string) []string {
+	roles := []string{RoleMember}
+	for _, group := range groups {
+		if role, ok := mapping[group]; ok && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	slices.Sort(roles)
+	return roles
+}
+
+// claimValues aceita a claim de grupos como uma lista ou como um único texto.
+func claimValues(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// displayName usa o nome informado pelo provedor ou, sem ele, o nome de usuário ou o início do email.
+func displayName(claims oidcClaims, email string) string {
+	for _, name := range []string{claims.Name, claims.PreferredUsername} {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+	name, _, _ := strings.Cut(email, "@")
+	return name
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/test/test_support"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// memoryIdentityRepository liga as contas do provedor aos usuários do memoryUserRepository.
+type memoryIdentityRepository struct {
+	users      *memoryUserRepository
+	identities map[string]int64
+}
+
+func (m *memoryIdentityRepository) GetUserByIdentity(ctx context.Context, issuer string, subject string) (*domain.User, error) {
+	id, ok := m.identities[issuer+" "+subject]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return m.users.GetUserByID(ctx, id)
+}
+
+func (m *memoryIdentityRepository) LinkIdentity(ctx context.Context, userID int64, issuer string, subject string) error {
+	if _, ok := m.identities[issuer+" "+subject]; ok {
+		return repository.ErrIdentityAlreadyLinked
+	}
+	m.identities[issuer+" "+subject] = userID
+	return nil
+}
+
+// memoryRoleRepository altera os papéis dos usuários do memoryUserRepository.
+type memoryRoleRepository struct {
+	users *memoryUserRepository
+}
+
+func (m *memoryRoleRepository) GetRoles(ctx context.Context) ([]domain.Role, error) {
+	return []domain.Role{{Name: RoleMember}, {Name: RoleLibrarian}, {Name: RoleAdmin}}, nil
+}
+
+func (m *memoryRoleRepository) SetUserRoles(ctx context.Context, userID int64, roles []string) error {
+	for _, user := range m.users.users {
+		if user.ID == userID {
+			user.Roles = roles
+			return nil
+		}
+	}
+	return repository.ErrUserNotFound
+}
+
+func newTestOIDCProvider(t *testing.T, server *test_support.OIDCServer, roleMapping map[string]string) (*OIDCProvider, *memoryUserRepository) {
+	t.Helper()
+	users := newMemoryUserRepository()
+	provider, err := NewOIDCProvider(context.Background(), OIDCOptions{
+		Name:         "Escola",
+		Issuer:       server.URL,
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		RedirectURL:  "http://lucienne.test/login/oidc/callback",
+		Scopes:       []string{"profile", "email"},
+		RolesClaim:   "groups",
+		RoleMapping:  roleMapping,
+	}, users, &memoryIdentityRepository{users: users, identities: map[string]int64{}}, &memoryRoleRepository{users: users})
+	if err != nil {
+		t.Fatalf("NewOIDCProvider retornou um erro inesperado: %v", err)
+	}
+	return provider, users
+}
+
+// oidcLogin percorre o fluxo como o navegador: inicia o login, segue para o provedor, que aprova a
+// autorização, e volta ao callback com o cookie do fluxo.
+func oidcLogin(t *testing.T, provider *OIDCProvider, tamper func(callback *http.Request)) (*domain.User, string, error) {
+	t.Helper()
+	start := httptest.NewRecorder()
+	authURL := provider.BeginLogin(start, "/authors")
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("o provedor recusou a autorização: status %d", res.StatusCode)
+	}
+
+	callback := httptest.NewRequest("GET", res.Header.Get("Location"), nil)
+	for _, cookie := range start.Result().Cookies() {
+		callback.AddCookie(cookie)
+	}
+	if tamper != nil {
+		tamper(callback)
+	}
+	return provider.CompleteLogin(httptest.NewRecorder(), callback)
+}
+
+func TestOIDCLogin(t *testing.T) {
+	server := test_support.NewOIDCServer(t)
+
+	t.Run("deve criar o usuário sem senha no primeiro login e reconhecê-lo depois", func(t *testing.T) {
+		provider, users := newTestOIDCProvider(t, server, nil)
+		server.SetClaims(map[string]any{"sub": "ana-123", "email": "ana@escola.edu", "email_verified": true, "name": "Ana"})
+
+		user, next, err := oidcLogin(t, provider, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin retornou um erro inesperado: %v", err)
+		}
+		if user.Email != "ana@escola.edu" || user.Name != "Ana" || user.HasPassword() || next != "/authors" {
+			t.Errorf("Usuário inesperado: %+v, next %q", user, next)
+		}
+
+		// A conta continua ligada ao mesmo usuário mesmo que o email mude no provedor.
+		server.SetClaims(map[string]any{"sub": "ana-123", "email": "ana.souza@escola.edu", "email_verified": true})
+		again, _, err := oidcLogin(t, provider, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin retornou um erro inesperado: %v", err)
+		}
+		if again.ID != user.ID || len(users.users) != 1 {
+			t.Errorf("Esperava o mesmo usuário %d, mas obtive %+v", user.ID, again)
+		}
+	})
+
+	t.Run("deve ligar a conta ao usuário com o mesmo email confirmado", func(t *testing.T) {
+		provider, users := newTestOIDCProvider(t, server, nil)
+		existing := &domain.User{Email: "Bia@escola.edu", Name: "Bia", PasswordHash: "hash"}
+		users.CreateUser(context.Background(), existing)
+		server.SetClaims(map[string]any{"sub": "bia-456", "email": "bia@escola.edu", "email_verified": true})
+
+		user, _, err := oidcLogin(t, provider, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin retornou um erro inesperado: %v", err)
+		}
+		if user.ID != existing.ID {
+			t.Errorf("Esperava o usuário %d, mas obtive %d", existing.ID, user.ID)
+		}
+	})
+
+	t.Run("deve recusar um email não confirmado", func(t *testing.T) {
+		provider, users := newTestOIDCProvider(t, server, nil)
+		server.SetClaims(map[string]any{"sub": "caio-789", "email": "caio@escola.edu", "email_verified": false})
+
+		if _, _, err := oidcLogin(t, provider, nil); !errors.Is(err, ErrOIDCEmailNotVerified) {
+			t.Errorf("Esperava ErrOIDCEmailNotVerified, mas obtive %v", err)
+		}
+		if len(users.users) != 0 {
+			t.Errorf("Nenhum usuário deveria ter sido criado: %v", users.users)
+		}
+	})
+
+	t.Run("deve recusar um state diferente do enviado", func(t *testing.T) {
+		provider, _ := newTestOIDCProvider(t, server, nil)
+		server.SetClaims(map[string]any{"sub": "ana-123", "email": "ana@escola.edu", "email_verified": true})
+
+		_, _, err := oidcLogin(t, provider, func(callback *http.Request) {
+			query := callback.URL.Query()
+			query.Set("state", "forjado")
+			callback.URL.RawQuery = query.Encode()
+		})
+		if !errors.Is(err, ErrOIDCLogin) {
+			t.Errorf("Esperava ErrOIDCLogin, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve recusar a volta sem o cookie do fluxo", func(t *testing.T) {
+		provider, _ := newTestOIDCProvider(t, server, nil)
+		server.SetClaims(map[string]any{"sub": "ana-123", "email": "ana@escola.edu", "email_verified": true})
+
+		_, _, err := oidcLogin(t, provider, func(callback *http.Request) { callback.Header.Del("Cookie") })
+		if !errors.Is(err, ErrOIDCLogin) {
+			t.Errorf("Esperava ErrOIDCLogin, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve aplicar os papéis mapeados dos grupos", func(t *testing.T) {
+		provider, _ := newTestOIDCProvider(t, server, map[string]string{"bibliotecarios": RoleLibrarian, "ti": RoleAdmin})
+		server.SetClaims(map[string]any{"sub": "duda-1", "email": "duda@escola.edu", "email_verified": true, "groups": []string{"bibliotecarios", "alunos"}})
+
+		user, _, err := oidcLogin(t, provider, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin retornou um erro inesperado: %v", err)
+		}
+		expected := []string{RoleLibrarian, RoleMember}
+		if !slices.Equal(user.Roles, expected) {
+			t.Errorf("Esperava os papéis %v, mas obtive %v", expected, user.Roles)
+		}
+
+		// Ao sair do grupo no provedor, o papel é retirado no próximo login.
+		server.SetClaims(map[string]any{"sub": "duda-1", "email": "duda@escola.edu", "email_verified": true, "groups": "alunos"})
+		user, _, err = oidcLogin(t, provider, nil)
+		if err != nil {
+			t.Fatalf("CompleteLogin retornou um erro inesperado: %v", err)
+		}
+		if !slices.Equal(user.Roles, []string{RoleMember}) {
+			t.Errorf("Esperava apenas o papel member, mas obtive %v", user.Roles)
+		}
+	})
+}
+
+func TestParseRoleMapping(t *testing.T) {
+	mapping, err := ParseRoleMapping([]string{"bibliotecarios=librarian", " ti = admin "})
+	if err != nil {
+		t.Fatalf("ParseRoleMapping retornou um erro inesperado: %v", err)
+	}
+	if mapping["bibliotecarios"] != RoleLibrarian || mapping["ti"] != RoleAdmin {
+		t.Errorf("Mapeamento inesperado: %v", mapping)
+	}
+
+	if _, err := ParseRoleMapping([]string{"bibliotecarios"}); err == nil {
+		t.Error("Esperava um erro para uma entrada sem papel")
+	}
+	if mapping, _ := ParseRoleMapping(nil); mapping != nil {
+		t.Errorf("Esperava nil sem entradas, mas obtive %v", mapping)
+	}
+}
+
+func TestNewOIDCProviderUnknownMappedRole(t *testing.T) {
+	users := newMemoryUserRepository()
+	_, err := NewOIDCProvider(context.Background(), OIDCOptions{
+		Issuer:      "http://lucienne.test/idp",
+		ClientID:    "lucienne",
+		RedirectURL: "http://lucienne.test/login/oidc/callback",
+		RoleMapping: map[string]string{"bibliotecarios": "libraian", "ti": RoleAdmin},
+	}, users, &memoryIdentityRepository{users: users, identities: map[string]int64{}}, &memoryRoleRepository{users: users})
+	if err == nil || err.Error() != `o papel "libraian", mapeado para o grupo "bibliotecarios", não existe` {
+		t.Errorf("Esperava um erro para o papel inexistente, mas obtive %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package auth
+
+import "context"
+
+// Papéis criados pela migração de papéis e permissões.
+const (
+	RoleMember    = "member"
+	RoleLibrarian = "librarian"
+	RoleAdmin     = "admin"
+)
+
+// Permissões verificadas pela aplicação. Cada papel concede um conjunto delas, guardado no banco.
+const (
+	PermissionManageAuthors    = "authors.manage"
+	PermissionManagePublishers = "publishers.manage"
+	PermissionManageBooks      = "books.manage"
+	PermissionManageUsers      = "users.manage"
+	PermissionManageSettings   = "settings.manage"
+	PermissionViewAudit        = "audit.view"
+)
+
+// Can informa se o usuário autenticado na requisição tem a permissão. Visitantes anônimos não têm nenhuma.
+func Can(ctx context.Context, permission string) bool {
+	user := CurrentUser(ctx)
+	return user != nil && user.Can(permission)
+}
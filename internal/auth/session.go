@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"net/http"
+	"time"
+)
+
+const sessionTokenLength = 32
+
+// touchInterval evita uma escrita no banco a cada requisição: o último uso da sessão só é atualizado
+// quando o registrado é mais antigo que isso.
+const touchInterval = time.Minute
+
+// SessionOptions configura a duração e o cookie das sessões.
+type SessionOptions struct {
+	// IdleTimeout encerra a sessão que não é usada há esse tempo.
+	IdleTimeout time.Duration
+	// Lifetime encerra a sessão esse tempo depois do login, mesmo que continue em uso.
+	Lifetime time.Duration
+	// Secure envia o cookie apenas por HTTPS, com o prefixo __Host-.
+	Secure bool
+}
+
+// SessionManager cria, carrega e encerra as sessões de login guardadas no banco.
+type SessionManager struct {
+	sessions   repository.SessionRepository
+	users      repository.UserRepository
+	options    SessionOptions
+	cookieName string
+	now        func() time.Time
+}
+
+// NewSessionManager cria um SessionManager com suas dependências.
+func NewSessionManager(sessions repository.SessionRepository, users repository.UserRepository, options SessionOptions) *SessionManager {
+	cookieName := "session"
+	if options.Secure {
+		cookieName = "__Host-session"
+	}
+	return &SessionManager{sessions: sessions, users: users, options: options, cookieName: cookieName, now: time.Now}
+}
+
+// Login inicia uma sessão para o usuário. A sessão anterior do navegador, se houver, é removida, de modo que
+// um token conhecido antes do login (session fixation) não passe a valer como autenticado.
+func (m *SessionManager) Login(w http.ResponseWriter, r *http.Request, user *domain.User) error {
+	if err := m.deleteCurrent(r); err != nil {
+		return err
+	}
+
+	token := make([]byte, sessionTokenLength)
+	rand.Read(token)
+	now := m.now()
+	session := &domain.Session{
+		ID:         sessionID(token),
+		UserID:     user.ID,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(m.options.Lifetime),
+	}
+	if err := m.sessions.CreateSession(r.Context(), session); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.cookieName,
+		Value:    base64.RawURLEncoding.EncodeToString(token),
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		Secure:   m.options.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// Logout encerra a sessão do navegador e apaga o cookie.
+func (m *SessionManager) Logout(w http.ResponseWriter, r *http.Request) error {
+	m.clearCookie(w)
+	return m.deleteCurrent(r)
+}
+
+// Middleware carrega o usuário da sessão do cookie no contexto da requisição, disponível por CurrentUser.
+// Sessões expiradas são removidas e a requisição segue como anônima.
+func (m *SessionManager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := m.cookieSessionID(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := m.load(r.Context(), id)
+		if err != nil {
+			if !errors.Is(err, repository.ErrSessionNotFound) {
+				slog.ErrorContext(r.Context(), "erro ao carregar a sessão", "error", err)
+			}
+			m.clearCookie(w)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+	})
+}
+
+// load retorna o usuário da sessão, que é removida se tiver expirado por inatividade ou pelo prazo absoluto.
+func (m *SessionManager) load(ctx context.Context, id string) (*domain.User, error) {
+	session, err := m.sessions.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	if !now.Before(session.ExpiresAt) || now.Sub(session.LastSeenAt) > m.options.IdleTimeout {
+		if err := m.sessions.DeleteSession(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, repository.ErrSessionNotFound
+	}
+
+	user, err := m.users.GetUserByID(ctx, session.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, repository.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if now.Sub(session.LastSeenAt) > touchInterval {
+		if err := m.sessions.TouchSession(ctx, id, now); err != nil {
+			slog.WarnContext(ctx, "erro ao atualizar o último uso da sessão", "error", err)
+		}
+	}
+	return user, nil
+}
+
+func (m *SessionManager) deleteCurrent(r *http.Request) error {
+	id, ok := m.cookieSessionID(r)
+	if !ok {
+		return nil
+	}
+	return m.sessions.DeleteSession(r.Context(), id)
+}
+
+func (m *SessionManager) cookieSessionID(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(m.cookieName)
+	if err != nil {
+		return "", false
+	}
+	token, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || len(token) != sessionTokenLength {
+		return "", false
+	}
+	return sessionID(token), true
+}
+
+func (m *SessionManager) clearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.cookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   m.options.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sessionID é o hash do token, que é o que fica guardado no banco.
+func sessionID(token []byte) string {
+	sum := sha256.Sum256(token)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// memorySessionRepository guarda as sessões em memória.
+type memorySessionRepository struct {
+	sessions map[string]domain.Session
+}
+
+func (m *memorySessionRepository) CreateSession(ctx context.Context, session *domain.Session) error {
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+func (m *memorySessionRepository) GetSession(ctx context.Context, id string) (*domain.Session, error) {
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, repository.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (m *memorySessionRepository) TouchSession(ctx context.Context, id string, lastSeenAt time.Time) error {
+	session := m.sessions[id]
+	session.LastSeenAt = lastSeenAt
+	m.sessions[id] = session
+	return nil
+}
+
+func (m *memorySessionRepository) DeleteSession(ctx context.Context, id string) error {
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *memorySessionRepository) DeleteUserSessions(ctx context.Context, userID int64) error {
+	for id, session := range m.sessions {
+		if session.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+func newTestSessionManager(t *testing.T) (*SessionManager, *memorySessionRepository, *time.Time) {
+	t.Helper()
+	useCheapParams(t)
+	users := newMemoryUserRepository()
+	if _, err := Register(context.Background(), users, "Ana", "ana@example.com", "uma senha bem longa"); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := &memorySessionRepository{sessions: map[string]domain.Session{}}
+	manager := NewSessionManager(sessions, users, SessionOptions{IdleTimeout: 30 * time.Minute, Lifetime: 12 * time.Hour, Secure: true})
+	now := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+	return manager, sessions, &now
+}
+
+// login inicia uma sessão e retorna o cookie enviado ao navegador.
+func login(t *testing.T, manager *SessionManager, cookies ...*http.Cookie) *http.Cookie {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/login", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rr := httptest.NewRecorder()
+	if err := manager.Login(rr, req, &domain.User{ID: 1}); err != nil {
+		t.Fatalf("erro ao iniciar a sessão: %v", err)
+	}
+	result := rr.Result().Cookies()
+	if len(result) != 1 {
+		t.Fatalf("esperava um cookie de sessão, obteve %v", result)
+	}
+	return result[0]
+}
+
+// currentUser executa o middleware com o cookie e retorna o usuário carregado.
+func currentUser(manager *SessionManager, cookie *http.Cookie) *domain.User {
+	var user *domain.User
+	req := httptest.NewRequest("GET", "/profile", nil)
+	req.AddCookie(cookie)
+	manager.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = CurrentUser(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	return user
+}
+
+func TestSessionLogin(t *testing.T) {
+	manager, sessions, _ := newTestSessionManager(t)
+
+	cookie := login(t, manager)
+	if cookie.Name != "__Host-session" || !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie de sessão sem os atributos de segurança: %+v", cookie)
+	}
+	if _, ok := sessions.sessions[cookie.Value]; ok {
+		t.Error("o token do cookie não deveria ser guardado no banco, apenas o hash")
+	}
+	if user := currentUser(manager, cookie); user == nil || user.Email != "ana@example.com" {
+		t.Errorf("esperava o usuário da sessão, obteve %+v", user)
+	}
+
+	t.Run("deve trocar a sessão a cada login", func(t *testing.T) {
+		rotated := login(t, manager, cookie)
+		if rotated.Value == cookie.Value {
+			t.Fatal("o login deveria gerar um novo token")
+		}
+		if currentUser(manager, cookie) != nil {
+			t.Error("a sessão anterior ao login deveria ter sido removida")
+		}
+		if currentUser(manager, rotated) == nil {
+			t.Error("a nova sessão deveria ser válida")
+		}
+	})
+
+	t.Run("deve ignorar um cookie desconhecido", func(t *testing.T) {
+		forged := &http.Cookie{Name: "__Host-session", Value: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}
+		if currentUser(manager, forged) != nil {
+			t.Error("um token inexistente não deveria autenticar")
+		}
+	})
+}
+
+func TestSessionExpiry(t *testing.T) {
+	t.Run("deve expirar por inatividade", func(t *testing.T) {
+		manager, sessions, now := newTestSessionManager(t)
+		cookie := login(t, manager)
+
+		*now = now.Add(31 * time.Minute)
+		if currentUser(manager, cookie) != nil {
+			t.Error("a sessão parada além do tempo de inatividade não deveria autenticar")
+		}
+		if len(sessions.sessions) != 0 {
+			t.Error("a sessão expirada deveria ter sido removida")
+		}
+	})
+
+	t.Run("deve renovar o prazo de inatividade a cada uso", func(t *testing.T) {
+		manager, _, now := newTestSessionManager(t)
+		cookie := login(t, manager)
+
+		for range 4 {
+			*now = now.Add(20 * time.Minute)
+			if currentUser(manager, cookie) == nil {
+				t.Fatal("a sessão em uso não deveria expirar por inatividade")
+			}
+		}
+	})
+
+	t.Run("deve expirar pelo prazo absoluto mesmo em uso", func(t *testing.T) {
+		manager, _, now := newTestSessionManager(t)
+		cookie := login(t, manager)
+
+		for elapsed := time.Duration(0); elapsed < 12*time.Hour; elapsed += 20 * time.Minute {
+			*now = now.Add(20 * time.Minute)
+			currentUser(manager, cookie)
+		}
+		if currentUser(manager, cookie) != nil {
+			t.Error("a sessão não deveria durar além do prazo absoluto")
+		}
+	})
+}
+
+func TestSessionLogout(t *testing.T) {
+	manager, sessions, _ := newTestSessionManager(t)
+	cookie := login(t, manager)
+
+	req := httptest.NewRequest("POST", "/logout", nil)
+	req.AddCookie(cookie)
+	rr := httptest.NewRecorder()
+	if err := manager.Logout(rr, req); err != nil {
+		t.Fatalf("erro ao sair: %v", err)
+	}
+
+	if len(sessions.sessions) != 0 {
+		t.Error("a sessão deveria ter sido removida")
+	}
+	if cleared := rr.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Errorf("esperava o cookie de sessão apagado, obteve %v", cleared)
+	}
+}
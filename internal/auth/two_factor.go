@@ -0,0 +1,351 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/totp"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// recoveryCodeCount é a quantidade de códigos de recuperação gerados de cada vez.
+	recoveryCodeCount = 10
+	// recoveryCodeLength é o tamanho de cada código, sem o hífen: 50 bits em base32.
+	recoveryCodeLength = 10
+	// totpSkew aceita o código do intervalo anterior e do seguinte, pela diferença de relógio do celular.
+	totpSkew = 1
+)
+
+var (
+	// ErrInvalidTwoFactorCode é retornado quando o código do autenticador ou de recuperação não confere.
+	ErrInvalidTwoFactorCode = errors.New("código de verificação inválido")
+	// ErrTwoFactorRequired é retornado ao tentar desativar a verificação em duas etapas exigida pelo papel do usuário.
+	ErrTwoFactorRequired = errors.New("verificação em duas etapas exigida pelo papel do usuário")
+	// ErrTwoFactorLoginExpired é retornado quando não há um login aguardando o código, ou ele expirou.
+	ErrTwoFactorLoginExpired = errors.New("login aguardando o código expirado")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorOptions configura a verificação em duas etapas.
+type TwoFactorOptions struct {
+	// Issuer é o nome da conta exibido no aplicativo autenticador.
+	Issuer string
+	// RequiredRoles são os papéis cujos usuários precisam ativar a verificação em duas etapas.
+	RequiredRoles []string
+	// LoginTTL é o prazo para informar o código depois de conferida a senha.
+	LoginTTL time.Duration
+	// Secure envia o cookie do login pendente apenas por HTTPS, com o prefixo __Host-.
+	Secure bool
+}
+
+// TwoFactorEnrollment é o que o usuário precisa para cadastrar a conta no aplicativo autenticador.
+type TwoFactorEnrollment struct {
+	Secret string
+	// URI é o endereço otpauth:// exibido como QR code.
+	URI string
+}
+
+// TwoFactor ativa e confere a verificação em duas etapas (TOTP) dos usuários que entram com senha.
+type TwoFactor struct {
+	repo       repository.TwoFactorRepository
+	tokens     repository.UserTokenRepository
+	users      repository.UserRepository
+	options    TwoFactorOptions
+	cookieName string
+	now        func() time.Time
+}
+
+// NewTwoFactor cria um TwoFactor com suas dependências.
+func NewTwoFactor(repo repository.TwoFactorRepository, tokens repository.UserTokenRepository, users repository.UserRepository, options TwoFactorOptions) *TwoFactor {
+	cookieName := "two_factor"
+	if options.Secure {
+		cookieName = "__Host-two_factor"
+	}
+	return &TwoFactor{repo: repo, tokens: tokens, users: users, options: options, cookieName: cookieName, now: time.Now}
+}
+
+// Required informa se o papel do usuário exige a verificação em duas etapas. Os usuários do login único não
+// têm senha e ficam de fora: a verificação é responsabilidade do provedor de identidade.
+func (t *TwoFactor) Required(user *domain.User) bool {
+	return user.HasPassword() && slices.ContainsFunc(t.options.RequiredRoles, user.HasRole)
+}
+
+// Status retorna a verificação em duas etapas do usuário, ou repository.ErrTwoFactorNotFound se ele não a iniciou.
+func (t *TwoFactor) Status(ctx context.Context, user *domain.User) (*domain.TwoFactor, error) {
+	return t.repo.GetTwoFactor(ctx, user.ID)
+}
+
+// BeginEnrollment inicia a ativação, gerando o segredo. Uma ativação já iniciada mantém o segredo,
+// para que o QR code lido antes de recarregar a página continue valendo.
+func (t *TwoFactor) BeginEnrollment(ctx context.Context, user *domain.User) (*TwoFactorEnrollment, error) {
+	if !user.HasPassword() {
+		return nil, ErrPasswordNotSet
+	}
+
+	secret := totp.GenerateSecret()
+	existing, err := t.repo.GetTwoFactor(ctx, user.ID)
+	switch {
+	case err == nil && existing.Enabled():
+		return nil, repository.ErrTwoFactorEnabled
+	case err == nil:
+		secret = existing.Secret
+	case !errors.Is(err, repository.ErrTwoFactorNotFound):
+		return nil, err
+	default:
+		if err := t.repo.SetTwoFactorSecret(ctx, user.ID, secret); err != nil {
+			return nil, err
+		}
+	}
+	return &TwoFactorEnrollment{Secret: secret, URI: totp.URI(t.options.Issuer, user.Email, secret)}, nil
+}
+
+// ConfirmEnrollment conclui a ativação com o primeiro código do aplicativo e retorna os códigos de recuperação,
+// que só são exibidos nesse momento.
+func (t *TwoFactor) ConfirmEnrollment(ctx context.Context, user *domain.User, code string) ([]string, error) {
+	twoFactor, err := t.repo.GetTwoFactor(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled() {
+		return nil, repository.ErrTwoFactorEnabled
+	}
+
+	step, ok, err := totp.Validate(twoFactor.Secret, code, t.now(), totpSkew)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes := newRecoveryCodes()
+	if err := t.repo.EnableTwoFactor(ctx, user.ID, step, hashes); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "verificação em duas etapas ativada", "user_id", user.ID)
+	return codes, nil
+}
+
+// Verify confere um código do aplicativo ou um código de recuperação do usuário. Cada código só é aceito uma vez.
+func (t *TwoFactor) Verify(ctx context.Context, userID int64, code string) error {
+	twoFactor, err := t.repo.GetTwoFactor(ctx, userID)
+	if errors.Is(err, repository.ErrTwoFactorNotFound) {
+		return ErrInvalidTwoFactorCode
+	}
+	if err != nil {
+		return err
+	}
+	if !twoFactor.Enabled() {
+		return ErrInvalidTwoFactorCode
+	}
+
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) == totp.Digits {
+		step, ok, err := totp.Validate(twoFactor.Secret, code, t.now(), totpSkew)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		err = t.repo.UseTwoFactorStep(ctx, userID, step)
+		if errors.Is(err, repository.ErrTwoFactorStepUsed) {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+
+	err = t.repo.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+		return ErrInvalidTwoFactorCode
+	}
+	if err != nil {
+		return err
+	}
+	slog.WarnContext(ctx, "código de recuperação usado", "user_id", userID, "remaining", twoFactor.RecoveryCodes-1)
+	return nil
+}
+
+// RegenerateRecoveryCodes troca os códigos de recuperação por novos, depois de conferir um código do usuário.
+func (t *TwoFactor) RegenerateRecoveryCodes(ctx context.Context, user *domain.User, code string) ([]string, error) {
+	if err := t.Verify(ctx, user.ID, code); err != nil {
+		return nil, err
+	}
+	codes, hashes := newRecoveryCodes()
+	if err := t.repo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable desativa a verificação em duas etapas do próprio usuário, depois de conferir um código dele.
+func (t *TwoFactor) Disable(ctx context.Context, user *domain.User, code string) error {
+	if t.Required(user) {
+		return ErrTwoFactorRequired
+	}
+	if err := t.Verify(ctx, user.ID, code); err != nil {
+		return err
+	}
+	if err := t.repo.DisableTwoFactor(ctx, user.ID); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "verificação em duas etapas desativada", "user_id", user.ID)
+	return nil
+}
+
+// Reset desativa a verificação em duas etapas de um usuário que perdeu o aplicativo e os códigos de recuperação.
+// É feito por um administrador; se o papel do usuário a exigir, ele terá de ativá-la de novo.
+func (t *TwoFactor) Reset(ctx context.Context, admin *domain.User, userID int64) error {
+	if err := t.repo.DisableTwoFactor(ctx, userID); err != nil {
+		return err
+	}
+	slog.WarnContext(ctx, "verificação em duas etapas redefinida por um administrador", "user_id", userID, "admin_id", admin.ID)
+	return nil
+}
+
+// BeginLogin guarda, depois de conferida a senha, o login que aguarda o código. O navegador recebe um cookie
+// com o token do login pendente, que só vale pelo prazo LoginTTL.
+func (t *TwoFactor) BeginLogin(w http.ResponseWriter, r *http.Request, user *domain.User) error {
+	plain := randomString()
+	expiresAt := t.now().Add(t.options.LoginTTL)
+	err := t.tokens.CreateUserToken(r.Context(), &domain.UserToken{
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposeTwoFactorLogin,
+		TokenHash: hashToken(plain),
+		Email:     user.Email,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     t.cookieName,
+		Value:    plain,
+		Path:     "/",
+		Expires:  expiresAt,
+		Secure:   t.options.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// PendingLogin informa se o navegador tem um login aguardando o código.
+func (t *TwoFactor) PendingLogin(r *http.Request) (bool, error) {
+	_, err := t.pendingLogin(r)
+	if errors.Is(err, ErrTwoFactorLoginExpired) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PendingLoginEmail retorna o email do login que aguarda o código, ou ErrTwoFactorLoginExpired se não houver um.
+func (t *TwoFactor) PendingLoginEmail(r *http.Request) (string, error) {
+	token, err := t.pendingLogin(r)
+	if err != nil {
+		return "", err
+	}
+	return token.Email, nil
+}
+
+// CompleteLogin confere o código do login pendente e retorna o usuário, cuja sessão pode então ser iniciada.
+// Um código errado mantém o login pendente, para uma nova tentativa.
+func (t *TwoFactor) CompleteLogin(w http.ResponseWriter, r *http.Request, code string) (*domain.User, error) {
+	token, err := t.pendingLogin(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Verify(r.Context(), token.UserID, code); err != nil {
+		return nil, err
+	}
+
+	_, err = t.tokens.ConsumeUserToken(r.Context(), domain.TokenPurposeTwoFactorLogin, token.TokenHash)
+	if errors.Is(err, repository.ErrUserTokenNotFound) {
+		return nil, ErrTwoFactorLoginExpired
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.clearCookie(w)
+	return t.users.GetUserByID(r.Context(), token.UserID)
+}
+
+// Middleware retira as permissões dos usuários cujo papel exige a verificação em duas etapas enquanto eles
+// não a ativam. Deve vir depois do middleware que carrega o usuário; TwoFactorSetupRequired informa quando
+// as permissões foram retiradas.
+func (t *TwoFactor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := CurrentUser(r.Context())
+		if user == nil || user.TwoFactorEnabled || !t.Required(user) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		restricted := *user
+		restricted.Permissions = nil
+		ctx := context.WithValue(WithUser(r.Context(), &restricted), twoFactorSetupKey{}, true)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type twoFactorSetupKey struct{}
+
+// TwoFactorSetupRequired informa se o usuário autenticado na requisição precisa ativar a verificação em duas
+// etapas para recuperar as permissões do seu papel.
+func TwoFactorSetupRequired(ctx context.Context) bool {
+	required, _ := ctx.Value(twoFactorSetupKey{}).(bool)
+	return required
+}
+
+func (t *TwoFactor) pendingLogin(r *http.Request) (*domain.UserToken, error) {
+	cookie, err := r.Cookie(t.cookieName)
+	if err != nil {
+		return nil, ErrTwoFactorLoginExpired
+	}
+	token, err := t.tokens.GetUserToken(r.Context(), domain.TokenPurposeTwoFactorLogin, hashToken(cookie.Value))
+	if errors.Is(err, repository.ErrUserTokenNotFound) {
+		return nil, ErrTwoFactorLoginExpired
+	}
+	return token, err
+}
+
+func (t *TwoFactor) clearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     t.cookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   t.options.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// newRecoveryCodes gera os códigos de recuperação, no formato xxxxx-xxxxx, e os hashes guardados no banco.
+func newRecoveryCodes() ([]string, []string) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 8)
+		rand.Read(b)
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:recoveryCodeLength]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashToken(code)
+	}
+	return codes, hashes
+}
+
+// normalizeRecoveryCode aceita o código digitado com ou sem o hífen, em maiúsculas ou minúsculas.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/totp"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// memoryTwoFactorRepository guarda a verificação em duas etapas em memória.
+type memoryTwoFactorRepository struct {
+	twoFactors    map[int64]*domain.TwoFactor
+	recoveryCodes map[int64][]string
+	now           func() time.Time
+}
+
+func newMemoryTwoFactorRepository(now func() time.Time) *memoryTwoFactorRepository {
+	return &memoryTwoFactorRepository{twoFactors: map[int64]*domain.TwoFactor{}, recoveryCodes: map[int64][]string{}, now: now}
+}
+
+func (m *memoryTwoFactorRepository) GetTwoFactor(ctx context.Context, userID int64) (*domain.TwoFactor, error) {
+	twoFactor, ok := m.twoFactors[userID]
+	if !ok {
+		return nil, repository.ErrTwoFactorNotFound
+	}
+	copied := *twoFactor
+	copied.RecoveryCodes = len(m.recoveryCodes[userID])
+	return &copied, nil
+}
+
+func (m *memoryTwoFactorRepository) SetTwoFactorSecret(ctx context.Context, userID int64, secret string) error {
+	if twoFactor, ok := m.twoFactors[userID]; ok && twoFactor.Enabled() {
+		return repository.ErrTwoFactorEnabled
+	}
+	m.twoFactors[userID] = &domain.TwoFactor{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (m *memoryTwoFactorRepository) EnableTwoFactor(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error {
+	twoFactor, ok := m.twoFactors[userID]
+	if !ok || twoFactor.Enabled() {
+		return repository.ErrTwoFactorNotFound
+	}
+	now := m.now()
+	twoFactor.EnabledAt = &now
+	twoFactor.LastStep = step
+	m.recoveryCodes[userID] = slices.Clone(recoveryCodeHashes)
+	return nil
+}
+
+func (m *memoryTwoFactorRepository) UseTwoFactorStep(ctx context.Context, userID int64, step int64) error {
+	twoFactor, ok := m.twoFactors[userID]
+	if !ok || !twoFactor.Enabled() || twoFactor.LastStep >= step {
+		return repository.ErrTwoFactorStepUsed
+	}
+	twoFactor.LastStep = step
+	return nil
+}
+
+func (m *memoryTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodeHashes []string) error {
+	m.recoveryCodes[userID] = slices.Clone(recoveryCodeHashes)
+	return nil
+}
+
+func (m *memoryTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int64, hash string) error {
+	i := slices.Index(m.recoveryCodes[userID], hash)
+	if i < 0 {
+		return repository.ErrRecoveryCodeNotFound
+	}
+	m.recoveryCodes[userID] = slices.Delete(m.recoveryCodes[userID], i, i+1)
+	return nil
+}
+
+func (m *memoryTwoFactorRepository) DisableTwoFactor(ctx context.Context, userID int64) error {
+	delete(m.twoFactors, userID)
+	delete(m.recoveryCodes, userID)
+	return nil
+}
+
+func newTestTwoFactor(t *testing.T) (*TwoFactor, *memoryTwoFactorRepository, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	repo := newMemoryTwoFactorRepository(clock)
+	tokens := &memoryUserTokenRepository{tokens: map[string]*domain.UserToken{}, now: clock}
+	twoFactor := NewTwoFactor(repo, tokens, newMemoryUserRepository(), TwoFactorOptions{
+		Issuer:        "lucienne",
+		RequiredRoles: []string{RoleAdmin},
+		LoginTTL:      5 * time.Minute,
+	})
+	twoFactor.now = clock
+	return twoFactor, repo, &now
+}
+
+// enroll ativa a verificação em duas etapas do usuário e retorna o segredo e os códigos de recuperação.
+func enroll(t *testing.T, twoFactor *TwoFactor, user *domain.User, now time.Time) (string, []string) {
+	t.Helper()
+	enrollment, err := twoFactor.BeginEnrollment(context.Background(), user)
+	if err != nil {
+		t.Fatalf("Erro ao iniciar a ativação: %v", err)
+	}
+	code, _ := totp.Code(enrollment.Secret, totp.Step(now))
+	codes, err := twoFactor.ConfirmEnrollment(context.Background(), user, code)
+	if err != nil {
+		t.Fatalf("Erro ao confirmar a ativação: %v", err)
+	}
+	return enrollment.Secret, codes
+}
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	twoFactor, _, now := newTestTwoFactor(t)
+	user := &domain.User{ID: 1, Email: "ana@example.com", PasswordHash: "hash"}
+	ctx := context.Background()
+
+	first, err := twoFactor.BeginEnrollment(ctx, user)
+	if err != nil {
+		t.Fatalf("Erro ao iniciar a ativação: %v", err)
+	}
+	again, err := twoFactor.BeginEnrollment(ctx, user)
+	if err != nil || again.Secret != first.Secret {
+		t.Errorf("Esperava o mesmo segredo ao recarregar a página, mas obteve %q e %q (%v)", first.Secret, again.Secret, err)
+	}
+
+	if _, err := twoFactor.ConfirmEnrollment(ctx, user, "000000"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Esperava ErrInvalidTwoFactorCode, mas obteve %v", err)
+	}
+
+	code, _ := totp.Code(first.Secret, totp.Step(*now))
+	codes, err := twoFactor.ConfirmEnrollment(ctx, user, code)
+	if err != nil {
+		t.Fatalf("Erro ao confirmar a ativação: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("Esperava %d códigos de recuperação, mas obteve %d", recoveryCodeCount, len(codes))
+	}
+	if _, err := twoFactor.BeginEnrollment(ctx, user); !errors.Is(err, repository.ErrTwoFactorEnabled) {
+		t.Errorf("Esperava ErrTwoFactorEnabled, mas obteve %v", err)
+	}
+
+	sso := &domain.User{ID: 2, Email: "bia@example.com"}
+	if _, err := twoFactor.BeginEnrollment(ctx, sso); !errors.Is(err, ErrPasswordNotSet) {
+		t.Errorf("Esperava ErrPasswordNotSet para o usuário do login único, mas obteve %v", err)
+	}
+}
+
+func TestTwoFactorVerify(t *testing.T) {
+	twoFactor, _, now := newTestTwoFactor(t)
+	user := &domain.User{ID: 1, Email: "ana@example.com", PasswordHash: "hash"}
+	ctx := context.Background()
+	secret, codes := enroll(t, twoFactor, user, *now)
+
+	t.Run("não deve aceitar o código usado na ativação", func(t *testing.T) {
+		code, _ := totp.Code(secret, totp.Step(*now))
+		if err := twoFactor.Verify(ctx, user.ID, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Errorf("Esperava ErrInvalidTwoFactorCode, mas obteve %v", err)
+		}
+	})
+
+	t.Run("deve aceitar o código seguinte uma única vez", func(t *testing.T) {
+		*now = now.Add(totp.Period)
+		code, _ := totp.Code(secret, totp.Step(*now))
+		if err := twoFactor.Verify(ctx, user.ID, code); err != nil {
+			t.Fatalf("Esperava o código aceito, mas obteve %v", err)
+		}
+		if err := twoFactor.Verify(ctx, user.ID, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Errorf("Esperava ErrInvalidTwoFactorCode ao repetir o código, mas obteve %v", err)
+		}
+	})
+
+	t.Run("deve aceitar o código de recuperação uma única vez, com ou sem hífen", func(t *testing.T) {
+		if err := twoFactor.Verify(ctx, user.ID, codes[0]); err != nil {
+			t.Fatalf("Esperava o código de recuperação aceito, mas obteve %v", err)
+		}
+		if err := twoFactor.Verify(ctx, user.ID, codes[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Errorf("Esperava ErrInvalidTwoFactorCode ao repetir o código de recuperação, mas obteve %v", err)
+		}
+		withoutHyphen := codes[1][:5] + codes[1][6:]
+		if err := twoFactor.Verify(ctx, user.ID, withoutHyphen); err != nil {
+			t.Errorf("Esperava o código de recuperação sem hífen aceito, mas obteve %v", err)
+		}
+	})
+
+	t.Run("deve invalidar os códigos antigos ao gerar novos", func(t *testing.T) {
+		*now = now.Add(totp.Period)
+		code, _ := totp.Code(secret, totp.Step(*now))
+		fresh, err := twoFactor.RegenerateRecoveryCodes(ctx, user, code)
+		if err != nil {
+			t.Fatalf("Erro ao gerar os códigos de recuperação: %v", err)
+		}
+		if err := twoFactor.Verify(ctx, user.ID, codes[2]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Errorf("Esperava ErrInvalidTwoFactorCode para um código antigo, mas obteve %v", err)
+		}
+		if err := twoFactor.Verify(ctx, user.ID, fresh[0]); err != nil {
+			t.Errorf("Esperava o novo código aceito, mas obteve %v", err)
+		}
+	})
+}
+
+func TestTwoFactorDisable(t *testing.T) {
+	twoFactor, repo, now := newTestTwoFactor(t)
+	ctx := context.Background()
+	admin := &domain.User{ID: 1, Email: "ana@example.com", PasswordHash: "hash", Roles: []string{RoleAdmin}}
+	member := &domain.User{ID: 2, Email: "bia@example.com", PasswordHash: "hash", Roles: []string{RoleMember}}
+	_, adminCodes := enroll(t, twoFactor, admin, *now)
+	_, memberCodes := enroll(t, twoFactor, member, *now)
+
+	if err := twoFactor.Disable(ctx, admin, adminCodes[0]); !errors.Is(err, ErrTwoFactorRequired) {
+		t.Errorf("Esperava ErrTwoFactorRequired para o administrador, mas obteve %v", err)
+	}
+	if err := twoFactor.Disable(ctx, member, "xxxxx-xxxxx"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Esperava ErrInvalidTwoFactorCode, mas obteve %v", err)
+	}
+	if err := twoFactor.Disable(ctx, member, memberCodes[0]); err != nil {
+		t.Fatalf("Erro ao desativar: %v", err)
+	}
+	if _, err := repo.GetTwoFactor(ctx, member.ID); !errors.Is(err, repository.ErrTwoFactorNotFound) {
+		t.Errorf("Esperava a verificação removida, mas obteve %v", err)
+	}
+
+	if err := twoFactor.Reset(ctx, &domain.User{ID: 3}, admin.ID); err != nil {
+		t.Fatalf("Erro ao redefinir: %v", err)
+	}
+	if _, err := repo.GetTwoFactor(ctx, admin.ID); !errors.Is(err, repository.ErrTwoFactorNotFound) {
+		t.Errorf("Esperava a verificação do administrador redefinida, mas obteve %v", err)
+	}
+}
+
+func TestTwoFactorMiddleware(t *testing.T) {
+	twoFactor, _, _ := newTestTwoFactor(t)
+	testCases := []struct {
+		name                string
+		user                *domain.User
+		expectedPermissions int
+		expectedSetup       bool
+	}{
+		{name: "papel que exige a verificação sem ativá-la", user: &domain.User{ID: 1, PasswordHash: "hash", Roles: []string{RoleAdmin}, Permissions: []string{PermissionManageUsers}}, expectedSetup: true},
+		{name: "papel que exige a verificação já ativada", user: &domain.User{ID: 1, PasswordHash: "hash", Roles: []string{RoleAdmin}, Permissions: []string{PermissionManageUsers}, TwoFactorEnabled: true}, expectedPermissions: 1},
+		{name: "usuário do login único", user: &domain.User{ID: 1, Roles: []string{RoleAdmin}, Permissions: []string{PermissionManageUsers}}, expectedPermissions: 1},
+		{name: "papel que não exige a verificação", user: &domain.User{ID: 1, PasswordHash: "hash", Roles: []string{RoleMember}, Permissions: []string{PermissionManageAuthors}}, expectedPermissions: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var user *domain.User
+			var setup bool
+			handler := twoFactor.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user = CurrentUser(r.Context())
+				setup = TwoFactorSetupRequired(r.Context())
+			}))
+			req := httptest.NewRequest("GET", "/", nil)
+			req = req.WithContext(WithUser(req.Context(), tc.user))
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if len(user.Permissions) != tc.expectedPermissions {
+				t.Errorf("Esperava %d permissões, mas obteve %v", tc.expectedPermissions, user.Permissions)
+			}
+			if setup != tc.expectedSetup {
+				t.Errorf("Esperava TwoFactorSetupRequired %v, mas obteve %v", tc.expectedSetup, setup)
+			}
+			if len(tc.user.Permissions) != 1 {
+				t.Error("O usuário original não deveria ser alterado")
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// APIToken é um token de acesso pessoal usado pelos clientes da API. Só o hash do token é guardado.
+type APIToken struct {
+	ID         int64
+	UserID     int64
+	Name       string
+	TokenHash  string
+	Prefix     string
+	Scopes     []string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// HasScope informa se o token foi criado com o escopo.
+func (t *APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// Active informa se o token ainda pode ser usado em now: não foi revogado nem expirou.
+func (t *APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
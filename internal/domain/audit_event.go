@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"cmp"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Ações registradas no histórico de alterações.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	// AuditActionRestore tira o registro da lixeira, e AuditActionPurge o apaga de vez.
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+)
+
+// Tipos de registro do catálogo cujas alterações ficam no histórico.
+const (
+	AuditEntityAuthor    = "author"
+	AuditEntityPublisher = "publisher"
+	AuditEntityBook      = "book"
+)
+
+// AuditEntityTypes lista os tipos de registro aceitos no filtro do histórico.
+var AuditEntityTypes = []string{AuditEntityAuthor, AuditEntityPublisher, AuditEntityBook}
+
+// AuditEvent é uma alteração do catálogo, gravada na mesma transação que a alteração.
+type AuditEvent struct {
+	ID         int64
+	OccurredAt time.Time
+	// ActorID e ActorEmail identificam quem fez a alteração; ficam vazios nas alterações feitas fora de uma
+	// requisição autenticada. O email é copiado para que o histórico sobreviva à remoção do usuário.
+	ActorID    *int64
+	ActorEmail string
+	Action     string
+	EntityType string
+	EntityID   int64
+	// Before e After trazem, em JSON, apenas os campos alterados. Before é nulo na criação, e After na remoção.
+	Before    json.RawMessage
+	After     json.RawMessage
+	RequestID string
+}
+
+// AuditChange é a mudança de um campo, para exibição.
+type AuditChange struct {
+	Field  string
+	Before any
+	After  any
+}
+
+// Changes retorna as mudanças de cada campo, em ordem alfabética.
+func (e *AuditEvent) Changes() []AuditChange {
+	var before, after map[string]any
+	json.Unmarshal(e.Before, &before)
+	json.Unmarshal(e.After, &after)
+	return changes(before, after)
+}
+
+// changes compara os campos de dois estados de um registro e retorna os que mudaram, em ordem alfabética.
+func changes(before map[string]any, after map[string]any) []AuditChange {
+	fields := map[string]bool{}
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+	changes := make([]AuditChange, 0, len(fields))
+	for field := range fields {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			changes = append(changes, AuditChange{Field: field, Before: before[field], After: after[field]})
+		}
+	}
+	slices.SortFunc(changes, func(a, b AuditChange) int { return cmp.Compare(a.Field, b.Field) })
+	return changes
+}
+
+// AuditFilter seleciona os eventos do histórico. Os campos vazios não filtram.
+type AuditFilter struct {
+	EntityType string
+	EntityID   int64
+	// BeforeID pagina o histórico: retorna apenas os eventos anteriores a ele.
+	BeforeID int64
+	Limit    int
+}
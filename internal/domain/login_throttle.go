@@ -0,0 +1,109 @@
+package domain
+
+import "time"
+
+// Escopos das falhas de login contadas.
+const (
+	// LoginThrottleAccount conta as falhas de um email, cadastrado ou não.
+	LoginThrottleAccount = "account"
+	// LoginThrottleIP conta as falhas de um IP, qualquer que seja o email.
+	LoginThrottleIP = "ip"
+)
+
+// LoginThrottle são as falhas de login recentes de uma conta ou de um IP.
+type LoginThrottle struct {
+	ID    int64
+	Scope string
+	// Subject é o email, em minúsculas, ou o IP.
+	Subject       string
+	Failures      int
+	LastIP        string
+	LastFailureAt time.Time
+	// LockedUntil é até quando novas tentativas são recusadas, seja pelo atraso entre as tentativas, seja pelo bloqueio.
+	LockedUntil *time.Time
+	// LockedAt é quando o bloqueio começou, ou nil se houver apenas o atraso.
+	LockedAt *time.Time
+	// UserName é o nome do dono da conta, vazio para IPs e emails não cadastrados.
+	UserName string
+}
+
+// Throttled informa se as tentativas estão sendo recusadas em now.
+func (t *LoginThrottle) Throttled(now time.Time) bool {
+	return t.LockedUntil != nil && t.LockedUntil.After(now)
+}
+
+// Locked informa se há um bloqueio, e não apenas o atraso, em now.
+func (t *LoginThrottle) Locked(now time.Time) bool {
+	return t.LockedAt != nil && t.Throttled(now)
+}
+
+// LoginThrottlePolicy define quando as falhas de uma conta ou de um IP atrasam ou bloqueiam as tentativas seguintes.
+type LoginThrottlePolicy struct {
+	// DelayAfter é a quantidade de falhas aceitas sem atraso.
+	DelayAfter int
+	// BaseDelay é o atraso depois de DelayAfter falhas, dobrado a cada nova falha.
+	BaseDelay time.Duration
+	// MaxFailures é a quantidade de falhas que bloqueia as tentativas por LockDuration.
+	MaxFailures  int
+	LockDuration time.Duration
+	// Window é por quanto tempo uma falha é lembrada.
+	Window time.Duration
+}
+
+// Delay é o atraso depois de failures falhas, que dobra a cada falha sem passar do bloqueio.
+func (p LoginThrottlePolicy) Delay(failures int) time.Duration {
+	delay := p.BaseDelay
+	for range failures - p.DelayAfter {
+		delay *= 2
+		if delay >= p.LockDuration {
+			return p.LockDuration
+		}
+	}
+	return delay
+}
+
+// Reserve conta a tentativa feita em at como uma falha antes de a senha ou o código ser conferido, e já aplica às
+// tentativas seguintes o atraso ou o bloqueio que ela causa. Retorna false, sem mudar nada, se as tentativas
+// estiverem sendo recusadas em at. O LoginThrottleRepository faz o mesmo em uma única instrução.
+func (t *LoginThrottle) Reserve(policy LoginThrottlePolicy, ip string, at time.Time) bool {
+	if t.Throttled(at) {
+		return false
+	}
+	if t.LastFailureAt.Before(at.Add(-policy.Window)) {
+		t.Failures = 0
+		t.LockedAt = nil
+	}
+	t.Failures++
+	t.LastIP = ip
+	t.LastFailureAt = at
+
+	switch {
+	case t.Failures >= policy.MaxFailures:
+		until := at.Add(policy.LockDuration)
+		t.LockedUntil = &until
+		if t.LockedAt == nil {
+			t.LockedAt = &at
+		}
+	case t.Failures >= policy.DelayAfter:
+		until := at.Add(policy.Delay(t.Failures))
+		t.LockedUntil = &until
+	}
+	return true
+}
+
+// Release desfaz a reserva feita em at, quando a senha ou o código estava certo: a falha deixa de contar e o
+// atraso ou o bloqueio aplicado por ela é retirado.
+func (t *LoginThrottle) Release(at time.Time) {
+	t.Failures = max(t.Failures-1, 0)
+	if t.LastFailureAt.Equal(at) {
+		t.LockedUntil = nil
+	}
+	if t.LockedAt != nil && t.LockedAt.Equal(at) {
+		t.LockedAt = nil
+	}
+}
+
+// LockedBy informa se o bloqueio começou com a tentativa reservada em at.
+func (t *LoginThrottle) LockedBy(at time.Time) bool {
+	return t.LockedAt != nil && t.LockedAt.Equal(at)
+}
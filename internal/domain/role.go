@@ -0,0 +1,9 @@
+package domain
+
+// Role é um papel, que concede um conjunto de permissões aos usuários que o recebem.
+type Role struct {
+	ID          int64
+	Name        string
+	Description string
+	Permissions []string
+}
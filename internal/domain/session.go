@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Session é uma sessão de login. O ID é o hash do token guardado no cookie do navegador.
+type Session struct {
+	ID         string
+	UserID     int64
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+}
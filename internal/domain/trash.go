@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// TrashItem é um autor, uma editora ou um livro removido, que pode ser restaurado até o fim do prazo de retenção.
+type TrashItem struct {
+	// EntityType usa os mesmos tipos do histórico de alterações, como AuditEntityAuthor.
+	EntityType string
+	ID         int64
+	Name       string
+	DeletedAt  time.Time
+}
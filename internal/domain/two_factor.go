@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// TwoFactor é a verificação em duas etapas (TOTP) de um usuário.
+type TwoFactor struct {
+	UserID int64
+	// Secret é o segredo compartilhado com o aplicativo autenticador, em base32.
+	Secret string
+	// EnabledAt é quando o usuário confirmou o primeiro código, ou nil enquanto a ativação não termina.
+	EnabledAt *time.Time
+	// LastStep é o último intervalo de tempo aceito, para recusar o mesmo código duas vezes.
+	LastStep int64
+	// RecoveryCodes é a quantidade de códigos de recuperação ainda não usados.
+	RecoveryCodes int
+}
+
+// Enabled informa se a ativação foi concluída.
+func (t *TwoFactor) Enabled() bool {
+	return t.EnabledAt != nil
+}
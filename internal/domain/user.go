@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+type User struct {
+	ID           int64
+	Email        string
+	Name         string
+	PasswordHash string
+	CreatedAt    time.Time
+	// EmailVerifiedAt é quando o usuário confirmou o email atual, ou nil se ainda não confirmou.
+	EmailVerifiedAt *time.Time
+	// TwoFactorEnabled informa se o login exige, além da senha, um código do aplicativo autenticador.
+	TwoFactorEnabled bool
+	// Roles e Permissions são os papéis do usuário e as permissões concedidas por eles.
+	Roles       []string
+	Permissions []string
+}
+
+// Can informa se algum dos papéis do usuário concede a permissão.
+func (u *User) Can(permission string) bool {
+	return slices.Contains(u.Permissions, permission)
+}
+
+// HasPassword informa se o usuário pode entrar com senha. Os usuários criados pelo login único não têm senha.
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// EmailVerified informa se o usuário confirmou o email atual.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// HasRole informa se o usuário tem o papel.
+func (u *User) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Finalidades dos tokens de uso único.
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	// TokenPurposeTwoFactorLogin é o login que aguarda o código da verificação em duas etapas.
+	// O token fica num cookie em vez de ir por email.
+	TokenPurposeTwoFactorLogin = "two_factor_login"
+)
+
+// UserToken é um token de uso único enviado por email para redefinir a senha ou confirmar o email,
+// ou guardado no navegador durante a verificação em duas etapas. Só o hash do token é guardado.
+type UserToken struct {
+	ID        int64
+	UserID    int64
+	Purpose   string
+	TokenHash string
+	// Email é o endereço para onde o token foi enviado.
+	Email     string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
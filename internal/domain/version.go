@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// EntityVersion é o estado de um autor ou livro entre ValidFrom e ValidTo.
+type EntityVersion struct {
+	Version   int
+	ValidFrom time.Time
+	// ValidTo é quando a versão foi substituída, ou nil na versão atual.
+	ValidTo *time.Time
+	// Fields traz os campos do registro como em JSON, incluindo deleted_at, preenchido enquanto ele esteve
+	// na lixeira.
+	Fields map[string]any
+}
+
+// Current informa se a versão é o estado atual do registro.
+func (v *EntityVersion) Current() bool {
+	return v.ValidTo == nil
+}
+
+// Deleted informa se o registro estava na lixeira nesta versão.
+func (v *EntityVersion) Deleted() bool {
+	return v.Fields["deleted_at"] != nil
+}
+
+// Changes retorna os campos que mudaram desde a versão anterior; na primeira versão, previous é nil e todos os
+// campos preenchidos aparecem.
+func (v *EntityVersion) Changes(previous *EntityVersion) []AuditChange {
+	var before map[string]any
+	if previous != nil {
+		before = previous.Fields
+	}
+	return changes(before, v.Fields)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminLockoutHandler agrupa a página de administração dos bloqueios de login.
+type AdminLockoutHandler struct {
+	guard *auth.LoginGuard
+}
+
+// AdminLockoutsPageData reúne os dados da página de bloqueios de login.
+type AdminLockoutsPageData struct {
+	Lockouts []domain.LoginThrottle
+}
+
+// NewAdminLockoutHandler cria uma nova instância do AdminLockoutHandler com suas dependências.
+func NewAdminLockoutHandler(guard *auth.LoginGuard) *AdminLockoutHandler {
+	return &AdminLockoutHandler{guard: guard}
+}
+
+// DefineAdminLockouts registra as rotas dos bloqueios de login, que exigem a permissão de gerenciar usuários.
+func (h *AdminLockoutHandler) DefineAdminLockouts(router *mux.Router) {
+	admin := router.NewRoute().Subrouter()
+	admin.Use(middleware.RequirePermission(auth.PermissionManageUsers))
+	admin.HandleFunc("/admin/lockouts", h.ListLockouts).Methods("GET")
+	admin.HandleFunc("/admin/lockouts/{id}", h.Unlock).Methods("DELETE")
+}
+
+// ListLockouts exibe as contas e os IPs bloqueados por excesso de falhas de login.
+func (h *AdminLockoutHandler) ListLockouts(w http.ResponseWriter, r *http.Request) {
+	lockouts, err := h.guard.Locked(r.Context())
+	if err != nil {
+		serverError(w, r, "Erro interno ao listar bloqueios", err)
+		return
+	}
+
+	page, err := renderer.HTML.Render(r.Context(), "admin/lockouts.html", AdminLockoutsPageData{Lockouts: lockouts})
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+}
+
+// Unlock remove o bloqueio de uma conta ou de um IP antes do fim do prazo.
+func (h *AdminLockoutHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	err = h.guard.Unlock(r.Context(), auth.CurrentUser(r.Context()), id)
+	if errors.Is(err, repository.ErrLoginThrottleNotFound) {
+		http.Error(w, "Bloqueio não encontrado", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro interno ao remover o bloqueio", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Bloqueio removido com sucesso \n"))
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MockLoginThrottleRepository é a implementação falsa do LoginThrottleRepository para testes.
+type MockLoginThrottleRepository struct {
+	GetLoginThrottleFunc    func(ctx context.Context, scope string, subject string) (*domain.LoginThrottle, error)
+	ReserveLoginAttemptFunc func(ctx context.Context, scope string, subject string, ip string, at time.Time, policy domain.LoginThrottlePolicy) (*domain.LoginThrottle, error)
+	ReleaseLoginAttemptFunc func(ctx context.Context, id int64, at time.Time) error
+	ClearLoginThrottleFunc  func(ctx context.Context, scope string, subject string) error
+	GetLockedLoginsFunc     func(ctx context.Context) ([]domain.LoginThrottle, error)
+	UnlockLoginFunc         func(ctx context.Context, id int64) error
+}
+
+func (m *MockLoginThrottleRepository) GetLoginThrottle(ctx context.Context, scope string, subject string) (*domain.LoginThrottle, error) {
+	if m.GetLoginThrottleFunc != nil {
+		return m.GetLoginThrottleFunc(ctx, scope, subject)
+	}
+	return nil, repository.ErrLoginThrottleNotFound
+}
+
+func (m *MockLoginThrottleRepository) ReserveLoginAttempt(ctx context.Context, scope string, subject string, ip string, at time.Time, policy domain.LoginThrottlePolicy) (*domain.LoginThrottle, error) {
+	if m.ReserveLoginAttemptFunc != nil {
+		return m.ReserveLoginAttemptFunc(ctx, scope, subject, ip, at, policy)
+	}
+	return &domain.LoginThrottle{Scope: scope, Subject: subject, Failures: 1, LastIP: ip, LastFailureAt: at}, nil
+}
+
+func (m *MockLoginThrottleRepository) ReleaseLoginAttempt(ctx context.Context, id int64, at time.Time) error {
+	if m.ReleaseLoginAttemptFunc != nil {
+		return m.ReleaseLoginAttemptFunc(ctx, id, at)
+	}
+	return nil
+}
+
+func (m *MockLoginThrottleRepository) ClearLoginThrottle(ctx context.Context, scope string, subject string) error {
+	if m.ClearLoginThrottleFunc != nil {
+		return m.ClearLoginThrottleFunc(ctx, scope, subject)
+	}
+	return nil
+}
+
+func (m *MockLoginThrottleRepository) GetLockedLogins(ctx context.Context) ([]domain.LoginThrottle, error) {
+	if m.GetLockedLoginsFunc != nil {
+		return m.GetLockedLoginsFunc(ctx)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func (m *MockLoginThrottleRepository) UnlockLogin(ctx context.Context, id int64) error {
+	if m.UnlockLoginFunc != nil {
+		return m.UnlockLoginFunc(ctx, id)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func newTestLoginGuard(repo repository.LoginThrottleRepository, users repository.UserRepository) *auth.LoginGuard {
+	return auth.NewLoginGuard(repo, users, &recordingMailer{}, auth.LoginGuardOptions{
+		DelayAfter:    3,
+		BaseDelay:     2 * time.Second,
+		MaxFailures:   10,
+		IPMaxFailures: 100,
+		LockDuration:  15 * time.Minute,
+		FailureWindow: time.Hour,
+		BaseURL:       "http://lucienne.test",
+	})
+}
+
+func TestListLockouts(t *testing.T) {
+	until := time.Now().Add(10 * time.Minute)
+	lockedAt := time.Now()
+	repo := &MockLoginThrottleRepository{
+		GetLockedLoginsFunc: func(ctx context.Context) ([]domain.LoginThrottle, error) {
+			return []domain.LoginThrottle{
+				{ID: 1, Scope: domain.LoginThrottleAccount, Subject: "bia@example.com", Failures: 10, LastIP: "10.0.0.1", LockedUntil: &until, LockedAt: &lockedAt, UserName: "Bia"},
+				{ID: 2, Scope: domain.LoginThrottleIP, Subject: "10.0.0.9", Failures: 100, LastIP: "10.0.0.9", LockedUntil: &until, LockedAt: &lockedAt},
+			}, nil
+		},
+	}
+	router := mux.NewRouter()
+	NewAdminLockoutHandler(newTestLoginGuard(repo, nil)).DefineAdminLockouts(router)
+
+	t.Run("deve listar as contas e os IPs bloqueados", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, asAdmin(httptest.NewRequest("GET", "/admin/lockouts", nil)))
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
+		}
+		for _, expected := range []string{"Bia &lt;bia@example.com&gt;", "IP 10.0.0.9", `action="/admin/lockouts/2"`} {
+			if !strings.Contains(rr.Body.String(), expected) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+			}
+		}
+	})
+
+	t.Run("deve recusar quem não pode gerenciar usuários", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, signedIn(httptest.NewRequest("GET", "/admin/lockouts", nil)))
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusForbidden)
+		}
+	})
+}
+
+func TestUnlockLogin(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		id                   string
+		repoErr              error
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{name: "deve remover o bloqueio", id: "1", expectedStatusCode: http.StatusOK, expectedBodyContains: "Bloqueio removido com sucesso"},
+		{name: "deve retornar 404 para um bloqueio inexistente", id: "999", repoErr: repository.ErrLoginThrottleNotFound, expectedStatusCode: http.StatusNotFound, expectedBodyContains: "Bloqueio não encontrado"},
+		{name: "deve retornar 400 para um ID inválido", id: "abc", expectedStatusCode: http.StatusBadRequest, expectedBodyContains: "ID inválido"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &MockLoginThrottleRepository{
+				UnlockLoginFunc: func(ctx context.Context, id int64) error { return tc.repoErr },
+			}
+			router := mux.NewRouter()
+			NewAdminLockoutHandler(newTestLoginGuard(repo, nil)).DefineAdminLockouts(router)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, asAdmin(httptest.NewRequest("DELETE", "/admin/lockouts/"+tc.id, nil)))
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+}
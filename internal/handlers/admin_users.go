@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminUserHandler agrupa as páginas de administração de usuários.
+type AdminUserHandler struct {
+	users     repository.UserRepository
+	roles     repository.RoleRepository
+	twoFactor *auth.TwoFactor
+}
+
+// AdminUsersPageData reúne os dados da página de administração de usuários.
+type AdminUsersPageData struct {
+	Users []domain.User
+	Roles []domain.Role
+}
+
+// NewAdminUserHandler cria uma nova instância do AdminUserHandler com suas dependências.
+func NewAdminUserHandler(users repository.UserRepository, roles repository.RoleRepository, twoFactor *auth.TwoFactor) *AdminUserHandler {
+	return &AdminUserHandler{users: users, roles: roles, twoFactor: twoFactor}
+}
+
+// DefineAdminUsers registra as rotas de administração de usuários, que exigem a permissão de gerenciar usuários.
+func (h *AdminUserHandler) DefineAdminUsers(router *mux.Router) {
+	admin := router.NewRoute().Subrouter()
+	admin.Use(middleware.RequirePermission(auth.PermissionManageUsers))
+	admin.HandleFunc("/admin/users", h.ListUsers).Methods("GET")
+	admin.HandleFunc("/admin/users/{id}/roles", h.UpdateUserRoles).Methods("PUT")
+	admin.HandleFunc("/admin/users/{id}/2fa", h.ResetTwoFactor).Methods("DELETE")
+}
+
+// ListUsers exibe os usuários com seus papéis.
+func (h *AdminUserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.users.GetUsers(r.Context())
+	if err != nil {
+		serverError(w, r, "Erro interno ao listar usuários", err)
+		return
+	}
+	roles, err := h.roles.GetRoles(r.Context())
+	if err != nil {
+		serverError(w, r, "Erro interno ao listar papéis", err)
+		return
+	}
+
+	page, err := renderer.HTML.Render(r.Context(), "admin/users.html", AdminUsersPageData{Users: users, Roles: roles})
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+}
+
+// UpdateUserRoles substitui os papéis de um usuário pelos marcados no formulário.
+func (h *AdminUserHandler) UpdateUserRoles(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	roles := slices.Compact(slices.Sorted(slices.Values(r.PostForm["roles"])))
+	// Impede que o último acesso à administração seja perdido por engano.
+	if id == auth.CurrentUser(r.Context()).ID && !slices.Contains(roles, auth.RoleAdmin) {
+		http.Error(w, "Você não pode remover o seu próprio papel de administrador", http.StatusBadRequest)
+		return
+	}
+
+	err = h.roles.SetUserRoles(r.Context(), id, roles)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, repository.ErrRoleNotFound) {
+		http.Error(w, "Papel inválido", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro interno ao atualizar papéis", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Papéis atualizados com sucesso \n"))
+}
+
+// ResetTwoFactor desativa a verificação em duas etapas de um usuário que perdeu o aplicativo e os códigos de
+// recuperação, para que ele possa entrar só com a senha e ativá-la de novo.
+func (h *AdminUserHandler) ResetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	err = h.twoFactor.Reset(r.Context(), auth.CurrentUser(r.Context()), id)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro interno ao redefinir a verificação em duas etapas", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Verificação em duas etapas redefinida com sucesso \n"))
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// MockRoleRepository é a implementação falsa do RoleRepository para testes.
+type MockRoleRepository struct {
+	GetRolesFunc     func(ctx context.Context) ([]domain.Role, error)
+	SetUserRolesFunc func(ctx context.Context, userID int64, roles []string) error
+}
+
+func (m *MockRoleRepository) GetRoles(ctx context.Context) ([]domain.Role, error) {
+	if m.GetRolesFunc != nil {
+		return m.GetRolesFunc(ctx)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func (m *MockRoleRepository) SetUserRoles(ctx context.Context, userID int64, roles []string) error {
+	if m.SetUserRolesFunc != nil {
+		return m.SetUserRolesFunc(ctx, userID, roles)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func asAdmin(req *http.Request) *http.Request {
+	return signedInAs(req, auth.RoleAdmin, auth.PermissionManageUsers)
+}
+
+func TestListUsers(t *testing.T) {
+	users := &MockUserRepository{
+		GetUsersFunc: func(ctx context.Context) ([]domain.User, error) {
+			return []domain.User{{ID: 2, Name: "Bia", Email: "bia@example.com", Roles: []string{"librarian"}}}, nil
+		},
+	}
+	roles := &MockRoleRepository{
+		GetRolesFunc: func(ctx context.Context) ([]domain.Role, error) {
+			return []domain.Role{{ID: 1, Name: "member"}, {ID: 2, Name: "librarian"}}, nil
+		},
+	}
+	router := mux.NewRouter()
+	NewAdminUserHandler(users, roles, nil).DefineAdminUsers(router)
+
+	t.Run("deve listar os usuários com os papéis marcados", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, asAdmin(httptest.NewRequest("GET", "/admin/users", nil)))
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
+		}
+		for _, expected := range []string{"bia@example.com", `value="librarian" checked`, `value="member">`} {
+			if !strings.Contains(rr.Body.String(), expected) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+			}
+		}
+	})
+
+	t.Run("deve recusar quem não pode gerenciar usuários", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, signedIn(httptest.NewRequest("GET", "/admin/users", nil)))
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusForbidden)
+		}
+		if !strings.Contains(rr.Body.String(), "Acesso negado") {
+			t.Errorf("esperava a página de acesso negado, obteve %q", rr.Body.String())
+		}
+	})
+}
+
+func TestUpdateUserRoles(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		userID               string
+		roles                []string
+		repoErr              error
+		expectedStatusCode   int
+		expectedBodyContains string
+		expectedRoles        []string
+	}{
+		{
+			name:                 "deve atualizar os papéis sem repetições",
+			userID:               "2",
+			roles:                []string{"member", "librarian", "member"},
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: "Papéis atualizados com sucesso",
+			expectedRoles:        []string{"librarian", "member"},
+		},
+		{
+			name:                 "deve impedir que o administrador remova o próprio papel",
+			userID:               "1",
+			roles:                []string{"member"},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "Você não pode remover o seu próprio papel de administrador",
+		},
+		{
+			name:                 "deve retornar 400 para um papel inexistente",
+			userID:               "2",
+			roles:                []string{"superuser"},
+			repoErr:              repository.ErrRoleNotFound,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "Papel inválido",
+		},
+		{
+			name:                 "deve retornar 404 para um usuário inexistente",
+			userID:               "999",
+			roles:                []string{"member"},
+			repoErr:              repository.ErrUserNotFound,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedBodyContains: "Usuário não encontrado",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var saved []string
+			roles := &MockRoleRepository{
+				SetUserRolesFunc: func(ctx context.Context, userID int64, roles []string) error {
+					saved = roles
+					return tc.repoErr
+				},
+			}
+			router := mux.NewRouter()
+			NewAdminUserHandler(&MockUserRepository{}, roles, nil).DefineAdminUsers(router)
+
+			form := url.Values{"roles": tc.roles}
+			req := asAdmin(httptest.NewRequest("PUT", "/admin/users/"+tc.userID+"/roles", strings.NewReader(form.Encode())))
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if tc.expectedRoles != nil && !slices.Equal(saved, tc.expectedRoles) {
+				t.Errorf("papéis salvos: got %v want %v", saved, tc.expectedRoles)
+			}
+		})
+	}
+}
+
+func TestResetTwoFactor(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		userID               string
+		repoErr              error
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "deve redefinir a verificação em duas etapas do usuário",
+			userID:               "2",
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: "Verificação em duas etapas redefinida com sucesso",
+		},
+		{
+			name:                 "deve retornar 404 para um usuário inexistente",
+			userID:               "999",
+			repoErr:              repository.ErrUserNotFound,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedBodyContains: "Usuário não encontrado",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var disabled int64
+			repo := &MockTwoFactorRepository{
+				DisableTwoFactorFunc: func(ctx context.Context, userID int64) error {
+					disabled = userID
+					return tc.repoErr
+				},
+			}
+			router := mux.NewRouter()
+			NewAdminUserHandler(&MockUserRepository{}, &MockRoleRepository{}, newTestTwoFactor(repo, nil, nil)).DefineAdminUsers(router)
+
+			req := asAdmin(httptest.NewRequest("DELETE", "/admin/users/"+tc.userID+"/2fa", nil))
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if tc.repoErr == nil && disabled != 2 {
+				t.Errorf("usuário redefinido: got %d want 2", disabled)
+			}
+		})
+	}
+}
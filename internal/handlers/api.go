@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxAPIBodySize limita o corpo das requisições JSON.
+const maxAPIBodySize = 1 << 20
+
+// APIHandler agrupa as rotas JSON usadas pelos clientes autenticados por token de API.
+type APIHandler struct {
+	authors    repository.AuthorRepository
+	publishers repository.PublisherRepository
+}
+
+// apiResource é a representação em JSON de autores e editoras.
+type apiResource struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewAPIHandler cria uma nova instância do APIHandler com suas dependências.
+func NewAPIHandler(authors repository.AuthorRepository, publishers repository.PublisherRepository) *APIHandler {
+	return &APIHandler{authors: authors, publishers: publishers}
+}
+
+// DefineAPI registra as rotas JSON no subroteador de /api. Cada rota exige um token com o escopo correspondente.
+func (h *APIHandler) DefineAPI(router *mux.Router) {
+	router.Handle("/authors", middleware.RequireAPIScope(auth.ScopeAuthorsRead)(http.HandlerFunc(h.ListAuthors))).Methods("GET")
+	router.Handle("/authors", middleware.RequireAPIScope(auth.ScopeAuthorsWrite)(http.HandlerFunc(h.CreateAuthor))).Methods("POST")
+	router.Handle("/publishers", middleware.RequireAPIScope(auth.ScopePublishersRead)(http.HandlerFunc(h.ListPublishers))).Methods("GET")
+	router.Handle("/publishers", middleware.RequireAPIScope(auth.ScopePublishersWrite)(http.HandlerFunc(h.CreatePublisher))).Methods("POST")
+}
+
+// ListAuthors retorna todos os autores.
+func (h *APIHandler) ListAuthors(w http.ResponseWriter, r *http.Request) {
+	authors, err := h.authors.GetAuthors(r.Context())
+	if err != nil {
+		apiServerError(w, r, "Erro interno ao listar autores", err)
+		return
+	}
+
+	resources := make([]apiResource, 0, len(authors))
+	for _, author := range authors {
+		resources = append(resources, apiResource{ID: author.ID, Name: author.Name})
+	}
+	writeJSON(w, http.StatusOK, resources)
+}
+
+// CreateAuthor cria um autor a partir de {"name": "..."}.
+func (h *APIHandler) CreateAuthor(w http.ResponseWriter, r *http.Request) {
+	name, ok := decodeAPIName(w, r)
+	if !ok {
+		return
+	}
+
+	author := &domain.Author{Name: name}
+	err := h.authors.CreateAuthor(r.Context(), author)
+	if errors.Is(err, repository.ErrAuthorAlreadyExists) {
+		writeAPIError(w, http.StatusConflict, "conflict", "O autor já está cadastrado")
+		return
+	}
+	if err != nil {
+		apiServerError(w, r, "Erro interno ao criar autor", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, apiResource{ID: author.ID, Name: author.Name})
+}
+
+// ListPublishers retorna todas as editoras.
+func (h *APIHandler) ListPublishers(w http.ResponseWriter, r *http.Request) {
+	publishers, err := h.publishers.GetPublishers(r.Context())
+	if err != nil {
+		apiServerError(w, r, "Erro interno ao listar editoras", err)
+		return
+	}
+
+	resources := make([]apiResource, 0, len(publishers))
+	for _, publisher := range publishers {
+		resources = append(resources, apiResource{ID: publisher.ID, Name: publisher.Name})
+	}
+	writeJSON(w, http.StatusOK, resources)
+}
+
+// CreatePublisher cria uma editora a partir de {"name": "..."}.
+func (h *APIHandler) CreatePublisher(w http.ResponseWriter, r *http.Request) {
+	name, ok := decodeAPIName(w, r)
+	if !ok {
+		return
+	}
+
+	publisher := &domain.Publisher{Name: name}
+	err := h.publishers.CreatePublisher(r.Context(), publisher)
+	if errors.Is(err, repository.ErrPublisherAlreadyExists) {
+		writeAPIError(w, http.StatusConflict, "conflict", "A editora já está cadastrada")
+		return
+	}
+	if err != nil {
+		apiServerError(w, r, "Erro interno ao criar editora", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, apiResource{ID: publisher.ID, Name: publisher.Name})
+}
+
+// decodeAPIName lê o nome do corpo JSON, respondendo 400 quando o corpo é inválido ou o nome está em branco.
+func decodeAPIName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBodySize)).Decode(&body); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Corpo JSON inválido")
+		return "", false
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == "" {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", `O campo "name" é obrigatório`)
+		return "", false
+	}
+	return name, true
+}
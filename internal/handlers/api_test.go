@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// withAPIToken retorna a requisição como se autenticada por um token de bibliotecário com os escopos.
+func withAPIToken(req *http.Request, scopes ...string) *http.Request {
+	req = signedIn(req)
+	return req.WithContext(auth.WithAPIToken(req.Context(), &domain.APIToken{ID: 1, UserID: 1, Scopes: scopes}))
+}
+
+func TestAPIAuthors(t *testing.T) {
+	mockRepo := &MockAuthorRepository{
+		GetAuthorsFunc: func(ctx context.Context) ([]domain.Author, error) {
+			return []domain.Author{{ID: 1, Name: "Neil Gaiman"}}, nil
+		},
+		CreateAuthorFunc: func(ctx context.Context, author *domain.Author) error {
+			if author.Name == "J.R.R. Tolkien" {
+				return repository.ErrAuthorAlreadyExists
+			}
+			author.ID = 7
+			return nil
+		},
+	}
+	router := mux.NewRouter()
+	NewAPIHandler(mockRepo, &MockPublisherRepository{}).DefineAPI(router.PathPrefix("/api").Subrouter())
+
+	testCases := []struct {
+		name               string
+		req                *http.Request
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "deve listar os autores em JSON",
+			req:                withAPIToken(httptest.NewRequest("GET", "/api/authors", nil), auth.ScopeAuthorsRead),
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"id":1,"name":"Neil Gaiman"}]`,
+		},
+		{
+			name:               "deve criar um autor",
+			req:                withAPIToken(httptest.NewRequest("POST", "/api/authors", strings.NewReader(`{"name": " Ursula K. Le Guin "}`)), auth.ScopeAuthorsWrite),
+			expectedStatusCode: http.StatusCreated,
+			expectedBody:       `{"id":7,"name":"Ursula K. Le Guin"}`,
+		},
+		{
+			name:               "deve retornar 409 para um autor existente",
+			req:                withAPIToken(httptest.NewRequest("POST", "/api/authors", strings.NewReader(`{"name": "J.R.R. Tolkien"}`)), auth.ScopeAuthorsWrite),
+			expectedStatusCode: http.StatusConflict,
+			expectedBody:       `{"error":"conflict","message":"O autor já está cadastrado"}`,
+		},
+		{
+			name:               "deve retornar 400 para um JSON inválido",
+			req:                withAPIToken(httptest.NewRequest("POST", "/api/authors", strings.NewReader(`{"name":`)), auth.ScopeAuthorsWrite),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"error":"invalid_request","message":"Corpo JSON inválido"}`,
+		},
+		{
+			name:               "deve exigir o escopo de escrita",
+			req:                withAPIToken(httptest.NewRequest("POST", "/api/authors", strings.NewReader(`{"name": "Ursula K. Le Guin"}`)), auth.ScopeAuthorsRead),
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       `{"error":"insufficient_scope","message":"O token não tem o escopo authors:write"}`,
+		},
+		{
+			name:               "deve exigir um token",
+			req:                signedIn(httptest.NewRequest("GET", "/api/authors", nil)),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, tc.req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if tc.expectedBody != "" && strings.TrimSpace(rr.Body.String()) != tc.expectedBody {
+				t.Errorf("handler retornou corpo inesperado: got %q want %q", rr.Body.String(), tc.expectedBody)
+			}
+			if !json.Valid(rr.Body.Bytes()) {
+				t.Errorf("esperava uma resposta em JSON, obteve %q", rr.Body.String())
+			}
+		})
+	}
+}
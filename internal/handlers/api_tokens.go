@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// APITokenLifetimes são as validades oferecidas no formulário de criação de tokens, em dias.
+var APITokenLifetimes = []int{30, 90, 365}
+
+// APITokenHandler agrupa as páginas em que o usuário gerencia os próprios tokens de API.
+type APITokenHandler struct {
+	tokens repository.APITokenRepository
+}
+
+// APITokensPageData reúne os dados da página de tokens de API.
+type APITokensPageData struct {
+	Tokens    []domain.APIToken
+	Scopes    []auth.Scope
+	Lifetimes []int
+	// NewToken é o token recém-criado, exibido uma única vez.
+	NewToken string
+	Now      time.Time
+}
+
+// NewAPITokenHandler cria uma nova instância do APITokenHandler com suas dependências.
+func NewAPITokenHandler(tokens repository.APITokenRepository) *APITokenHandler {
+	return &APITokenHandler{tokens: tokens}
+}
+
+// DefineAPITokens registra as rotas de tokens de API, que exigem login.
+func (h *APITokenHandler) DefineAPITokens(router *mux.Router) {
+	profile := router.NewRoute().Subrouter()
+	profile.Use(middleware.RequireUser)
+	profile.HandleFunc("/profile/tokens", h.ListAPITokens).Methods("GET")
+	profile.HandleFunc("/profile/tokens", h.CreateAPIToken).Methods("POST")
+	profile.HandleFunc("/profile/tokens/{id}", h.RevokeAPIToken).Methods("DELETE")
+}
+
+// ListAPITokens exibe os tokens do usuário e o formulário de criação.
+func (h *APITokenHandler) ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	h.renderTokens(w, r, http.StatusOK, "")
+}
+
+// CreateAPIToken cria um token com o nome, os escopos e a validade do formulário e o exibe uma única vez.
+func (h *APITokenHandler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+	days, err := strconv.Atoi(r.FormValue("expires_in_days"))
+	if err != nil {
+		http.Error(w, "Validade do token inválida", http.StatusBadRequest)
+		return
+	}
+
+	user := auth.CurrentUser(r.Context())
+	plain, _, err := auth.CreateAPIToken(r.Context(), h.tokens, user, r.FormValue("name"), r.PostForm["scopes"], time.Duration(days)*24*time.Hour)
+	switch {
+	case errors.Is(err, auth.ErrAPITokenNameRequired):
+		http.Error(w, `O campo "name" é obrigatório`, http.StatusBadRequest)
+		return
+	case errors.Is(err, auth.ErrInvalidScopes):
+		http.Error(w, "Escolha ao menos um escopo permitido para a sua conta", http.StatusBadRequest)
+		return
+	case errors.Is(err, auth.ErrInvalidAPITokenLifetime):
+		http.Error(w, "Validade do token inválida", http.StatusBadRequest)
+		return
+	case err != nil:
+		serverError(w, r, "Erro interno ao criar token", err)
+		return
+	}
+
+	// A página não pode ficar em cache, já que exibe o token.
+	w.Header().Set("Cache-Control", "no-store")
+	h.renderTokens(w, r, http.StatusCreated, plain)
+}
+
+// RevokeAPIToken revoga um token do usuário.
+func (h *APITokenHandler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	err = h.tokens.RevokeAPIToken(r.Context(), auth.CurrentUser(r.Context()).ID, id)
+	if errors.Is(err, repository.ErrAPITokenNotFound) {
+		http.Error(w, "Token não encontrado", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro interno ao revogar token", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Token revogado com sucesso \n"))
+}
+
+func (h *APITokenHandler) renderTokens(w http.ResponseWriter, r *http.Request, status int, newToken string) {
+	user := auth.CurrentUser(r.Context())
+	tokens, err := h.tokens.GetUserAPITokens(r.Context(), user.ID)
+	if err != nil {
+		serverError(w, r, "Erro interno ao listar tokens", err)
+		return
+	}
+
+	// Só são oferecidos os escopos cujas permissões o usuário tem.
+	var scopes []auth.Scope
+	for _, scope := range auth.Scopes {
+		if scope.Permission == "" || user.Can(scope.Permission) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	data := APITokensPageData{Tokens: tokens, Scopes: scopes, Lifetimes: APITokenLifetimes, NewToken: newToken, Now: time.Now()}
+	page, err := renderer.HTML.Render(r.Context(), "users/tokens.html", data)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(page)
+}
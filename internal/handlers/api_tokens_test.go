@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MockAPITokenRepository é a implementação falsa do APITokenRepository para testes.
+type MockAPITokenRepository struct {
+	CreateAPITokenFunc    func(ctx context.Context, token *domain.APIToken) error
+	GetUserAPITokensFunc  func(ctx context.Context, userID int64) ([]domain.APIToken, error)
+	GetAPITokenByHashFunc func(ctx context.Context, hash string) (*domain.APIToken, error)
+	RevokeAPITokenFunc    func(ctx context.Context, userID int64, id int64) error
+}
+
+func (m *MockAPITokenRepository) CreateAPIToken(ctx context.Context, token *domain.APIToken) error {
+	if m.CreateAPITokenFunc != nil {
+		return m.CreateAPITokenFunc(ctx, token)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func (m *MockAPITokenRepository) GetUserAPITokens(ctx context.Context, userID int64) ([]domain.APIToken, error) {
+	if m.GetUserAPITokensFunc != nil {
+		return m.GetUserAPITokensFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockAPITokenRepository) GetAPITokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	if m.GetAPITokenByHashFunc != nil {
+		return m.GetAPITokenByHashFunc(ctx, hash)
+	}
+	return nil, repository.ErrAPITokenNotFound
+}
+
+func (m *MockAPITokenRepository) TouchAPIToken(ctx context.Context, id int64, lastUsedAt time.Time) error {
+	return nil
+}
+
+func (m *MockAPITokenRepository) RevokeAPIToken(ctx context.Context, userID int64, id int64) error {
+	if m.RevokeAPITokenFunc != nil {
+		return m.RevokeAPITokenFunc(ctx, userID, id)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func TestListAPITokens(t *testing.T) {
+	revokedAt := time.Now()
+	repo := &MockAPITokenRepository{
+		GetUserAPITokensFunc: func(ctx context.Context, userID int64) ([]domain.APIToken, error) {
+			return []domain.APIToken{
+				{ID: 1, Name: "sincronização", Prefix: "lct_abc123", Scopes: []string{"authors:read"}, ExpiresAt: time.Now().Add(time.Hour)},
+				{ID: 2, Name: "antigo", Prefix: "lct_def456", Scopes: []string{"authors:read"}, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt},
+			}, nil
+		},
+	}
+	router := mux.NewRouter()
+	NewAPITokenHandler(repo).DefineAPITokens(router)
+
+	t.Run("deve listar os tokens e os escopos permitidos ao membro", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, signedInAs(httptest.NewRequest("GET", "/profile/tokens", nil), auth.RoleMember))
+
+		body := rr.Body.String()
+		for _, expected := range []string{"lct_abc123", `action="/profile/tokens/1"`, "Revogado", `value="authors:read"`, "Nunca usado"} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", body, expected)
+			}
+		}
+		if strings.Contains(body, `value="authors:write"`) {
+			t.Errorf("o membro não deveria poder escolher escopos de escrita: %q", body)
+		}
+		if strings.Contains(body, `action="/profile/tokens/2"`) {
+			t.Errorf("o token revogado não deveria poder ser revogado de novo: %q", body)
+		}
+	})
+
+	t.Run("deve exigir login", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/profile/tokens", nil))
+
+		if status := rr.Code; status != http.StatusSeeOther {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusSeeOther)
+		}
+	})
+}
+
+func TestCreateAPIToken(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		form                 url.Values
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "deve criar o token e exibi-lo uma vez",
+			form:                 url.Values{"name": {"sincronização"}, "scopes": {"authors:read", "authors:write"}, "expires_in_days": {"90"}},
+			expectedStatusCode:   http.StatusCreated,
+			expectedBodyContains: "Copie o token agora",
+		},
+		{
+			name:                 "deve recusar sem escopos",
+			form:                 url.Values{"name": {"sincronização"}, "expires_in_days": {"90"}},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "Escolha ao menos um escopo",
+		},
+		{
+			name:                 "deve recusar sem nome",
+			form:                 url.Values{"name": {" "}, "scopes": {"authors:read"}, "expires_in_days": {"90"}},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: `O campo "name" é obrigatório`,
+		},
+		{
+			name:                 "deve recusar uma validade inválida",
+			form:                 url.Values{"name": {"sincronização"}, "scopes": {"authors:read"}, "expires_in_days": {"nunca"}},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "Validade do token inválida",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var created *domain.APIToken
+			repo := &MockAPITokenRepository{
+				CreateAPITokenFunc: func(ctx context.Context, token *domain.APIToken) error {
+					created = token
+					return nil
+				},
+			}
+			router := mux.NewRouter()
+			NewAPITokenHandler(repo).DefineAPITokens(router)
+
+			req := signedIn(httptest.NewRequest("POST", "/profile/tokens", strings.NewReader(tc.form.Encode())))
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if tc.expectedStatusCode != http.StatusCreated {
+				return
+			}
+
+			plain := regexp.MustCompile(`lct_[A-Za-z0-9_-]{43}`).FindString(rr.Body.String())
+			if plain == "" || auth.HashAPIToken(plain) != created.TokenHash {
+				t.Errorf("o token exibido não corresponde ao hash guardado: %q", plain)
+			}
+			if rr.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("a página com o token não deveria ir para o cache: %q", rr.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestRevokeAPIToken(t *testing.T) {
+	repo := &MockAPITokenRepository{
+		RevokeAPITokenFunc: func(ctx context.Context, userID int64, id int64) error {
+			if userID != 1 || id != 5 {
+				return repository.ErrAPITokenNotFound
+			}
+			return nil
+		},
+	}
+	router := mux.NewRouter()
+	NewAPITokenHandler(repo).DefineAPITokens(router)
+
+	for path, expectedStatusCode := range map[string]int{"/profile/tokens/5": http.StatusOK, "/profile/tokens/6": http.StatusNotFound} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, signedIn(httptest.NewRequest("DELETE", path, nil)))
+
+		if status := rr.Code; status != expectedStatusCode {
+			t.Errorf("%s: handler retornou status code errado: got %v want %v", path, status, expectedStatusCode)
+		}
+	}
+}
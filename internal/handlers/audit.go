@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultAuditLimit é a quantidade de eventos por página do histórico.
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+// errInvalidAuditFilter é retornado quando algum parâmetro do filtro do histórico é inválido.
+var errInvalidAuditFilter = errors.New("filtro inválido")
+
+// AuditHandler agrupa a página e a rota JSON do histórico de alterações do catálogo.
+type AuditHandler struct {
+	events repository.AuditEventRepository
+}
+
+// AuditPageData reúne os dados da página do histórico.
+type AuditPageData struct {
+	Filter      domain.AuditFilter
+	EntityTypes []string
+	Events      []domain.AuditEvent
+	// NextBefore pagina para os eventos anteriores ao último exibido; é zero na última página.
+	NextBefore int64
+}
+
+// apiAuditEvent é a representação em JSON de um evento do histórico.
+type apiAuditEvent struct {
+	ID         int64           `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      *apiAuditActor  `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id,omitempty"`
+}
+
+type apiAuditActor struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+}
+
+// NewAuditHandler cria uma nova instância do AuditHandler com suas dependências.
+func NewAuditHandler(events repository.AuditEventRepository) *AuditHandler {
+	return &AuditHandler{events: events}
+}
+
+// DefineAudit registra a página do histórico, que exige a permissão de consultar o histórico.
+func (h *AuditHandler) DefineAudit(router *mux.Router) {
+	admin := router.NewRoute().Subrouter()
+	admin.Use(middleware.RequirePermission(auth.PermissionViewAudit))
+	admin.HandleFunc("/admin/audit", h.ShowAudit).Methods("GET")
+}
+
+// DefineAuditAPI registra a rota JSON do histórico no subroteador de /api.
+func (h *AuditHandler) DefineAuditAPI(router *mux.Router) {
+	router.Handle("/audit-events", middleware.RequireAPIScope(auth.ScopeAuditRead)(http.HandlerFunc(h.ListAuditEvents))).Methods("GET")
+}
+
+// ShowAudit exibe o histórico, filtrado por entity_type e entity_id e paginado por before.
+func (h *AuditHandler) ShowAudit(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Filtro inválido", http.StatusBadRequest)
+		return
+	}
+	events, err := h.events.GetAuditEvents(r.Context(), filter)
+	if err != nil {
+		serverError(w, r, "Erro interno ao listar o histórico", err)
+		return
+	}
+
+	data := AuditPageData{Filter: filter, EntityTypes: domain.AuditEntityTypes, Events: events}
+	if len(events) == filter.Limit {
+		data.NextBefore = events[len(events)-1].ID
+	}
+	page, err := renderer.HTML.Render(r.Context(), "admin/audit.html", data)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+}
+
+// ListAuditEvents retorna os eventos do histórico, dos mais recentes para os mais antigos. Aceita os mesmos
+// filtros da página, além de limit.
+func (h *AuditHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request", "Filtro inválido")
+		return
+	}
+	events, err := h.events.GetAuditEvents(r.Context(), filter)
+	if err != nil {
+		apiServerError(w, r, "Erro interno ao listar o histórico", err)
+		return
+	}
+
+	resources := make([]apiAuditEvent, 0, len(events))
+	for _, event := range events {
+		resource := apiAuditEvent{
+			ID:         event.ID,
+			OccurredAt: event.OccurredAt,
+			Action:     event.Action,
+			EntityType: event.EntityType,
+			EntityID:   event.EntityID,
+			Before:     event.Before,
+			After:      event.After,
+			RequestID:  event.RequestID,
+		}
+		if event.ActorID != nil {
+			resource.Actor = &apiAuditActor{ID: *event.ActorID, Email: event.ActorEmail}
+		}
+		resources = append(resources, resource)
+	}
+	writeJSON(w, http.StatusOK, resources)
+}
+
+// parseAuditFilter lê o filtro do histórico da query string. Os parâmetros ausentes não filtram.
+func parseAuditFilter(query url.Values) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{EntityType: query.Get("entity_type"), Limit: defaultAuditLimit}
+	if filter.EntityType != "" && !slices.Contains(domain.AuditEntityTypes, filter.EntityType) {
+		return filter, errInvalidAuditFilter
+	}
+
+	for name, target := range map[string]*int64{"entity_id": &filter.EntityID, "before": &filter.BeforeID} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n <= 0 {
+			return filter, errInvalidAuditFilter
+		}
+		*target = n
+	}
+
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return filter, errInvalidAuditFilter
+		}
+		filter.Limit = min(n, maxAuditLimit)
+	}
+	return filter, nil
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MockAuditEventRepository é a implementação falsa do AuditEventRepository para testes.
+type MockAuditEventRepository struct {
+	GetAuditEventsFunc func(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error)
+}
+
+func (m *MockAuditEventRepository) GetAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	if m.GetAuditEventsFunc != nil {
+		return m.GetAuditEventsFunc(ctx, filter)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func testAuditEvents() []domain.AuditEvent {
+	actorID := int64(1)
+	occurredAt := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	return []domain.AuditEvent{
+		{
+			ID: 9, OccurredAt: occurredAt, ActorID: &actorID, ActorEmail: "teste@example.com", Action: domain.AuditActionUpdate,
+			EntityType: domain.AuditEntityAuthor, EntityID: 3, Before: json.RawMessage(`{"name":"Neil"}`), After: json.RawMessage(`{"name":"Neil Gaiman"}`), RequestID: "req-1",
+		},
+		{
+			ID: 4, OccurredAt: occurredAt, Action: domain.AuditActionCreate,
+			EntityType: domain.AuditEntityAuthor, EntityID: 3, After: json.RawMessage(`{"name":"Neil"}`),
+		},
+	}
+}
+
+func TestShowAudit(t *testing.T) {
+	var filters []domain.AuditFilter
+	repo := &MockAuditEventRepository{
+		GetAuditEventsFunc: func(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+			filters = append(filters, filter)
+			return testAuditEvents(), nil
+		},
+	}
+	router := mux.NewRouter()
+	NewAuditHandler(repo).DefineAudit(router)
+
+	testCases := []struct {
+		name                 string
+		req                  *http.Request
+		expectedStatusCode   int
+		expectedFilter       *domain.AuditFilter
+		expectedBodyContains []string
+	}{
+		{
+			name:                 "deve listar o histórico do registro",
+			req:                  signedInAs(httptest.NewRequest("GET", "/admin/audit?entity_type=author&entity_id=3", nil), auth.RoleAdmin, auth.PermissionViewAudit),
+			expectedStatusCode:   http.StatusOK,
+			expectedFilter:       &domain.AuditFilter{EntityType: domain.AuditEntityAuthor, EntityID: 3, Limit: defaultAuditLimit},
+			expectedBodyContains: []string{"teste@example.com", "name: Neil → Neil Gaiman", "sistema", "req-1"},
+		},
+		{
+			name:                 "deve oferecer a página seguinte quando a página está cheia",
+			req:                  signedInAs(httptest.NewRequest("GET", "/admin/audit?limit=2", nil), auth.RoleAdmin, auth.PermissionViewAudit),
+			expectedStatusCode:   http.StatusOK,
+			expectedFilter:       &domain.AuditFilter{Limit: 2},
+			expectedBodyContains: []string{"before=4"},
+		},
+		{
+			name:                 "deve retornar 400 para um tipo desconhecido",
+			req:                  signedInAs(httptest.NewRequest("GET", "/admin/audit?entity_type=users", nil), auth.RoleAdmin, auth.PermissionViewAudit),
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: []string{"Filtro inválido"},
+		},
+		{
+			name:               "deve recusar quem não pode consultar o histórico",
+			req:                signedIn(httptest.NewRequest("GET", "/admin/audit", nil)),
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filters = nil
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, tc.req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if tc.expectedFilter != nil && (len(filters) != 1 || filters[0] != *tc.expectedFilter) {
+				t.Errorf("handler consultou o filtro errado: got %+v want %+v", filters, *tc.expectedFilter)
+			}
+			for _, expected := range tc.expectedBodyContains {
+				if !strings.Contains(rr.Body.String(), expected) {
+					t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIAuditEvents(t *testing.T) {
+	repo := &MockAuditEventRepository{
+		GetAuditEventsFunc: func(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+			return testAuditEvents()[:1], nil
+		},
+	}
+	router := mux.NewRouter()
+	NewAuditHandler(repo).DefineAuditAPI(router.PathPrefix("/api").Subrouter())
+
+	t.Run("deve listar o histórico em JSON", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/audit-events?entity_type=author&entity_id=3", nil)
+		req = signedInAs(req, auth.RoleAdmin, auth.PermissionViewAudit)
+		req = req.WithContext(auth.WithAPIToken(req.Context(), &domain.APIToken{ID: 1, UserID: 1, Scopes: []string{auth.ScopeAuditRead}}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
+		}
+		expected := `[{"id":9,"occurred_at":"2026-05-04T12:00:00Z","actor":{"id":1,"email":"teste@example.com"},"action":"update","entity_type":"author","entity_id":3,"before":{"name":"Neil"},"after":{"name":"Neil Gaiman"},"request_id":"req-1"}]`
+		if body := strings.TrimSpace(rr.Body.String()); body != expected {
+			t.Errorf("handler retornou corpo inesperado: got %v want %v", body, expected)
+		}
+	})
+
+	t.Run("deve exigir a permissão de consultar o histórico", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withAPIToken(httptest.NewRequest("GET", "/api/audit-events", nil), auth.ScopeAuditRead))
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusForbidden)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// historyTimeLayout é o formato do campo datetime-local usado para consultar uma data.
+const historyTimeLayout = "2006-01-02T15:04"
+
+// AuthorHistoryPageData reúne os dados da página de histórico de um autor.
+type AuthorHistoryPageData struct {
+	ID       int64
+	Name     string
+	Versions []VersionEntry
+	// At é a data consultada, como digitada no formulário, e AtVersion a versão em vigor nela, ou nil se o
+	// autor ainda não existia.
+	At        string
+	AtVersion *domain.EntityVersion
+}
+
+// VersionEntry é uma versão com os campos que mudaram em relação à anterior.
+type VersionEntry struct {
+	domain.EntityVersion
+	Changes []domain.AuditChange
+}
+
+// AuthorHistory exibe as versões do autor, com as mudanças de cada uma, e a versão em vigor na data do parâmetro at.
+func (h *AuthorHandler) AuthorHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.history.GetVersions(r.Context(), domain.AuditEntityAuthor, id)
+	if errors.Is(err, repository.ErrHistoryRecordNotFound) {
+		http.Error(w, "Autor não encontrado", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro ao buscar o histórico do autor", err)
+		return
+	}
+
+	name, _ := versions[0].Fields["name"].(string)
+	data := AuthorHistoryPageData{ID: id, Name: name, At: r.URL.Query().Get("at")}
+	for i, version := range versions {
+		var previous *domain.EntityVersion
+		if i+1 < len(versions) {
+			previous = &versions[i+1]
+		}
+		data.Versions = append(data.Versions, VersionEntry{EntityVersion: version, Changes: version.Changes(previous)})
+	}
+
+	if data.At != "" {
+		at, err := time.ParseInLocation(historyTimeLayout, data.At, time.Local)
+		if err != nil {
+			http.Error(w, "Data inválida", http.StatusBadRequest)
+			return
+		}
+		data.AtVersion, err = h.history.GetVersionAt(r.Context(), domain.AuditEntityAuthor, id, at)
+		if err != nil && !errors.Is(err, repository.ErrVersionNotFound) {
+			serverError(w, r, "Erro ao buscar a versão do autor", err)
+			return
+		}
+	}
+
+	page, err := renderer.HTML.Render(r.Context(), "authors/history.html", data)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+}
+
+// RevertAuthor devolve o autor ao nome de uma versão anterior.
+func (h *AuthorHandler) RevertAuthor(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		http.Error(w, "Versão inválida", http.StatusBadRequest)
+		return
+	}
+
+	err = h.history.RevertToVersion(r.Context(), domain.AuditEntityAuthor, id, version)
+	switch {
+	case errors.Is(err, repository.ErrHistoryRecordNotFound):
+		http.Error(w, "Autor não encontrado", http.StatusNotFound)
+		return
+	case errors.Is(err, repository.ErrVersionNotFound):
+		http.Error(w, "Versão não encontrada", http.StatusNotFound)
+		return
+	case errors.Is(err, repository.ErrRevertNameInUse):
+		http.Error(w, "Outro autor já usa o nome dessa versão", http.StatusConflict)
+		return
+	case err != nil:
+		serverError(w, r, "Erro interno ao reverter autor", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Autor revertido para a versão %d \n", version)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MockHistoryRepository é a implementação falsa do HistoryRepository para testes.
+type MockHistoryRepository struct {
+	GetVersionsFunc     func(ctx context.Context, entityType string, id int64) ([]domain.EntityVersion, error)
+	GetVersionAtFunc    func(ctx context.Context, entityType string, id int64, at time.Time) (*domain.EntityVersion, error)
+	RevertToVersionFunc func(ctx context.Context, entityType string, id int64, version int) error
+}
+
+func (m *MockHistoryRepository) GetVersions(ctx context.Context, entityType string, id int64) ([]domain.EntityVersion, error) {
+	if m.GetVersionsFunc != nil {
+		return m.GetVersionsFunc(ctx, entityType, id)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func (m *MockHistoryRepository) GetVersionAt(ctx context.Context, entityType string, id int64, at time.Time) (*domain.EntityVersion, error) {
+	if m.GetVersionAtFunc != nil {
+		return m.GetVersionAtFunc(ctx, entityType, id, at)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func (m *MockHistoryRepository) RevertToVersion(ctx context.Context, entityType string, id int64, version int) error {
+	if m.RevertToVersionFunc != nil {
+		return m.RevertToVersionFunc(ctx, entityType, id, version)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func testAuthorVersions() []domain.EntityVersion {
+	created := time.Date(2026, 5, 4, 12, 0, 0, 0, time.Local)
+	renamed := created.Add(24 * time.Hour)
+	return []domain.EntityVersion{
+		{Version: 2, ValidFrom: renamed, Fields: map[string]any{"name": "Neil Gaiman", "deleted_at": nil}},
+		{Version: 1, ValidFrom: created, ValidTo: &renamed, Fields: map[string]any{"name": "Neil", "deleted_at": nil}},
+	}
+}
+
+func TestAuthorHistory(t *testing.T) {
+	var requestedAt time.Time
+	history := &MockHistoryRepository{
+		GetVersionsFunc: func(ctx context.Context, entityType string, id int64) ([]domain.EntityVersion, error) {
+			if id != 3 {
+				return nil, repository.ErrHistoryRecordNotFound
+			}
+			return testAuthorVersions(), nil
+		},
+		GetVersionAtFunc: func(ctx context.Context, entityType string, id int64, at time.Time) (*domain.EntityVersion, error) {
+			requestedAt = at
+			if at.Year() < 2026 {
+				return nil, repository.ErrVersionNotFound
+			}
+			return &testAuthorVersions()[1], nil
+		},
+	}
+	handler := NewAuthorHandler(&MockAuthorRepository{})
+	handler.EnableHistory(history)
+	router := mux.NewRouter()
+	handler.DefineAuthors(router)
+
+	testCases := []struct {
+		name                 string
+		path                 string
+		expectedStatusCode   int
+		expectedBodyContains []string
+	}{
+		{
+			name:                 "deve listar as versões com as mudanças",
+			path:                 "/authors/3/history",
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: []string{"<h2>Neil Gaiman</h2>", "name: Neil → Neil Gaiman", `action="/authors/3/history/1/revert"`},
+		},
+		{
+			name:                 "deve exibir a versão em vigor na data",
+			path:                 "/authors/3/history?at=2026-05-04T18:30",
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: []string{"versão 1: Neil"},
+		},
+		{
+			name:                 "deve avisar quando o autor ainda não existia",
+			path:                 "/authors/3/history?at=2020-01-01T00:00",
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: []string{"O autor ainda não existia nessa data"},
+		},
+		{
+			name:                 "deve retornar 400 para uma data inválida",
+			path:                 "/authors/3/history?at=ontem",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: []string{"Data inválida"},
+		},
+		{
+			name:                 "deve retornar 404 para um autor sem histórico",
+			path:                 "/authors/9/history",
+			expectedStatusCode:   http.StatusNotFound,
+			expectedBodyContains: []string{"Autor não encontrado"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, signedIn(httptest.NewRequest("GET", tc.path, nil)))
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			for _, expected := range tc.expectedBodyContains {
+				if !strings.Contains(rr.Body.String(), expected) {
+					t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+				}
+			}
+		})
+	}
+
+	if expected := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local); !requestedAt.Equal(expected) {
+		t.Errorf("handler consultou a data errada: got %v want %v", requestedAt, expected)
+	}
+
+	t.Run("deve exibir o histórico sem a reversão para quem não gerencia autores", func(t *testing.T) {
+		for _, req := range []*http.Request{
+			httptest.NewRequest("GET", "/authors/3/history", nil),
+			signedInAs(httptest.NewRequest("GET", "/authors/3/history", nil), auth.RoleMember),
+		} {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != http.StatusOK {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
+			}
+			if body := rr.Body.String(); !strings.Contains(body, "name: Neil → Neil Gaiman") || strings.Contains(body, "/revert") {
+				t.Errorf("esperava as versões sem o botão de reverter: %q", body)
+			}
+		}
+	})
+}
+
+func TestRevertAuthor(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		path                 string
+		repoErr              error
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{name: "deve reverter o autor", path: "/authors/3/history/1/revert", expectedStatusCode: http.StatusOK, expectedBodyContains: "Autor revertido para a versão 1"},
+		{name: "deve retornar 409 para um nome em uso", path: "/authors/3/history/1/revert", repoErr: repository.ErrRevertNameInUse, expectedStatusCode: http.StatusConflict, expectedBodyContains: "Outro autor já usa o nome"},
+		{name: "deve retornar 404 para uma versão inexistente", path: "/authors/3/history/7/revert", repoErr: repository.ErrVersionNotFound, expectedStatusCode: http.StatusNotFound, expectedBodyContains: "Versão não encontrada"},
+		{name: "deve retornar 404 para um autor removido", path: "/authors/3/history/1/revert", repoErr: repository.ErrHistoryRecordNotFound, expectedStatusCode: http.StatusNotFound, expectedBodyContains: "Autor não encontrado"},
+		{name: "deve retornar 400 para uma versão inválida", path: "/authors/3/history/abc/revert", expectedStatusCode: http.StatusBadRequest, expectedBodyContains: "Versão inválida"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			history := &MockHistoryRepository{
+				RevertToVersionFunc: func(ctx context.Context, entityType string, id int64, version int) error {
+					if entityType != domain.AuditEntityAuthor || id != 3 {
+						t.Errorf("handler reverteu o registro errado: got %s %d", entityType, id)
+					}
+					return tc.repoErr
+				},
+			}
+			handler := NewAuthorHandler(&MockAuthorRepository{})
+			handler.EnableHistory(history)
+			router := mux.NewRouter()
+			handler.DefineAuthors(router)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, signedIn(httptest.NewRequest("POST", tc.path, nil)))
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+
+	t.Run("deve exigir a permissão de gerenciar autores", func(t *testing.T) {
+		history := &MockHistoryRepository{
+			RevertToVersionFunc: func(ctx context.Context, entityType string, id int64, version int) error {
+				t.Error("a reversão não deveria ser feita sem a permissão")
+				return nil
+			},
+		}
+		handler := NewAuthorHandler(&MockAuthorRepository{})
+		handler.EnableHistory(history)
+		router := mux.NewRouter()
+		handler.DefineAuthors(router)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, signedInAs(httptest.NewRequest("POST", "/authors/3/history/1/revert", nil), auth.RoleMember))
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusForbidden)
+		}
+	})
+}
@@ -3,9 +3,10 @@ package handlers
 import (
 	"errors"
 	"fmt"
-	"log"
+	"lucienne/internal/auth"
 	"lucienne/internal/domain"
 	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
 	"lucienne/pkg/renderer"
 	"net/http"
 	"strconv"
@@ -16,7 +17,8 @@ import (
 
 // AuthorHandler agrupa os handlers relacionados a autores e suas dependências.
 type AuthorHandler struct {
-	repo repository.AuthorRepository
+	repo    repository.AuthorRepository
+	history repository.HistoryRepository
 }
 
 type AuthorsPageData struct {
@@ -28,22 +30,39 @@ func NewAuthorHandler(repo repository.AuthorRepository) *AuthorHandler {
 	return &AuthorHandler{repo: repo}
 }
 
+// EnableHistory ativa a página com as versões de cada autor e a reversão para uma versão anterior. Deve ser
+// chamado antes de DefineAuthors.
+func (h *AuthorHandler) EnableHistory(history repository.HistoryRepository) {
+	h.history = history
+}
+
 // DefineAuthors registra as rotas de autor no roteador.
 func (h *AuthorHandler) DefineAuthors(router *mux.Router) {
 	router.HandleFunc("/authors", h.ListAuthors).Methods("GET")
-	router.HandleFunc("/authors/new", h.NewAuthorForm).Methods("GET")
-	router.HandleFunc("/authors/{id}/edit", h.EditAuthor).Methods("GET")
-	router.HandleFunc("/authors/{id}", h.UpdateAuthor).Methods("PUT", "POST")
-	router.HandleFunc("/authors", h.CreateAuthorHandler).Methods("POST")
-	router.HandleFunc("/authors/{id}", h.RemoveAuthor).Methods("DELETE")
+	if h.history != nil {
+		// O histórico pode ser consultado por todos, como a lista; só a reversão exige a permissão de gerenciar.
+		router.HandleFunc("/authors/{id}/history", h.AuthorHistory).Methods("GET")
+	}
+
+	// As páginas e rotas que alteram o catálogo exigem a permissão de gerenciar autores.
+	editing := router.NewRoute().Subrouter()
+	editing.Use(middleware.RequirePermission(auth.PermissionManageAuthors))
+	editing.HandleFunc("/authors/new", h.NewAuthorForm).Methods("GET")
+	editing.HandleFunc("/authors/{id}/edit", h.EditAuthor).Methods("GET")
+	editing.HandleFunc("/authors/{id}/delete", h.DeleteAuthorConfirmation).Methods("GET")
+	editing.HandleFunc("/authors/{id}", h.UpdateAuthor).Methods("PUT")
+	editing.HandleFunc("/authors", h.CreateAuthorHandler).Methods("POST")
+	editing.HandleFunc("/authors/{id}", h.RemoveAuthor).Methods("DELETE")
+	if h.history != nil {
+		editing.HandleFunc("/authors/{id}/history/{version}/revert", h.RevertAuthor).Methods("POST")
+	}
 }
 
 // ListAuthors exibe a lista de todos os autores.
 func (h *AuthorHandler) ListAuthors(w http.ResponseWriter, r *http.Request) {
 	authors, err := h.repo.GetAuthors(r.Context())
 	if err != nil {
-		log.Printf("Erro inesperado ao listar autores: %v", err)
-		http.Error(w, "Erro interno ao listar autores", http.StatusInternalServerError)
+		serverError(w, r, "Erro interno ao listar autores", err)
 		return
 	}
 
@@ -51,9 +70,9 @@ func (h *AuthorHandler) ListAuthors(w http.ResponseWriter, r *http.Request) {
 		Authors: authors,
 	}
 
-	page, err := renderer.HTML.Render("authors/index.html", data)
+	page, err := renderer.HTML.Render(r.Context(), "authors/index.html", data)
 	if err != nil {
-		http.Error(w, "Erro ao renderizar a página", http.StatusInternalServerError)
+		serverError(w, r, "Erro ao renderizar a página", err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -76,13 +95,40 @@ func (h *AuthorHandler) EditAuthor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err != nil {
-		http.Error(w, "Erro ao buscar autor", http.StatusInternalServerError)
+		serverError(w, r, "Erro ao buscar autor", err)
+		return
+	}
+
+	page, err := renderer.HTML.Render(r.Context(), "authors/edit.html", author)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar template", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+}
+
+// DeleteAuthorConfirmation exibe a página que confirma a remoção de um autor.
+func (h *AuthorHandler) DeleteAuthorConfirmation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	author, err := h.repo.GetAuthorByID(r.Context(), id)
+	if errors.Is(err, repository.ErrAuthorNotFound) {
+		http.Error(w, "Autor não encontrado", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro ao buscar autor", err)
 		return
 	}
 
-	page, err := renderer.HTML.Render("authors/edit.html", author)
+	page, err := renderer.HTML.Render(r.Context(), "authors/delete.html", author)
 	if err != nil {
-		http.Error(w, "Erro ao renderizar template", http.StatusInternalServerError)
+		serverError(w, r, "Erro ao renderizar a página", err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -91,10 +137,9 @@ func (h *AuthorHandler) EditAuthor(w http.ResponseWriter, r *http.Request) {
 
 // NewAuthorForm exibe o formulário para criar um novo autor.
 func (h *AuthorHandler) NewAuthorForm(w http.ResponseWriter, r *http.Request) {
-	page, err := renderer.HTML.Render("authors/new.html", nil)
+	page, err := renderer.HTML.Render(r.Context(), "authors/new.html", nil)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Ocorreu um erro ao renderizar a página"))
+		serverError(w, r, "Ocorreu um erro ao renderizar a página", err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -131,7 +176,7 @@ func (h *AuthorHandler) UpdateAuthor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		http.Error(w, "Erro ao atualizar autor", http.StatusInternalServerError)
+		serverError(w, r, "Erro ao atualizar autor", err)
 		return
 	}
 
@@ -166,8 +211,7 @@ func (h *AuthorHandler) CreateAuthorHandler(w http.ResponseWriter, r *http.Reque
 			http.Error(w, errorMessage, http.StatusConflict)
 			return
 		}
-		log.Printf("Erro inesperado ao criar autor: %v", err)
-		http.Error(w, "Erro interno ao criar autor", http.StatusInternalServerError)
+		serverError(w, r, "Erro interno ao criar autor", err)
 		return
 	}
 
@@ -196,8 +240,7 @@ func (h *AuthorHandler) RemoveAuthor(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		log.Printf("Erro inesperado ao remover autor: %v", err)
-		http.Error(w, "Erro interno ao remover autor", http.StatusInternalServerError)
+		serverError(w, r, "Erro interno ao remover autor", err)
 		return
 	}
 
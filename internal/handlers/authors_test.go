@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"lucienne/internal/auth"
 	"lucienne/internal/domain"
 	"lucienne/internal/infra/repository" // Importado para usar o erro customizado
+	"lucienne/internal/middleware"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -71,7 +73,7 @@ func TestNewAuthorForm(t *testing.T) {
 		router := mux.NewRouter()
 		handler.DefineAuthors(router)
 
-		req := httptest.NewRequest("GET", "/authors/new", nil)
+		req := signedIn(httptest.NewRequest("GET", "/authors/new", nil))
 		rr := httptest.NewRecorder()
 
 		router.ServeHTTP(rr, req)
@@ -218,7 +220,7 @@ func TestCreateAuthorHandler(t *testing.T) {
 			formData := url.Values{}
 			formData.Set("name", tc.formName)
 
-			req := httptest.NewRequest("POST", "/authors", strings.NewReader(formData.Encode()))
+			req := signedIn(httptest.NewRequest("POST", "/authors", strings.NewReader(formData.Encode())))
 			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 			rr := httptest.NewRecorder()
 
@@ -309,7 +311,7 @@ func TestUpdateAuthorHandler(t *testing.T) {
 			formData := url.Values{}
 			formData.Set("name", tc.formName)
 
-			req := httptest.NewRequest("PUT", fmt.Sprintf("/authors/%s", tc.authorID), strings.NewReader(formData.Encode()))
+			req := signedIn(httptest.NewRequest("PUT", fmt.Sprintf("/authors/%s", tc.authorID), strings.NewReader(formData.Encode())))
 			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 			rr := httptest.NewRecorder()
 
@@ -340,7 +342,7 @@ func TestEditAuthorHandler(t *testing.T) {
 		router := mux.NewRouter()
 		handler.DefineAuthors(router)
 
-		req := httptest.NewRequest("GET", "/authors/1/edit", nil)
+		req := signedIn(httptest.NewRequest("GET", "/authors/1/edit", nil))
 		rr := httptest.NewRecorder()
 		router.ServeHTTP(rr, req)
 
@@ -368,7 +370,7 @@ func TestEditAuthorHandler(t *testing.T) {
 		router := mux.NewRouter()
 		handler.DefineAuthors(router)
 
-		req := httptest.NewRequest("GET", "/authors/999/edit", nil)
+		req := signedIn(httptest.NewRequest("GET", "/authors/999/edit", nil))
 		rr := httptest.NewRecorder()
 		router.ServeHTTP(rr, req)
 
@@ -386,7 +388,7 @@ func TestEditAuthorHandler(t *testing.T) {
 		router := mux.NewRouter()
 		handler.DefineAuthors(router)
 
-		req := httptest.NewRequest("GET", "/authors/abc/edit", nil)
+		req := signedIn(httptest.NewRequest("GET", "/authors/abc/edit", nil))
 		rr := httptest.NewRecorder()
 		router.ServeHTTP(rr, req)
 
@@ -409,7 +411,7 @@ func TestEditAuthorHandler(t *testing.T) {
 		router := mux.NewRouter()
 		handler.DefineAuthors(router)
 
-		req := httptest.NewRequest("GET", "/authors/1/edit", nil)
+		req := signedIn(httptest.NewRequest("GET", "/authors/1/edit", nil))
 		rr := httptest.NewRecorder()
 		router.ServeHTTP(rr, req)
 
@@ -486,7 +488,7 @@ func TestRemoveAuthorHandler(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			handler := NewAuthorHandler(tc.mockRepo)
-			req := httptest.NewRequest("DELETE", fmt.Sprintf("/authors/%s", tc.authorID), nil)
+			req := signedIn(httptest.NewRequest("DELETE", fmt.Sprintf("/authors/%s", tc.authorID), nil))
 			rr := httptest.NewRecorder()
 
 			router := mux.NewRouter()
@@ -503,3 +505,140 @@ func TestRemoveAuthorHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteAuthorConfirmation(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		authorID             string
+		mockRepo             *MockAuthorRepository
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{
+			name:     "deve exibir a confirmação com o formulário de remoção",
+			authorID: "1",
+			mockRepo: &MockAuthorRepository{
+				GetAuthorByIDFunc: func(ctx context.Context, id int64) (*domain.Author, error) {
+					return &domain.Author{ID: id, Name: "Autor Teste"}, nil
+				},
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: `<input type="hidden" name="_method" value="DELETE">`,
+		},
+		{
+			name:     "deve retornar 404 se o autor não for encontrado",
+			authorID: "999",
+			mockRepo: &MockAuthorRepository{
+				GetAuthorByIDFunc: func(ctx context.Context, id int64) (*domain.Author, error) {
+					return nil, repository.ErrAuthorNotFound
+				},
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedBodyContains: "Autor não encontrado",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			NewAuthorHandler(tc.mockRepo).DefineAuthors(router)
+
+			req := signedIn(httptest.NewRequest("GET", fmt.Sprintf("/authors/%s/delete", tc.authorID), nil))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", rr.Code, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+}
+
+func TestAuthorFormsMethodOverride(t *testing.T) {
+	var updated, removed bool
+	router := mux.NewRouter()
+	NewAuthorHandler(&MockAuthorRepository{
+		UpdateAuthorFunc: func(ctx context.Context, id int, name string) error {
+			updated = true
+			return nil
+		},
+		RemoveAuthorFunc: func(ctx context.Context, id int64) error {
+			removed = true
+			return nil
+		},
+	}).DefineAuthors(router)
+	handler := middleware.MethodOverride(router)
+
+	send := func(form url.Values) int {
+		req := signedIn(httptest.NewRequest("POST", "/authors/1", strings.NewReader(form.Encode())))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if status := send(url.Values{"name": {"Novo Nome"}}); status != http.StatusMethodNotAllowed {
+		t.Errorf("esperava 405 para POST sem _method, obteve %v", status)
+	}
+	if status := send(url.Values{"_method": {"PUT"}, "name": {"Novo Nome"}}); status != http.StatusOK || !updated {
+		t.Errorf("esperava o formulário de edição atualizando o autor, obteve %v", status)
+	}
+	if status := send(url.Values{"_method": {"DELETE"}}); status != http.StatusOK || !removed {
+		t.Errorf("esperava o formulário de remoção removendo o autor, obteve %v", status)
+	}
+}
+
+func TestAuthorsPermissions(t *testing.T) {
+	mockRepo := &MockAuthorRepository{
+		GetAuthorsFunc: func(ctx context.Context) ([]domain.Author, error) {
+			return []domain.Author{{ID: 1, Name: "Autor 1"}}, nil
+		},
+	}
+	router := mux.NewRouter()
+	NewAuthorHandler(mockRepo).DefineAuthors(router)
+
+	t.Run("deve esconder as ações de quem não pode gerenciar autores", func(t *testing.T) {
+		for _, req := range []*http.Request{
+			httptest.NewRequest("GET", "/authors", nil),
+			signedInAs(httptest.NewRequest("GET", "/authors", nil), auth.RoleMember),
+		} {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != http.StatusOK {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
+			}
+			for _, hidden := range []string{`href="/authors/new"`, `href="/authors/1/edit"`, `href="/authors/1/delete"`} {
+				if strings.Contains(rr.Body.String(), hidden) {
+					t.Errorf("a página não deveria conter %q: %q", hidden, rr.Body.String())
+				}
+			}
+		}
+	})
+
+	t.Run("deve exibir as ações para o bibliotecário", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, signedIn(httptest.NewRequest("GET", "/authors", nil)))
+
+		for _, expected := range []string{`href="/authors/new"`, `href="/authors/1/edit"`, `href="/authors/1/delete"`} {
+			if !strings.Contains(rr.Body.String(), expected) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+			}
+		}
+	})
+
+	t.Run("deve responder 403 ao membro que tenta editar", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, signedInAs(httptest.NewRequest("GET", "/authors/new", nil), auth.RoleMember))
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusForbidden)
+		}
+		if !strings.Contains(rr.Body.String(), "Acesso negado") {
+			t.Errorf("esperava a página de acesso negado, obteve %q", rr.Body.String())
+		}
+	})
+}
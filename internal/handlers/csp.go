@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxCSPReportSize limita o corpo dos relatórios, já que a rota é pública.
+const maxCSPReportSize = 64 << 10
+
+// cspViolation reúne os campos relevantes dos dois formatos de relatório (report-uri e Reporting API).
+type cspViolation struct {
+	DocumentURI        string `json:"document-uri"`
+	DocumentURL        string `json:"documentURL"`
+	ViolatedDirective  string `json:"violated-directive"`
+	EffectiveDirective string `json:"effectiveDirective"`
+	BlockedURI         string `json:"blocked-uri"`
+	BlockedURL         string `json:"blockedURL"`
+	Disposition        string `json:"disposition"`
+}
+
+// CSPHandler recebe os relatórios de violação da Content Security Policy enviados pelos navegadores.
+type CSPHandler struct {
+	reportURI string
+}
+
+// NewCSPHandler cria uma nova instância do CSPHandler que atende os relatórios enviados para reportURI,
+// o mesmo endereço informado na política.
+func NewCSPHandler(reportURI string) *CSPHandler {
+	return &CSPHandler{reportURI: reportURI}
+}
+
+// DefineCSP registra a rota de relatórios de CSP no roteador. Nada é registrado quando os relatórios
+// estão desativados ou são enviados para um coletor externo (URL absoluta).
+func (h *CSPHandler) DefineCSP(router *mux.Router) {
+	if !strings.HasPrefix(h.reportURI, "/") || strings.HasPrefix(h.reportURI, "//") {
+		return
+	}
+	router.HandleFunc(h.reportURI, h.Report).Methods("POST")
+}
+
+// Report registra no log as violações recebidas. Relatórios inválidos são ignorados.
+func (h *CSPHandler) Report(w http.ResponseWriter, r *http.Request) {
+	violations, err := decodeCSPReport(http.MaxBytesReader(w, r.Body, maxCSPReportSize), r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, "Relatório inválido", http.StatusBadRequest)
+		return
+	}
+
+	for _, violation := range violations {
+		slog.WarnContext(r.Context(), "violação de CSP",
+			"document", firstNonEmpty(violation.DocumentURI, violation.DocumentURL),
+			"directive", firstNonEmpty(violation.ViolatedDirective, violation.EffectiveDirective),
+			"blocked", firstNonEmpty(violation.BlockedURI, violation.BlockedURL),
+			"disposition", violation.Disposition,
+		)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeCSPReport lê tanto o formato application/csp-report ({"csp-report": {...}}) quanto o
+// application/reports+json da Reporting API ([{"type": "csp-violation", "body": {...}}]).
+func decodeCSPReport(body io.Reader, contentType string) ([]cspViolation, error) {
+	if strings.HasPrefix(contentType, "application/reports+json") {
+		var reports []struct {
+			Type string       `json:"type"`
+			Body cspViolation `json:"body"`
+		}
+		if err := json.NewDecoder(body).Decode(&reports); err != nil {
+			return nil, err
+		}
+		var violations []cspViolation
+		for _, report := range reports {
+			if report.Type == "csp-violation" {
+				violations = append(violations, report.Body)
+			}
+		}
+		return violations, nil
+	}
+
+	var report struct {
+		Violation cspViolation `json:"csp-report"`
+	}
+	if err := json.NewDecoder(body).Decode(&report); err != nil {
+		return nil, err
+	}
+	return []cspViolation{report.Violation}, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"log/slog"
+	"lucienne/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCSPReport(t *testing.T) {
+	var output bytes.Buffer
+	log, _ := logger.New(&output, logger.FormatText, "info")
+	previous := slog.Default()
+	slog.SetDefault(log)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	router := mux.NewRouter()
+	NewCSPHandler("/csp-report").DefineCSP(router)
+
+	testCases := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedLog    string
+	}{
+		{
+			name:           "registra um relatório report-uri",
+			contentType:    "application/csp-report",
+			body:           `{"csp-report": {"document-uri": "http://localhost/authors", "violated-directive": "script-src-elem", "blocked-uri": "inline"}}`,
+			expectedStatus: http.StatusNoContent,
+			expectedLog:    "directive=script-src-elem blocked=inline",
+		},
+		{
+			name:           "registra um relatório da Reporting API",
+			contentType:    "application/reports+json",
+			body:           `[{"type": "csp-violation", "body": {"documentURL": "http://localhost/", "effectiveDirective": "style-src-elem", "blockedURL": "https://cdn.example.com/a.css"}}]`,
+			expectedStatus: http.StatusNoContent,
+			expectedLog:    "directive=style-src-elem blocked=https://cdn.example.com/a.css",
+		},
+		{
+			name:           "recusa um corpo inválido",
+			contentType:    "application/csp-report",
+			body:           `não é json`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output.Reset()
+			req := httptest.NewRequest("POST", "/csp-report", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler retornou status inesperado: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if !strings.Contains(output.String(), tc.expectedLog) {
+				t.Errorf("esperava %q no log, obteve %q", tc.expectedLog, output.String())
+			}
+		})
+	}
+}
+
+func TestCSPReportURI(t *testing.T) {
+	testCases := []struct {
+		name           string
+		reportURI      string
+		path           string
+		expectedStatus int
+	}{
+		{"atende o endereço configurado", "/seguranca/csp", "/seguranca/csp", http.StatusNoContent},
+		{"não atende o endereço padrão quando outro é configurado", "/seguranca/csp", "/csp-report", http.StatusNotFound},
+		{"não registra rota para um coletor externo", "https://coletor.example.com/csp", "/csp-report", http.StatusNotFound},
+		{"não registra rota com os relatórios desativados", "", "/csp-report", http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			NewCSPHandler(tc.reportURI).DefineCSP(router)
+
+			req := httptest.NewRequest("POST", tc.path, strings.NewReader(`{"csp-report": {}}`))
+			req.Header.Set("Content-Type", "application/csp-report")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler retornou status inesperado: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// serverError registra o erro com o contexto da requisição (ID da requisição e trace) e responde 500 com a mensagem.
+func serverError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	slog.ErrorContext(r.Context(), message, "error", err, "method", r.Method, "path", r.URL.Path)
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// apiServerError é o equivalente de serverError para as rotas JSON.
+func apiServerError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	slog.ErrorContext(r.Context(), message, "error", err, "method", r.Method, "path", r.URL.Path)
+	writeAPIError(w, http.StatusInternalServerError, "internal_error", message)
+}
+
+// writeAPIError responde com o erro em JSON, no mesmo formato usado pelos middlewares da API.
+func writeAPIError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, map[string]string{"error": code, "message": message})
+}
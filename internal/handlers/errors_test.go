@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"lucienne/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServerError(t *testing.T) {
+	var output bytes.Buffer
+	log, _ := logger.New(&output, logger.FormatText, "info")
+	previous := slog.Default()
+	slog.SetDefault(log)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	req := httptest.NewRequest("GET", "/authors", nil)
+	req = req.WithContext(logger.WithRequestID(req.Context(), "req-99"))
+	rr := httptest.NewRecorder()
+
+	serverError(rr, req, "Erro interno ao listar autores", errors.New("conexão recusada"))
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("handler retornou status inesperado: got %v want %v", rr.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(rr.Body.String(), "conexão recusada") {
+		t.Errorf("o erro interno não deve ser exposto na resposta: %q", rr.Body.String())
+	}
+	for _, expected := range []string{"request_id=req-99", `error="conexão recusada"`, "path=/authors"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("esperava %q no log, obteve %q", expected, output.String())
+		}
+	}
+}
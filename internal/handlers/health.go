@@ -1,16 +1,65 @@
 package handlers
 
 import (
+	"encoding/json"
+	"log/slog"
+	"lucienne/pkg/health"
 	"net/http"
 
 	"github.com/gorilla/mux"
 )
 
-func ReturnHealth(router *mux.Router) {
-	router.HandleFunc("/health", HealthHandler).Methods("GET")
+// HealthHandler expõe as verificações de liveness e readiness da aplicação.
+type HealthHandler struct {
+	registry *health.Registry
 }
 
-func HealthHandler(w http.ResponseWriter, r *http.Request) {
+// NewHealthHandler cria uma nova instância do HealthHandler com o registro de verificações usado na readiness.
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// DefineHealth registra as rotas de saúde no roteador.
+func (h *HealthHandler) DefineHealth(router *mux.Router) {
+	router.HandleFunc("/health", h.Health).Methods("GET")
+	router.HandleFunc("/health/live", h.Live).Methods("GET")
+	router.HandleFunc("/health/ready", h.Ready).Methods("GET")
+}
+
+// Health mantém a resposta em texto da rota /health original.
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("HEALTH OK"))
 }
+
+// Live indica que o processo está de pé. Não verifica dependências, para o orquestrador não reiniciar a aplicação
+// quando apenas o banco de dados estiver indisponível.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, health.Report{Status: health.StatusOK, Checks: []health.Result{}})
+}
+
+// Ready executa as verificações registradas e responde 503 quando alguma verificação crítica falha. A rota é
+// pública, então os erros das verificações vão apenas para o log, e a resposta traz só a situação de cada uma.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.registry.Run(r.Context())
+	for i, result := range report.Checks {
+		if result.Error == "" {
+			continue
+		}
+		slog.WarnContext(r.Context(), "verificação de readiness falhou", "check", result.Name, "critical", result.Critical, "error", result.Error)
+		report.Checks[i].Error = ""
+	}
+
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"lucienne/pkg/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHealthHandler(t *testing.T) {
+	testCases := []struct {
+		name               string
+		path               string
+		checkErr           error
+		expectedStatusCode int
+		expectedStatus     string
+		expectedChecks     int
+	}{
+		{
+			name:               "liveness não depende das verificações",
+			path:               "/health/live",
+			checkErr:           errors.New("banco fora do ar"),
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     health.StatusOK,
+			expectedChecks:     0,
+		},
+		{
+			name:               "readiness retorna 200 quando as verificações passam",
+			path:               "/health/ready",
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     health.StatusOK,
+			expectedChecks:     1,
+		},
+		{
+			name:               "readiness retorna 503 quando uma verificação crítica falha",
+			path:               "/health/ready",
+			checkErr:           errors.New("banco fora do ar"),
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus:     health.StatusFail,
+			expectedChecks:     1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := health.NewRegistry()
+			registry.Register(health.Check{Name: "database", Critical: true, Run: func(ctx context.Context) error {
+				return tc.checkErr
+			}})
+			router := mux.NewRouter()
+			NewHealthHandler(registry).DefineHealth(router)
+
+			req := httptest.NewRequest("GET", tc.path, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", rr.Code, tc.expectedStatusCode)
+			}
+
+			var report health.Report
+			if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+				t.Fatalf("resposta não é um JSON válido: %v", err)
+			}
+			if report.Status != tc.expectedStatus || len(report.Checks) != tc.expectedChecks {
+				t.Errorf("relatório inesperado: %+v", report)
+			}
+			if tc.expectedChecks > 0 && (report.Checks[0].Name != "database" || report.Checks[0].Error != "") {
+				t.Errorf("esperava só a situação da verificação, sem o erro: %+v", report.Checks[0])
+			}
+		})
+	}
+
+	t.Run("mantém a rota /health em texto", func(t *testing.T) {
+		router := mux.NewRouter()
+		NewHealthHandler(health.NewRegistry()).DefineHealth(router)
+
+		req := httptest.NewRequest("GET", "/health", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK || rr.Body.String() != "HEALTH OK" {
+			t.Errorf("resposta inesperada: %d %q", rr.Code, rr.Body.String())
+		}
+	})
+}
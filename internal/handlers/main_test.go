@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"io/fs"
 	"lucienne/config"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/middleware"
 	"lucienne/pkg/renderer"
+	"net/http"
 	"os"
-	"path/filepath"
 	"testing"
 )
 
@@ -12,11 +17,29 @@ func TestMain(m *testing.M) {
 	// Configura as dependências necessárias para os handlers deste pacote antes de rodar os testes.
 	// Isso garante que os testes sejam autocontidos e não dependam da inicialização do pacote main.
 	config.Application.Configure("test")
-	viewsPath := filepath.Join(config.Application.RootPath, "internal", "views")
-	renderer.HTML.Configure("", viewsPath, nil)
+	views, err := fs.Sub(config.Application.FS, "internal/views")
+	if err != nil {
+		panic(err)
+	}
+	renderer.HTML.Configure("", views, nil)
+	renderer.HTML.AddContextFunc("cspNonce", func(ctx context.Context, _ ...string) any { return middleware.CSPNonce(ctx) })
+	renderer.HTML.AddContextFunc("csrfField", func(ctx context.Context, _ ...string) any { return middleware.CSRFField(ctx) })
+	renderer.HTML.AddContextFunc("can", func(ctx context.Context, args ...string) any { return len(args) == 1 && auth.Can(ctx, args[0]) })
 
 	// Roda todos os testes do pacote
 	exitCode := m.Run()
 
 	os.Exit(exitCode)
 }
+
+// signedIn retorna a requisição como se feita por um bibliotecário autenticado, para as rotas que exigem login
+// e permissão de gerenciar o catálogo.
+func signedIn(req *http.Request) *http.Request {
+	return signedInAs(req, auth.RoleLibrarian, auth.PermissionManageAuthors, auth.PermissionManagePublishers, auth.PermissionManageBooks)
+}
+
+// signedInAs retorna a requisição como se feita por um usuário com o papel e as permissões informados.
+func signedInAs(req *http.Request, role string, permissions ...string) *http.Request {
+	user := &domain.User{ID: 1, Name: "Usuário de Teste", Email: "teste@example.com", Roles: []string{role}, Permissions: permissions}
+	return req.WithContext(auth.WithUser(req.Context(), user))
+}
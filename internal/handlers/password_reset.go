@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"lucienne/internal/auth"
+	"lucienne/pkg/renderer"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// PasswordResetHandler agrupa os handlers de redefinição de senha por email.
+type PasswordResetHandler struct {
+	accounts *auth.AccountTokens
+}
+
+// PasswordResetPageData reúne os dados das páginas de redefinição de senha.
+type PasswordResetPageData struct {
+	Email string
+	Token string
+	// Sent indica que o pedido foi recebido e o link, enviado se o email estiver cadastrado.
+	Sent  bool
+	Error string
+}
+
+// NewPasswordResetHandler cria uma nova instância do PasswordResetHandler com suas dependências.
+func NewPasswordResetHandler(accounts *auth.AccountTokens) *PasswordResetHandler {
+	return &PasswordResetHandler{accounts: accounts}
+}
+
+// DefinePasswordReset registra as rotas de redefinição de senha no roteador.
+func (h *PasswordResetHandler) DefinePasswordReset(router *mux.Router) {
+	router.HandleFunc("/password/forgot", h.ForgotForm).Methods("GET")
+	router.HandleFunc("/password/forgot", h.Forgot).Methods("POST")
+	router.HandleFunc("/password/reset", h.ResetForm).Methods("GET")
+	router.HandleFunc("/password/reset", h.Reset).Methods("POST")
+}
+
+// ForgotForm exibe o formulário que pede o link de redefinição.
+func (h *PasswordResetHandler) ForgotForm(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, "users/forgot_password.html", http.StatusOK, PasswordResetPageData{})
+}
+
+// Forgot envia o link de redefinição. A resposta é a mesma para emails cadastrados ou não.
+func (h *PasswordResetHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	data := PasswordResetPageData{Email: r.FormValue("email")}
+	if _, err := auth.NormalizeEmail(data.Email); err != nil {
+		data.Error = "Email inválido"
+		h.render(w, r, "users/forgot_password.html", http.StatusBadRequest, data)
+		return
+	}
+	if err := h.accounts.RequestPasswordReset(r.Context(), data.Email); err != nil {
+		serverError(w, r, "Erro interno ao enviar o link de redefinição", err)
+		return
+	}
+
+	data.Sent = true
+	h.render(w, r, "users/forgot_password.html", http.StatusOK, data)
+}
+
+// ResetForm exibe o formulário da nova senha, se o link ainda for válido.
+func (h *PasswordResetHandler) ResetForm(w http.ResponseWriter, r *http.Request) {
+	data := PasswordResetPageData{Token: r.URL.Query().Get("token")}
+	err := h.accounts.CheckPasswordReset(r.Context(), data.Token)
+	if errors.Is(err, auth.ErrInvalidUserToken) {
+		data.Token, data.Error = "", "Link de redefinição inválido ou expirado. Peça um novo link."
+		h.render(w, r, "users/reset_password.html", http.StatusBadRequest, data)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro interno ao verificar o link de redefinição", err)
+		return
+	}
+
+	h.render(w, r, "users/reset_password.html", http.StatusOK, data)
+}
+
+// Reset troca a senha com o link de redefinição e encerra as sessões do usuário.
+func (h *PasswordResetHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	data := PasswordResetPageData{Token: r.FormValue("token")}
+	if r.FormValue("password") != r.FormValue("password_confirmation") {
+		data.Error = "As senhas não conferem"
+		h.render(w, r, "users/reset_password.html", http.StatusBadRequest, data)
+		return
+	}
+
+	err := h.accounts.ResetPassword(r.Context(), data.Token, r.FormValue("password"))
+	switch {
+	case errors.Is(err, auth.ErrWeakPassword):
+		data.Error = fmt.Sprintf("A senha deve ter entre %d e %d caracteres", auth.MinPasswordLength, auth.MaxPasswordLength)
+		h.render(w, r, "users/reset_password.html", http.StatusBadRequest, data)
+		return
+	case errors.Is(err, auth.ErrInvalidUserToken):
+		data.Token, data.Error = "", "Link de redefinição inválido ou expirado. Peça um novo link."
+		h.render(w, r, "users/reset_password.html", http.StatusBadRequest, data)
+		return
+	case err != nil:
+		serverError(w, r, "Erro interno ao redefinir a senha", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Senha redefinida com sucesso. Entre com a nova senha. \n"))
+}
+
+func (h *PasswordResetHandler) render(w http.ResponseWriter, r *http.Request, view string, status int, data PasswordResetPageData) {
+	page, err := renderer.HTML.Render(r.Context(), view, data)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(page)
+}
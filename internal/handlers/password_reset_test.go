@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/mailer"
+	"lucienne/pkg/password"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MockUserTokenRepository é a implementação falsa do UserTokenRepository para testes.
+type MockUserTokenRepository struct {
+	CreateUserTokenFunc  func(ctx context.Context, token *domain.UserToken) error
+	GetUserTokenFunc     func(ctx context.Context, purpose string, hash string) (*domain.UserToken, error)
+	ConsumeUserTokenFunc func(ctx context.Context, purpose string, hash string) (*domain.UserToken, error)
+	ResetPasswordFunc    func(ctx context.Context, hash string, passwordHash string) (*domain.UserToken, error)
+}
+
+func (m *MockUserTokenRepository) CreateUserToken(ctx context.Context, token *domain.UserToken) error {
+	if m.CreateUserTokenFunc != nil {
+		return m.CreateUserTokenFunc(ctx, token)
+	}
+	return nil
+}
+
+func (m *MockUserTokenRepository) GetUserToken(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+	if m.GetUserTokenFunc != nil {
+		return m.GetUserTokenFunc(ctx, purpose, hash)
+	}
+	return nil, repository.ErrUserTokenNotFound
+}
+
+func (m *MockUserTokenRepository) ConsumeUserToken(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+	if m.ConsumeUserTokenFunc != nil {
+		return m.ConsumeUserTokenFunc(ctx, purpose, hash)
+	}
+	return nil, repository.ErrUserTokenNotFound
+}
+
+func (m *MockUserTokenRepository) ResetPassword(ctx context.Context, hash string, passwordHash string) (*domain.UserToken, error) {
+	if m.ResetPasswordFunc != nil {
+		return m.ResetPasswordFunc(ctx, hash, passwordHash)
+	}
+	return nil, repository.ErrUserTokenNotFound
+}
+
+// recordingMailer guarda as mensagens em vez de enviá-las.
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// wait espera até n mensagens serem enviadas, por no máximo um segundo, e retorna as mensagens enviadas.
+func (m *recordingMailer) wait(n int) []mailer.Message {
+	deadline := time.Now().Add(time.Second)
+	for {
+		m.mu.Lock()
+		messages := append([]mailer.Message(nil), m.messages...)
+		m.mu.Unlock()
+		if len(messages) >= n || time.Now().After(deadline) {
+			return messages
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// link retorna o token do link da última mensagem enviada.
+func (m *recordingMailer) link() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.messages) == 0 {
+		return ""
+	}
+	match := regexp.MustCompile(`\?token=(\S+)`).FindStringSubmatch(m.messages[len(m.messages)-1].Text)
+	if match == nil {
+		return ""
+	}
+	token, _ := url.QueryUnescape(match[1])
+	return token
+}
+
+func newTestAccounts(users repository.UserRepository, tokens repository.UserTokenRepository) (*auth.AccountTokens, *recordingMailer) {
+	mail := &recordingMailer{}
+	return auth.NewAccountTokens(users, tokens, mail, auth.AccountTokenOptions{
+		BaseURL:              "http://lucienne.test",
+		PasswordResetTTL:     time.Hour,
+		EmailVerificationTTL: 48 * time.Hour,
+	}), mail
+}
+
+func TestForgotPassword(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		email                string
+		expectedStatusCode   int
+		expectedBodyContains string
+		expectedLookup       bool
+		expectedMessages     int
+	}{
+		{name: "deve enviar o link para um email cadastrado", email: "ana@example.com", expectedStatusCode: http.StatusOK, expectedBodyContains: "você receberá um link", expectedLookup: true, expectedMessages: 1},
+		{name: "deve responder igual para um email não cadastrado", email: "bia@example.com", expectedStatusCode: http.StatusOK, expectedBodyContains: "você receberá um link", expectedLookup: true, expectedMessages: 0},
+		{name: "deve recusar um email inválido", email: "ana", expectedStatusCode: http.StatusBadRequest, expectedBodyContains: "Email inválido", expectedMessages: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// O email é buscado depois da resposta, por accounts.Run; looked avisa quando a busca terminou.
+			looked := make(chan struct{}, 1)
+			users := &MockUserRepository{
+				GetUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+					defer func() { looked <- struct{}{} }()
+					if email != "ana@example.com" {
+						return nil, repository.ErrUserNotFound
+					}
+					return &domain.User{ID: 1, Name: "Ana", Email: email, PasswordHash: "hash"}, nil
+				},
+			}
+			var created *domain.UserToken
+			accounts, mail := newTestAccounts(users, &MockUserTokenRepository{
+				CreateUserTokenFunc: func(ctx context.Context, token *domain.UserToken) error {
+					created = token
+					return nil
+				},
+			})
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			go accounts.Run(ctx)
+			router := mux.NewRouter()
+			NewPasswordResetHandler(accounts).DefinePasswordReset(router)
+
+			form := url.Values{"email": {tc.email}}
+			req := httptest.NewRequest("POST", "/password/forgot", strings.NewReader(form.Encode()))
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if tc.expectedLookup {
+				select {
+				case <-looked:
+				case <-time.After(time.Second):
+					t.Fatal("esperava o email buscado depois da resposta")
+				}
+			}
+			messages := mail.wait(tc.expectedMessages)
+			if len(messages) != tc.expectedMessages {
+				t.Fatalf("esperava %d emails, obteve %d", tc.expectedMessages, len(messages))
+			}
+			if tc.expectedMessages == 0 {
+				return
+			}
+
+			msg := messages[0]
+			if msg.To != `"Ana" <ana@example.com>` || !strings.Contains(msg.Text, "http://lucienne.test/password/reset?token=") {
+				t.Errorf("email inesperado: %+v", msg)
+			}
+			if plain := mail.link(); auth.HashAPIToken(plain) != created.TokenHash || created.Purpose != domain.TokenPurposePasswordReset {
+				t.Errorf("o link não corresponde ao token guardado: %q, %+v", plain, created)
+			}
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		form                 url.Values
+		reset                func(ctx context.Context, hash string, passwordHash string) (*domain.UserToken, error)
+		expectedStatusCode   int
+		expectedBodyContains string
+		expectedReset        bool
+	}{
+		{
+			name: "deve trocar a senha e encerrar as sessões",
+			form: url.Values{"token": {"abc"}, "password": {"uma nova senha longa"}, "password_confirmation": {"uma nova senha longa"}},
+			reset: func(ctx context.Context, hash string, passwordHash string) (*domain.UserToken, error) {
+				return &domain.UserToken{UserID: 7, Purpose: domain.TokenPurposePasswordReset}, nil
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: "Senha redefinida com sucesso",
+			expectedReset:        true,
+		},
+		{
+			name: "deve recusar um link usado ou expirado",
+			form: url.Values{"token": {"abc"}, "password": {"uma nova senha longa"}, "password_confirmation": {"uma nova senha longa"}},
+			reset: func(ctx context.Context, hash string, passwordHash string) (*domain.UserToken, error) {
+				return nil, repository.ErrUserTokenNotFound
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "Link de redefinição inválido ou expirado",
+		},
+		{
+			name:                 "deve recusar uma senha curta sem usar o link",
+			form:                 url.Values{"token": {"abc"}, "password": {"curta"}, "password_confirmation": {"curta"}},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "A senha deve ter entre 12 e 128 caracteres",
+		},
+		{
+			name:                 "deve recusar senhas que não conferem",
+			form:                 url.Values{"token": {"abc"}, "password": {"uma nova senha longa"}, "password_confirmation": {"outra senha longa"}},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "As senhas não conferem",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var saved string
+			tokens := &MockUserTokenRepository{
+				ResetPasswordFunc: func(ctx context.Context, hash string, passwordHash string) (*domain.UserToken, error) {
+					if tc.reset == nil {
+						return nil, errors.New("o link não deveria ser usado")
+					}
+					saved = passwordHash
+					return tc.reset(ctx, hash, passwordHash)
+				},
+			}
+			accounts, _ := newTestAccounts(&MockUserRepository{}, tokens)
+			router := mux.NewRouter()
+			NewPasswordResetHandler(accounts).DefinePasswordReset(router)
+
+			req := httptest.NewRequest("POST", "/password/reset", strings.NewReader(tc.form.Encode()))
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if !tc.expectedReset {
+				return
+			}
+			if match, _, _ := password.Verify("uma nova senha longa", saved); !match {
+				t.Errorf("a nova senha não foi salva: %q", saved)
+			}
+		})
+	}
+}
+
+func TestResetPasswordForm(t *testing.T) {
+	tokens := &MockUserTokenRepository{
+		GetUserTokenFunc: func(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+			if hash != auth.HashAPIToken("valido") {
+				return nil, repository.ErrUserTokenNotFound
+			}
+			return &domain.UserToken{UserID: 1, Purpose: purpose}, nil
+		},
+	}
+	accounts, _ := newTestAccounts(&MockUserRepository{}, tokens)
+	router := mux.NewRouter()
+	NewPasswordResetHandler(accounts).DefinePasswordReset(router)
+
+	for token, expected := range map[string]string{
+		"valido":   `<input type="hidden" name="token" value="valido">`,
+		"expirado": "Link de redefinição inválido ou expirado",
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/password/reset?token="+token, nil))
+
+		if !strings.Contains(rr.Body.String(), expected) {
+			t.Errorf("%s: handler retornou corpo inesperado: got %q want to contain %q", token, rr.Body.String(), expected)
+		}
+	}
+}
@@ -3,11 +3,13 @@ package handlers
 import (
 	"errors"
 	"fmt"
-	"log"
+	"lucienne/internal/auth"
 	"lucienne/internal/domain"
 	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
 	"lucienne/pkg/renderer"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -25,8 +27,37 @@ func NewPublisherHandler(repo repository.PublisherRepository) *PublisherHandler
 
 // DefinePublishers registra as rotas de publisher no roteador.
 func (h *PublisherHandler) DefinePublishers(router *mux.Router) {
-	router.HandleFunc("/publishers", h.CreatePublisherHandler).Methods("POST")
-	router.HandleFunc("/publishers/new", h.NewPublisherForm).Methods("GET")
+	router.HandleFunc("/publishers", h.ListPublishers).Methods("GET")
+
+	// As páginas e rotas que alteram o catálogo exigem a permissão de gerenciar editoras.
+	editing := router.NewRoute().Subrouter()
+	editing.Use(middleware.RequirePermission(auth.PermissionManagePublishers))
+	editing.HandleFunc("/publishers", h.CreatePublisherHandler).Methods("POST")
+	editing.HandleFunc("/publishers/new", h.NewPublisherForm).Methods("GET")
+	editing.HandleFunc("/publishers/{id}/delete", h.DeletePublisherConfirmation).Methods("GET")
+	editing.HandleFunc("/publishers/{id}", h.RemovePublisher).Methods("DELETE")
+}
+
+// PublishersPageData reúne os dados da página de listagem de editoras.
+type PublishersPageData struct {
+	Publishers []domain.Publisher
+}
+
+// ListPublishers exibe a lista de todas as editoras.
+func (h *PublisherHandler) ListPublishers(w http.ResponseWriter, r *http.Request) {
+	publishers, err := h.repo.GetPublishers(r.Context())
+	if err != nil {
+		serverError(w, r, "Erro interno ao listar editoras", err)
+		return
+	}
+
+	page, err := renderer.HTML.Render(r.Context(), "publishers/index.html", PublishersPageData{Publishers: publishers})
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
 }
 
 func (h *PublisherHandler) CreatePublisherHandler(w http.ResponseWriter, r *http.Request) {
@@ -57,8 +88,7 @@ func (h *PublisherHandler) CreatePublisherHandler(w http.ResponseWriter, r *http
 			http.Error(w, errorMessage, http.StatusConflict)
 			return
 		}
-		log.Printf("Erro inesperado ao criar editora: %v", err)
-		http.Error(w, "Erro interno ao criar editora", http.StatusInternalServerError)
+		serverError(w, r, "Erro interno ao criar editora", err)
 		return
 	}
 
@@ -68,12 +98,61 @@ func (h *PublisherHandler) CreatePublisherHandler(w http.ResponseWriter, r *http
 }
 
 func (h *PublisherHandler) NewPublisherForm(w http.ResponseWriter, r *http.Request) {
-	page, err := renderer.HTML.Render("publishers/new.html", nil)
+	page, err := renderer.HTML.Render(r.Context(), "publishers/new.html", nil)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+}
+
+// DeletePublisherConfirmation exibe a página que confirma a remoção de uma editora.
+func (h *PublisherHandler) DeletePublisherConfirmation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
 	if err != nil {
-		http.Error(w, "Erro ao renderizar a página", http.StatusInternalServerError)
+		http.Error(w, "ID inválido", http.StatusBadRequest)
 		return
 	}
 
+	publisher, err := h.repo.GetPublisherByID(r.Context(), id)
+	if errors.Is(err, repository.ErrPublisherNotFound) {
+		http.Error(w, "Editora não encontrada", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro ao buscar editora", err)
+		return
+	}
+
+	page, err := renderer.HTML.Render(r.Context(), "publishers/delete.html", publisher)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 	w.Write(page)
 }
+
+// RemovePublisher remove uma editora.
+func (h *PublisherHandler) RemovePublisher(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	err = h.repo.RemovePublisher(r.Context(), id)
+	if errors.Is(err, repository.ErrPublisherNotFound) {
+		http.Error(w, "Editora não encontrada", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro interno ao remover editora", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Editora removida com sucesso \n"))
+}
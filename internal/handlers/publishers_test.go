@@ -5,9 +5,11 @@ import (
 	"errors"
 	"lucienne/internal/domain"
 	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -16,7 +18,10 @@ import (
 
 // MockPublisherRepository é a nossa implementação falsa do repositório para testes.
 type MockPublisherRepository struct {
-	CreatePublisherFunc func(ctx context.Context, Publisher *domain.Publisher) error
+	CreatePublisherFunc  func(ctx context.Context, Publisher *domain.Publisher) error
+	GetPublishersFunc    func(ctx context.Context) ([]domain.Publisher, error)
+	GetPublisherByIDFunc func(ctx context.Context, id int64) (*domain.Publisher, error)
+	RemovePublisherFunc  func(ctx context.Context, id int64) error
 }
 
 // Implementamos os métodos da interface PublisherRepository.
@@ -27,22 +32,52 @@ func (m *MockPublisherRepository) CreatePublisher(ctx context.Context, Publisher
 	return nil
 }
 
+func (m *MockPublisherRepository) GetPublishers(ctx context.Context) ([]domain.Publisher, error) {
+	if m.GetPublishersFunc != nil {
+		return m.GetPublishersFunc(ctx)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func (m *MockPublisherRepository) GetPublisherByID(ctx context.Context, id int64) (*domain.Publisher, error) {
+	if m.GetPublisherByIDFunc != nil {
+		return m.GetPublisherByIDFunc(ctx, id)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func (m *MockPublisherRepository) RemovePublisher(ctx context.Context, id int64) error {
+	if m.RemovePublisherFunc != nil {
+		return m.RemovePublisherFunc(ctx, id)
+	}
+	return errors.New("não implementado no mock")
+}
+
 func TestNewPublisherForm(t *testing.T) {
 	handler := NewPublisherHandler(nil)
 	router := mux.NewRouter()
 	handler.DefinePublishers(router)
 
-	req := httptest.NewRequest("GET", "/publishers/new", nil)
+	req := signedIn(httptest.NewRequest("GET", "/publishers/new", nil))
 	rr := httptest.NewRecorder()
 
-	router.ServeHTTP(rr, req)
+	// O middleware de CSRF fornece o token incluído no formulário.
+	middleware.CSRF(middleware.CSRFOptions{})(router).ServeHTTP(rr, req)
 
 	if status := rr.Code; status != http.StatusOK {
 		t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
 	}
 
+	// O token muda a cada resposta, então é conferido à parte e o resto do formulário é comparado por inteiro.
+	csrfInput := regexp.MustCompile(`<input type="hidden" name="csrf_token" value="([A-Za-z0-9_-]+)">`)
+	match := csrfInput.FindStringSubmatch(rr.Body.String())
+	if match == nil {
+		t.Fatalf("esperava o formulário com o token CSRF preenchido: %q", rr.Body.String())
+	}
+
 	expectedBody := `<h1>Criar Editora</h1>
     <form action="/publishers" method="post">
+        <input type="hidden" name="csrf_token" value="` + match[1] + `">
         <label for="name">Nome</label>
         <input type="text" id="name" name="name" required>
         <button type="submit">Criar</button>
@@ -131,3 +166,145 @@ func TestCreatePublisherHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestListPublishers(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		mockRepo             *MockPublisherRepository
+		expectedStatusCode   int
+		expectedBodyContains []string
+	}{
+		{
+			name: "deve listar as editoras com o link de remoção",
+			mockRepo: &MockPublisherRepository{
+				GetPublishersFunc: func(ctx context.Context) ([]domain.Publisher, error) {
+					return []domain.Publisher{{ID: 1, Name: "Editora 1"}, {ID: 2, Name: "Editora 2"}}, nil
+				},
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: []string{"Editora 1", "Editora 2", `href="/publishers/2/delete"`},
+		},
+		{
+			name: "deve exibir uma mensagem quando não há editoras",
+			mockRepo: &MockPublisherRepository{
+				GetPublishersFunc: func(ctx context.Context) ([]domain.Publisher, error) {
+					return []domain.Publisher{}, nil
+				},
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: []string{"Nenhuma editora encontrada"},
+		},
+		{
+			name: "deve retornar 500 se o repositório falhar",
+			mockRepo: &MockPublisherRepository{
+				GetPublishersFunc: func(ctx context.Context) ([]domain.Publisher, error) {
+					return nil, repository.ErrSearchPublishers
+				},
+			},
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedBodyContains: []string{"Erro interno ao listar editoras"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			NewPublisherHandler(tc.mockRepo).DefinePublishers(router)
+
+			req := signedIn(httptest.NewRequest("GET", "/publishers", nil))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", rr.Code, tc.expectedStatusCode)
+			}
+			for _, expected := range tc.expectedBodyContains {
+				if !strings.Contains(rr.Body.String(), expected) {
+					t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRemovePublisher(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		method               string
+		path                 string
+		mockRepo             *MockPublisherRepository
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{
+			name:   "deve exibir a confirmação de remoção",
+			method: "GET",
+			path:   "/publishers/1/delete",
+			mockRepo: &MockPublisherRepository{
+				GetPublisherByIDFunc: func(ctx context.Context, id int64) (*domain.Publisher, error) {
+					return &domain.Publisher{ID: id, Name: "Editora Teste"}, nil
+				},
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: `<input type="hidden" name="_method" value="DELETE">`,
+		},
+		{
+			name:   "deve retornar 404 na confirmação de uma editora inexistente",
+			method: "GET",
+			path:   "/publishers/999/delete",
+			mockRepo: &MockPublisherRepository{
+				GetPublisherByIDFunc: func(ctx context.Context, id int64) (*domain.Publisher, error) {
+					return nil, repository.ErrPublisherNotFound
+				},
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedBodyContains: "Editora não encontrada",
+		},
+		{
+			name:   "deve remover a editora",
+			method: "DELETE",
+			path:   "/publishers/1",
+			mockRepo: &MockPublisherRepository{
+				RemovePublisherFunc: func(ctx context.Context, id int64) error { return nil },
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: "Editora removida com sucesso",
+		},
+		{
+			name:   "deve retornar 404 ao remover uma editora inexistente",
+			method: "DELETE",
+			path:   "/publishers/999",
+			mockRepo: &MockPublisherRepository{
+				RemovePublisherFunc: func(ctx context.Context, id int64) error { return repository.ErrPublisherNotFound },
+			},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedBodyContains: "Editora não encontrada",
+		},
+		{
+			name:                 "deve retornar 400 se o ID for inválido",
+			method:               "DELETE",
+			path:                 "/publishers/abc",
+			mockRepo:             &MockPublisherRepository{},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "ID inválido",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			NewPublisherHandler(tc.mockRepo).DefinePublishers(router)
+
+			req := signedIn(httptest.NewRequest(tc.method, tc.path, nil))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", rr.Code, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+}
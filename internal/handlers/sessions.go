@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"lucienne/internal/auth"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"lucienne/pkg/renderer"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gorilla/mux"
+)
+
+// SessionHandler agrupa os handlers de login e logout.
+type SessionHandler struct {
+	users     repository.UserRepository
+	sessions  *auth.SessionManager
+	sso       *auth.OIDCProvider
+	twoFactor *auth.TwoFactor
+	guard     *auth.LoginGuard
+	// trustProxy identifica o IP do cliente por X-Forwarded-For, como no rate limit.
+	trustProxy bool
+}
+
+// LoginPageData reúne os dados do formulário de login.
+type LoginPageData struct {
+	Email string
+	Next  string
+	Error string
+	// SingleSignOn é o nome do provedor de identidade, vazio quando o login único está desativado.
+	SingleSignOn string
+}
+
+// NewSessionHandler cria uma nova instância do SessionHandler com suas dependências.
+func NewSessionHandler(users repository.UserRepository, sessions *auth.SessionManager) *SessionHandler {
+	return &SessionHandler{users: users, sessions: sessions}
+}
+
+// EnableSingleSignOn ativa o login pelo provedor de identidade. Deve ser chamado antes de DefineSessions.
+func (h *SessionHandler) EnableSingleSignOn(provider *auth.OIDCProvider) {
+	h.sso = provider
+}
+
+// EnableTwoFactor pede o código da verificação em duas etapas, depois da senha, aos usuários que a ativaram.
+// Deve ser chamado antes de DefineSessions.
+func (h *SessionHandler) EnableTwoFactor(twoFactor *auth.TwoFactor) {
+	h.twoFactor = twoFactor
+}
+
+// EnableLoginGuard conta as falhas de login por conta e por IP, recusando as tentativas enquanto a conta ou o
+// IP estiver atrasado ou bloqueado. Deve ser chamado antes de DefineSessions.
+func (h *SessionHandler) EnableLoginGuard(guard *auth.LoginGuard, trustProxy bool) {
+	h.guard = guard
+	h.trustProxy = trustProxy
+}
+
+// DefineSessions registra as rotas de login e logout no roteador.
+func (h *SessionHandler) DefineSessions(router *mux.Router) {
+	router.HandleFunc("/login", h.LoginForm).Methods("GET")
+	router.HandleFunc("/login", h.Login).Methods("POST")
+	router.HandleFunc("/logout", h.Logout).Methods("POST")
+
+	if h.twoFactor != nil {
+		router.HandleFunc("/login/2fa", h.TwoFactorForm).Methods("GET")
+		router.HandleFunc("/login/2fa", h.TwoFactorLogin).Methods("POST")
+	}
+	if h.sso != nil {
+		router.HandleFunc("/login/oidc", h.SingleSignOn).Methods("GET")
+		router.HandleFunc("/login/oidc/callback", h.SingleSignOnCallback).Methods("GET")
+	}
+}
+
+// LoginForm exibe o formulário de login.
+func (h *SessionHandler) LoginForm(w http.ResponseWriter, r *http.Request) {
+	h.renderLogin(w, r, http.StatusOK, LoginPageData{Next: safeRedirect(r.URL.Query().Get("next"))})
+}
+
+// Login confere o email e a senha e inicia uma nova sessão, redirecionando para a página pedida antes do login.
+// Quem ativou a verificação em duas etapas é levado antes ao formulário do código.
+func (h *SessionHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	data := LoginPageData{Email: r.FormValue("email"), Next: safeRedirect(r.FormValue("next"))}
+	attempt, ok := h.beginLoginAttempt(w, r, data.Email, func(message string) {
+		data.Error = message
+		h.renderLogin(w, r, http.StatusTooManyRequests, data)
+	})
+	if !ok {
+		return
+	}
+
+	user, err := auth.Authenticate(r.Context(), h.users, data.Email, r.FormValue("password"))
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		h.loginFailed(r, attempt)
+		data.Error = "Email ou senha inválidos"
+		h.renderLogin(w, r, http.StatusUnauthorized, data)
+		return
+	}
+	if err != nil {
+		h.releaseLoginAttempt(r, attempt)
+		serverError(w, r, "Erro interno ao entrar", err)
+		return
+	}
+
+	// Com a verificação em duas etapas, as falhas só são esquecidas depois do código, para que quem descobriu a
+	// senha não possa testar códigos sem limite.
+	if h.twoFactor != nil && user.TwoFactorEnabled {
+		h.releaseLoginAttempt(r, attempt)
+		if err := h.twoFactor.BeginLogin(w, r, user); err != nil {
+			serverError(w, r, "Erro interno ao entrar", err)
+			return
+		}
+		http.Redirect(w, r, "/login/2fa?next="+url.QueryEscape(data.Next), http.StatusSeeOther)
+		return
+	}
+
+	h.loginSucceeded(r, attempt)
+	if err := h.sessions.Login(w, r, user); err != nil {
+		serverError(w, r, "Erro interno ao iniciar a sessão", err)
+		return
+	}
+	http.Redirect(w, r, data.Next, http.StatusSeeOther)
+}
+
+// Logout encerra a sessão atual.
+func (h *SessionHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.sessions.Logout(w, r); err != nil {
+		serverError(w, r, "Erro interno ao sair", err)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (h *SessionHandler) renderLogin(w http.ResponseWriter, r *http.Request, status int, data LoginPageData) {
+	if h.sso != nil {
+		data.SingleSignOn = h.sso.Name()
+	}
+	page, err := renderer.HTML.Render(r.Context(), "sessions/login.html", data)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(page)
+}
+
+// beginLoginAttempt reserva a tentativa de login, recusando-a enquanto a conta ou o IP estiver atrasado ou
+// bloqueado, chamando reject com a mensagem para o usuário. Sem a proteção configurada, a tentativa é nil.
+func (h *SessionHandler) beginLoginAttempt(w http.ResponseWriter, r *http.Request, email string, reject func(message string)) (*auth.LoginAttempt, bool) {
+	if h.guard == nil {
+		return nil, true
+	}
+
+	attempt, err := h.guard.Begin(r.Context(), email, middleware.ClientIP(r, h.trustProxy))
+	var throttled *auth.LoginThrottledError
+	if errors.As(err, &throttled) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		if throttled.Locked {
+			reject("Muitas tentativas com a senha errada. O login está bloqueado por " + formatWait(throttled.RetryAfter) + ".")
+		} else {
+			reject("Muitas tentativas com a senha errada. Aguarde " + formatWait(throttled.RetryAfter) + " para tentar de novo.")
+		}
+		return nil, false
+	}
+	if err != nil {
+		serverError(w, r, "Erro interno ao entrar", err)
+		return nil, false
+	}
+	return attempt, true
+}
+
+// loginFailed confirma a falha de login, já contada na reserva.
+func (h *SessionHandler) loginFailed(r *http.Request, attempt *auth.LoginAttempt) {
+	if attempt != nil {
+		attempt.Failed(r.Context())
+	}
+}
+
+// releaseLoginAttempt desfaz a reserva de uma tentativa que não errou a senha nem o código. Um erro ao desfazê-la
+// não muda a resposta e é apenas registrado no log.
+func (h *SessionHandler) releaseLoginAttempt(r *http.Request, attempt *auth.LoginAttempt) {
+	if attempt == nil {
+		return
+	}
+	if err := attempt.Release(r.Context()); err != nil {
+		slog.ErrorContext(r.Context(), "erro ao desfazer a reserva da tentativa de login", "error", err)
+	}
+}
+
+// loginSucceeded esquece as falhas de login da conta.
+func (h *SessionHandler) loginSucceeded(r *http.Request, attempt *auth.LoginAttempt) {
+	if attempt == nil {
+		return
+	}
+	if err := attempt.Succeeded(r.Context()); err != nil {
+		slog.ErrorContext(r.Context(), "erro ao limpar as falhas de login", "error", err)
+	}
+}
+
+// formatWait escreve a espera em segundos ou, a partir de um minuto, em minutos arredondados para cima.
+func formatWait(wait time.Duration) string {
+	if wait < time.Minute {
+		seconds := int(math.Ceil(wait.Seconds()))
+		if seconds == 1 {
+			return "1 segundo"
+		}
+		return strconv.Itoa(seconds) + " segundos"
+	}
+	minutes := int(math.Ceil(wait.Minutes()))
+	if minutes == 1 {
+		return "1 minuto"
+	}
+	return strconv.Itoa(minutes) + " minutos"
+}
+
+// safeRedirect aceita apenas caminhos da própria aplicação, para que o parâmetro next não seja usado
+// para levar o usuário a outro site depois do login (open redirect). Barras invertidas e caracteres de
+// controle são recusados em qualquer posição, já que os navegadores descartam tabs e quebras de linha e
+// tratam \ como /, o que transformaria "/\t/evil.com" em "//evil.com".
+func safeRedirect(next string) string {
+	if strings.ContainsFunc(next, func(r rune) bool { return r == '\\' || unicode.IsControl(r) }) {
+		return "/"
+	}
+	target, err := url.Parse(next)
+	if err != nil || target.Scheme != "" || target.Host != "" || !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		return "/"
+	}
+	return next
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/password"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MockSessionRepository é a implementação falsa do SessionRepository para testes.
+type MockSessionRepository struct {
+	CreateSessionFunc      func(ctx context.Context, session *domain.Session) error
+	GetSessionFunc         func(ctx context.Context, id string) (*domain.Session, error)
+	TouchSessionFunc       func(ctx context.Context, id string, lastSeenAt time.Time) error
+	DeleteSessionFunc      func(ctx context.Context, id string) error
+	DeleteUserSessionsFunc func(ctx context.Context, userID int64) error
+}
+
+func (m *MockSessionRepository) CreateSession(ctx context.Context, session *domain.Session) error {
+	if m.CreateSessionFunc != nil {
+		return m.CreateSessionFunc(ctx, session)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func (m *MockSessionRepository) GetSession(ctx context.Context, id string) (*domain.Session, error) {
+	if m.GetSessionFunc != nil {
+		return m.GetSessionFunc(ctx, id)
+	}
+	return nil, repository.ErrSessionNotFound
+}
+
+func (m *MockSessionRepository) TouchSession(ctx context.Context, id string, lastSeenAt time.Time) error {
+	if m.TouchSessionFunc != nil {
+		return m.TouchSessionFunc(ctx, id, lastSeenAt)
+	}
+	return nil
+}
+
+func (m *MockSessionRepository) DeleteSession(ctx context.Context, id string) error {
+	if m.DeleteSessionFunc != nil {
+		return m.DeleteSessionFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockSessionRepository) DeleteUserSessions(ctx context.Context, userID int64) error {
+	if m.DeleteUserSessionsFunc != nil {
+		return m.DeleteUserSessionsFunc(ctx, userID)
+	}
+	return nil
+}
+
+func TestLogin(t *testing.T) {
+	hash, err := password.Hash("uma senha bem longa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &MockUserRepository{
+		GetUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+			if email != "ana@example.com" {
+				return nil, repository.ErrUserNotFound
+			}
+			return &domain.User{ID: 1, Email: email, PasswordHash: hash}, nil
+		},
+	}
+
+	testCases := []struct {
+		name                 string
+		form                 url.Values
+		expectedStatusCode   int
+		expectedLocation     string
+		expectedBodyContains string
+		expectedSession      bool
+	}{
+		{
+			name:               "deve entrar e voltar para a página pedida",
+			form:               url.Values{"email": {"ana@example.com"}, "password": {"uma senha bem longa"}, "next": {"/authors/new"}},
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "/authors/new",
+			expectedSession:    true,
+		},
+		{
+			name:               "deve ignorar um next para outro site",
+			form:               url.Values{"email": {"ana@example.com"}, "password": {"uma senha bem longa"}, "next": {"//evil.example.com"}},
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "/",
+			expectedSession:    true,
+		},
+		{
+			name:               "deve ignorar um next com tab antes da segunda barra",
+			form:               url.Values{"email": {"ana@example.com"}, "password": {"uma senha bem longa"}, "next": {"/\t/evil.example.com"}},
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "/",
+			expectedSession:    true,
+		},
+		{
+			name:                 "deve recusar a senha errada",
+			form:                 url.Values{"email": {"ana@example.com"}, "password": {"senha errada!!"}},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedBodyContains: "Email ou senha inválidos",
+		},
+		{
+			name:                 "deve recusar um email desconhecido com a mesma mensagem",
+			form:                 url.Values{"email": {"bia@example.com"}, "password": {"uma senha bem longa"}},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedBodyContains: "Email ou senha inválidos",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var created *domain.Session
+			sessions := auth.NewSessionManager(&MockSessionRepository{
+				CreateSessionFunc: func(ctx context.Context, session *domain.Session) error {
+					created = session
+					return nil
+				},
+			}, users, auth.SessionOptions{IdleTimeout: time.Hour, Lifetime: time.Hour})
+			router := mux.NewRouter()
+			NewSessionHandler(users, sessions).DefineSessions(router)
+
+			req := httptest.NewRequest("POST", "/login", strings.NewReader(tc.form.Encode()))
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if location := rr.Header().Get("Location"); location != tc.expectedLocation {
+				t.Errorf("handler redirecionou para o lugar errado: got %q want %q", location, tc.expectedLocation)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if (created != nil) != tc.expectedSession {
+				t.Errorf("sessão criada: got %v want %v", created != nil, tc.expectedSession)
+			}
+		})
+	}
+}
+
+func TestLoginForm(t *testing.T) {
+	router := mux.NewRouter()
+	NewSessionHandler(nil, nil).DefineSessions(router)
+
+	req := httptest.NewRequest("GET", "/login?next=%2Fpublishers%2Fnew", nil)
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
+	}
+	expected := `<input type="hidden" name="next" value="/publishers/new">`
+	if !strings.Contains(rr.Body.String(), expected) {
+		t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+	}
+}
+
+func TestLoginWithLoginGuard(t *testing.T) {
+	hash, err := password.Hash("uma senha bem longa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name                 string
+		form                 url.Values
+		throttle             *domain.LoginThrottle
+		expectedStatusCode   int
+		expectedBodyContains string
+		expectedRetryAfter   string
+		expectedReserved     []string
+		expectedReleased     []string
+		expectedCleared      bool
+	}{
+		{
+			name:                 "deve recusar sem conferir a senha enquanto a conta estiver bloqueada",
+			form:                 url.Values{"email": {"ana@example.com"}, "password": {"uma senha bem longa"}},
+			throttle:             &domain.LoginThrottle{LockedUntil: timePtr(time.Now().Add(10 * time.Minute)), LockedAt: timePtr(time.Now())},
+			expectedStatusCode:   http.StatusTooManyRequests,
+			expectedBodyContains: "O login está bloqueado por 10 minutos",
+			expectedRetryAfter:   "600",
+		},
+		{
+			name:                 "deve pedir para aguardar durante o atraso",
+			form:                 url.Values{"email": {"ana@example.com"}, "password": {"uma senha bem longa"}},
+			throttle:             &domain.LoginThrottle{LockedUntil: timePtr(time.Now().Add(4 * time.Second))},
+			expectedStatusCode:   http.StatusTooManyRequests,
+			expectedBodyContains: "Aguarde 4 segundos",
+			expectedRetryAfter:   "4",
+		},
+		{
+			name:                 "deve manter a falha reservada da conta e do IP",
+			form:                 url.Values{"email": {"Ana@Example.com"}, "password": {"senha errada!!"}},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedBodyContains: "Email ou senha inválidos",
+			expectedReserved:     []string{"account:ana@example.com", "ip:192.0.2.1"},
+		},
+		{
+			name:               "deve esquecer as falhas da conta depois do login",
+			form:               url.Values{"email": {"ana@example.com"}, "password": {"uma senha bem longa"}},
+			throttle:           &domain.LoginThrottle{Failures: 2},
+			expectedStatusCode: http.StatusSeeOther,
+			expectedReserved:   []string{"account:ana@example.com", "ip:192.0.2.1"},
+			expectedReleased:   []string{"ip:192.0.2.1"},
+			expectedCleared:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checked := false
+			users := &MockUserRepository{
+				GetUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+					checked = true
+					return &domain.User{ID: 1, Email: "ana@example.com", PasswordHash: hash}, nil
+				},
+			}
+			var reserved, released []string
+			cleared := false
+			throttles := &MockLoginThrottleRepository{
+				ReserveLoginAttemptFunc: func(ctx context.Context, scope string, subject string, ip string, at time.Time, policy domain.LoginThrottlePolicy) (*domain.LoginThrottle, error) {
+					if tc.throttle != nil && scope == domain.LoginThrottleAccount && tc.throttle.Throttled(at) {
+						return tc.throttle, repository.ErrLoginThrottled
+					}
+					reserved = append(reserved, scope+":"+subject)
+					return &domain.LoginThrottle{ID: int64(len(reserved)), Scope: scope, Subject: subject, Failures: 1, LastFailureAt: at}, nil
+				},
+				ReleaseLoginAttemptFunc: func(ctx context.Context, id int64, at time.Time) error {
+					released = append(released, reserved[id-1])
+					return nil
+				},
+				ClearLoginThrottleFunc: func(ctx context.Context, scope string, subject string) error {
+					cleared = scope == domain.LoginThrottleAccount && subject == "ana@example.com"
+					return nil
+				},
+			}
+			sessions := auth.NewSessionManager(&MockSessionRepository{
+				CreateSessionFunc: func(ctx context.Context, session *domain.Session) error { return nil },
+			}, users, auth.SessionOptions{IdleTimeout: time.Hour, Lifetime: time.Hour})
+			handler := NewSessionHandler(users, sessions)
+			handler.EnableLoginGuard(newTestLoginGuard(throttles, users), false)
+			router := mux.NewRouter()
+			handler.DefineSessions(router)
+
+			req := httptest.NewRequest("POST", "/login", strings.NewReader(tc.form.Encode()))
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if retryAfter := rr.Header().Get("Retry-After"); retryAfter != tc.expectedRetryAfter {
+				t.Errorf("Retry-After: got %q want %q", retryAfter, tc.expectedRetryAfter)
+			}
+			if tc.expectedStatusCode == http.StatusTooManyRequests && checked {
+				t.Error("a senha não deveria ser conferida durante o bloqueio")
+			}
+			if strings.Join(reserved, ",") != strings.Join(tc.expectedReserved, ",") {
+				t.Errorf("tentativas reservadas: got %v want %v", reserved, tc.expectedReserved)
+			}
+			if strings.Join(released, ",") != strings.Join(tc.expectedReleased, ",") {
+				t.Errorf("reservas desfeitas: got %v want %v", released, tc.expectedReleased)
+			}
+			if cleared != tc.expectedCleared {
+				t.Errorf("falhas esquecidas: got %v want %v", cleared, tc.expectedCleared)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestSafeRedirect(t *testing.T) {
+	testCases := []struct {
+		next     string
+		expected string
+	}{
+		{"/authors/new", "/authors/new"},
+		{"/authors?page=2#fim", "/authors?page=2#fim"},
+		{"", "/"},
+		{"authors", "/"},
+		{"https://evil.example.com", "/"},
+		{"//evil.example.com", "/"},
+		{"/\\evil.example.com", "/"},
+		{"/authors\\..\\", "/"},
+		{"/\t/evil.example.com", "/"},
+		{"/\n/evil.example.com", "/"},
+		{"/\r\n/evil.example.com", "/"},
+		{"/authors\x00", "/"},
+	}
+
+	for _, tc := range testCases {
+		if got := safeRedirect(tc.next); got != tc.expected {
+			t.Errorf("safeRedirect(%q): got %q want %q", tc.next, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"lucienne/internal/auth"
+	"net/http"
+	"net/url"
+)
+
+// SingleSignOn inicia o login pelo provedor de identidade.
+func (h *SessionHandler) SingleSignOn(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.URL.Query().Get("next"))
+	http.Redirect(w, r, h.sso.BeginLogin(w, next), http.StatusFound)
+}
+
+// SingleSignOnCallback recebe a volta do provedor de identidade e inicia a sessão do usuário,
+// criado no primeiro login. Quem ativou a verificação em duas etapas, como uma conta com senha ligada
+// depois ao provedor, ainda precisa informar o código.
+func (h *SessionHandler) SingleSignOnCallback(w http.ResponseWriter, r *http.Request) {
+	user, next, err := h.sso.CompleteLogin(w, r)
+	switch {
+	case errors.Is(err, auth.ErrOIDCLogin):
+		slog.WarnContext(r.Context(), "login único recusado", "error", err)
+		h.renderLogin(w, r, http.StatusUnauthorized, LoginPageData{Next: "/", Error: "Não foi possível entrar pelo login único. Tente novamente."})
+		return
+	case errors.Is(err, auth.ErrOIDCEmailNotVerified), errors.Is(err, auth.ErrInvalidEmail):
+		h.renderLogin(w, r, http.StatusForbidden, LoginPageData{Next: "/", Error: "O provedor de identidade não informou um email confirmado para a sua conta."})
+		return
+	case err != nil:
+		serverError(w, r, "Erro interno ao entrar pelo login único", err)
+		return
+	}
+
+	if h.twoFactor != nil && user.TwoFactorEnabled {
+		if err := h.twoFactor.BeginLogin(w, r, user); err != nil {
+			serverError(w, r, "Erro interno ao entrar pelo login único", err)
+			return
+		}
+		http.Redirect(w, r, "/login/2fa?next="+url.QueryEscape(safeRedirect(next)), http.StatusSeeOther)
+		return
+	}
+
+	if err := h.sessions.Login(w, r, user); err != nil {
+		serverError(w, r, "Erro interno ao iniciar a sessão", err)
+		return
+	}
+	http.Redirect(w, r, safeRedirect(next), http.StatusSeeOther)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/test/test_support"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MockIdentityRepository é a implementação falsa do IdentityRepository para testes.
+type MockIdentityRepository struct {
+	GetUserByIdentityFunc func(ctx context.Context, issuer string, subject string) (*domain.User, error)
+	LinkIdentityFunc      func(ctx context.Context, userID int64, issuer string, subject string) error
+}
+
+func (m *MockIdentityRepository) GetUserByIdentity(ctx context.Context, issuer string, subject string) (*domain.User, error) {
+	if m.GetUserByIdentityFunc != nil {
+		return m.GetUserByIdentityFunc(ctx, issuer, subject)
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (m *MockIdentityRepository) LinkIdentity(ctx context.Context, userID int64, issuer string, subject string) error {
+	if m.LinkIdentityFunc != nil {
+		return m.LinkIdentityFunc(ctx, userID, issuer, subject)
+	}
+	return errors.New("não implementado no mock")
+}
+
+// newSSORouter registra as rotas de sessão com o login único apontando para o provedor de teste.
+// twoFactor é opcional.
+func newSSORouter(t *testing.T, server *test_support.OIDCServer, users *MockUserRepository, identities *MockIdentityRepository, created **domain.Session, twoFactor *auth.TwoFactor) *mux.Router {
+	t.Helper()
+	provider, err := auth.NewOIDCProvider(context.Background(), auth.OIDCOptions{
+		Name:         "Escola",
+		Issuer:       server.URL,
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		RedirectURL:  "http://lucienne.test/login/oidc/callback",
+		RolesClaim:   "groups",
+	}, users, identities, &MockRoleRepository{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := auth.NewSessionManager(&MockSessionRepository{
+		CreateSessionFunc: func(ctx context.Context, session *domain.Session) error {
+			*created = session
+			return nil
+		},
+	}, users, auth.SessionOptions{IdleTimeout: time.Hour, Lifetime: time.Hour})
+
+	handler := NewSessionHandler(users, sessions)
+	handler.EnableSingleSignOn(provider)
+	if twoFactor != nil {
+		handler.EnableTwoFactor(twoFactor)
+	}
+	router := mux.NewRouter()
+	handler.DefineSessions(router)
+	return router
+}
+
+func TestSingleSignOn(t *testing.T) {
+	server := test_support.NewOIDCServer(t)
+	server.SetClaims(map[string]any{"sub": "ana-123", "email": "ana@escola.edu", "email_verified": true, "name": "Ana"})
+
+	var createdUser *domain.User
+	users := &MockUserRepository{
+		GetUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+			return nil, repository.ErrUserNotFound
+		},
+		CreateUserFunc: func(ctx context.Context, user *domain.User) error {
+			user.ID = 9
+			createdUser = user
+			return nil
+		},
+	}
+	var linked string
+	identities := &MockIdentityRepository{
+		LinkIdentityFunc: func(ctx context.Context, userID int64, issuer string, subject string) error {
+			linked = subject
+			return nil
+		},
+	}
+	var created *domain.Session
+	router := newSSORouter(t, server, users, identities, &created, nil)
+
+	t.Run("deve exibir o botão do provedor no formulário de login", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/login?next=%2Fauthors", nil))
+
+		expected := `<a href="/login/oidc?next=%2fauthors">Entrar com Escola</a>`
+		if !strings.Contains(rr.Body.String(), expected) {
+			t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+		}
+	})
+
+	t.Run("deve criar o usuário e iniciar a sessão na volta do provedor", func(t *testing.T) {
+		start := httptest.NewRecorder()
+		router.ServeHTTP(start, httptest.NewRequest("GET", "/login/oidc?next=%2Fauthors", nil))
+		if status := start.Code; status != http.StatusFound {
+			t.Fatalf("handler retornou status code errado: got %v want %v", status, http.StatusFound)
+		}
+		authURL := start.Header().Get("Location")
+		if !strings.HasPrefix(authURL, server.URL+"/authorize?") || !strings.Contains(authURL, "code_challenge_method=S256") {
+			t.Fatalf("esperava o redirecionamento ao provedor com PKCE, obteve %q", authURL)
+		}
+
+		rr := completeSSOLogin(t, router, start)
+
+		if status := rr.Code; status != http.StatusSeeOther {
+			t.Fatalf("handler retornou status code errado: got %v want %v: %s", status, http.StatusSeeOther, rr.Body.String())
+		}
+		if location := rr.Header().Get("Location"); location != "/authors" {
+			t.Errorf("handler redirecionou para o lugar errado: got %q want %q", location, "/authors")
+		}
+		if createdUser == nil || createdUser.Email != "ana@escola.edu" || createdUser.HasPassword() || linked != "ana-123" {
+			t.Errorf("esperava o usuário criado sem senha e ligado à conta do provedor: %+v, %q", createdUser, linked)
+		}
+		if created == nil || created.UserID != 9 {
+			t.Errorf("esperava a sessão do usuário criado: %+v", created)
+		}
+	})
+
+	t.Run("deve voltar ao login quando o provedor recusa o acesso", func(t *testing.T) {
+		start := httptest.NewRecorder()
+		router.ServeHTTP(start, httptest.NewRequest("GET", "/login/oidc", nil))
+		state := strings.SplitN(strings.Split(start.Header().Get("Location"), "state=")[1], "&", 2)[0]
+
+		callback := httptest.NewRequest("GET", "/login/oidc/callback?error=access_denied&state="+state, nil)
+		for _, cookie := range start.Result().Cookies() {
+			callback.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, callback)
+
+		if status := rr.Code; status != http.StatusUnauthorized {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusUnauthorized)
+		}
+		if !strings.Contains(rr.Body.String(), "Não foi possível entrar pelo login único") {
+			t.Errorf("handler retornou corpo inesperado: %q", rr.Body.String())
+		}
+	})
+}
+
+func TestSingleSignOnWithTwoFactor(t *testing.T) {
+	server := test_support.NewOIDCServer(t)
+	server.SetClaims(map[string]any{"sub": "ana-123", "email": "ana@escola.edu", "email_verified": true, "name": "Ana"})
+
+	users := &MockUserRepository{}
+	identities := &MockIdentityRepository{
+		GetUserByIdentityFunc: func(ctx context.Context, issuer string, subject string) (*domain.User, error) {
+			return &domain.User{ID: 3, Email: "ana@escola.edu", PasswordHash: "hash", TwoFactorEnabled: true}, nil
+		},
+	}
+	var pending *domain.UserToken
+	tokens := &MockUserTokenRepository{
+		CreateUserTokenFunc: func(ctx context.Context, token *domain.UserToken) error {
+			pending = token
+			return nil
+		},
+	}
+	var created *domain.Session
+	router := newSSORouter(t, server, users, identities, &created, newTestTwoFactor(&MockTwoFactorRepository{}, tokens, users))
+
+	start := httptest.NewRecorder()
+	router.ServeHTTP(start, httptest.NewRequest("GET", "/login/oidc?next=%2Fauthors", nil))
+	rr := completeSSOLogin(t, router, start)
+
+	if status := rr.Code; status != http.StatusSeeOther {
+		t.Fatalf("handler retornou status code errado: got %v want %v: %s", status, http.StatusSeeOther, rr.Body.String())
+	}
+	if location := rr.Header().Get("Location"); location != "/login/2fa?next=%2Fauthors" {
+		t.Errorf("handler redirecionou para o lugar errado: got %q want %q", location, "/login/2fa?next=%2Fauthors")
+	}
+	if created != nil {
+		t.Error("a sessão não deveria ser criada antes do código")
+	}
+	if pending == nil || pending.UserID != 3 || pending.Purpose != domain.TokenPurposeTwoFactorLogin {
+		t.Errorf("esperava o login pendente do usuário: %+v", pending)
+	}
+}
+
+// completeSSOLogin segue o redirecionamento de start até o provedor e entrega a volta ao roteador.
+func completeSSOLogin(t *testing.T, router *mux.Router, start *httptest.ResponseRecorder) *httptest.ResponseRecorder {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(start.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	callback := httptest.NewRequest("GET", res.Header.Get("Location"), nil)
+	for _, cookie := range start.Result().Cookies() {
+		callback.AddCookie(cookie)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, callback)
+	return rr
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// trashPermissions relaciona cada tipo de registro da lixeira à permissão que o restaura e o apaga de vez.
+var trashPermissions = map[string]string{
+	domain.AuditEntityAuthor:    auth.PermissionManageAuthors,
+	domain.AuditEntityPublisher: auth.PermissionManagePublishers,
+	domain.AuditEntityBook:      auth.PermissionManageBooks,
+}
+
+// TrashHandler agrupa a lixeira de autores, editoras e livros removidos.
+type TrashHandler struct {
+	trash     repository.TrashRepository
+	retention time.Duration
+}
+
+// TrashPageData reúne os dados da página da lixeira.
+type TrashPageData struct {
+	Items []TrashEntry
+}
+
+// TrashEntry é um registro da lixeira com a data em que será apagado de vez.
+type TrashEntry struct {
+	domain.TrashItem
+	PurgeAt time.Time
+}
+
+// NewTrashHandler cria uma nova instância do TrashHandler. retention é o prazo da lixeira, usado para exibir
+// quando cada registro será apagado de vez.
+func NewTrashHandler(trash repository.TrashRepository, retention time.Duration) *TrashHandler {
+	return &TrashHandler{trash: trash, retention: retention}
+}
+
+// DefineTrash registra as rotas da lixeira. Cada registro exige a permissão de gerenciar o seu tipo.
+func (h *TrashHandler) DefineTrash(router *mux.Router) {
+	trash := router.NewRoute().Subrouter()
+	trash.Use(middleware.RequireUser)
+	trash.HandleFunc("/trash", h.ListTrash).Methods("GET")
+	trash.HandleFunc("/trash/{type}/{id}/restore", h.RestoreItem).Methods("POST")
+	trash.HandleFunc("/trash/{type}/{id}", h.PurgeItem).Methods("DELETE")
+}
+
+// ListTrash exibe os registros removidos que o usuário pode restaurar.
+func (h *TrashHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	allowed := false
+	for _, permission := range trashPermissions {
+		allowed = allowed || auth.Can(r.Context(), permission)
+	}
+	if !allowed {
+		middleware.Forbidden(w, r)
+		return
+	}
+
+	items, err := h.trash.GetTrash(r.Context())
+	if err != nil {
+		serverError(w, r, "Erro interno ao listar a lixeira", err)
+		return
+	}
+
+	data := TrashPageData{Items: []TrashEntry{}}
+	for _, item := range items {
+		if auth.Can(r.Context(), trashPermissions[item.EntityType]) {
+			data.Items = append(data.Items, TrashEntry{TrashItem: item, PurgeAt: item.DeletedAt.Add(h.retention)})
+		}
+	}
+	page, err := renderer.HTML.Render(r.Context(), "trash/index.html", data)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+}
+
+// RestoreItem tira um registro da lixeira.
+func (h *TrashHandler) RestoreItem(w http.ResponseWriter, r *http.Request) {
+	entityType, id, ok := h.trashItem(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.trash.RestoreItem(r.Context(), entityType, id)
+	if h.trashError(w, r, err, "Erro interno ao restaurar o registro") {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Registro restaurado com sucesso \n"))
+}
+
+// PurgeItem apaga de vez um registro da lixeira.
+func (h *TrashHandler) PurgeItem(w http.ResponseWriter, r *http.Request) {
+	entityType, id, ok := h.trashItem(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.trash.PurgeItem(r.Context(), entityType, id)
+	if h.trashError(w, r, err, "Erro interno ao apagar o registro") {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Registro apagado definitivamente \n"))
+}
+
+// trashItem lê o tipo e o ID do registro da rota e verifica a permissão do usuário para o tipo.
+func (h *TrashHandler) trashItem(w http.ResponseWriter, r *http.Request) (string, int64, bool) {
+	vars := mux.Vars(r)
+	permission, ok := trashPermissions[vars["type"]]
+	if !ok {
+		http.Error(w, "Registro não encontrado", http.StatusNotFound)
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return "", 0, false
+	}
+	if !auth.Can(r.Context(), permission) {
+		middleware.Forbidden(w, r)
+		return "", 0, false
+	}
+	return vars["type"], id, true
+}
+
+// trashError responde aos erros da lixeira e informa se houve erro.
+func (h *TrashHandler) trashError(w http.ResponseWriter, r *http.Request, err error, message string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, repository.ErrTrashItemNotFound):
+		http.Error(w, "Registro não encontrado na lixeira", http.StatusNotFound)
+	case errors.Is(err, repository.ErrTrashNameInUse):
+		http.Error(w, "Já existe um registro ativo com esse nome", http.StatusConflict)
+	case errors.Is(err, repository.ErrTrashAuthorDeleted):
+		http.Error(w, "O autor do livro está na lixeira; restaure-o primeiro", http.StatusUnprocessableEntity)
+	case errors.Is(err, repository.ErrTrashItemInUse):
+		http.Error(w, "O autor tem livros na lixeira; apague-os primeiro", http.StatusUnprocessableEntity)
+	default:
+		serverError(w, r, message, err)
+	}
+	return true
+}
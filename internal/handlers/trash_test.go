@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MockTrashRepository é a implementação falsa do TrashRepository para testes.
+type MockTrashRepository struct {
+	GetTrashFunc    func(ctx context.Context) ([]domain.TrashItem, error)
+	RestoreItemFunc func(ctx context.Context, entityType string, id int64) error
+	PurgeItemFunc   func(ctx context.Context, entityType string, id int64) error
+}
+
+func (m *MockTrashRepository) GetTrash(ctx context.Context) ([]domain.TrashItem, error) {
+	if m.GetTrashFunc != nil {
+		return m.GetTrashFunc(ctx)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func (m *MockTrashRepository) RestoreItem(ctx context.Context, entityType string, id int64) error {
+	if m.RestoreItemFunc != nil {
+		return m.RestoreItemFunc(ctx, entityType, id)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func (m *MockTrashRepository) PurgeItem(ctx context.Context, entityType string, id int64) error {
+	if m.PurgeItemFunc != nil {
+		return m.PurgeItemFunc(ctx, entityType, id)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func TestListTrash(t *testing.T) {
+	deletedAt := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	repo := &MockTrashRepository{
+		GetTrashFunc: func(ctx context.Context) ([]domain.TrashItem, error) {
+			return []domain.TrashItem{
+				{EntityType: domain.AuditEntityAuthor, ID: 3, Name: "Neil Gaiman", DeletedAt: deletedAt},
+				{EntityType: domain.AuditEntityPublisher, ID: 5, Name: "Rocco", DeletedAt: deletedAt},
+			}, nil
+		},
+	}
+	router := mux.NewRouter()
+	NewTrashHandler(repo, 30*24*time.Hour).DefineTrash(router)
+
+	t.Run("deve listar os registros que o usuário pode restaurar", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, signedInAs(httptest.NewRequest("GET", "/trash", nil), auth.RoleLibrarian, auth.PermissionManageAuthors))
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
+		}
+		for _, expected := range []string{"Neil Gaiman", "03/06/2026 12:00", `action="/trash/author/3/restore"`} {
+			if !strings.Contains(rr.Body.String(), expected) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+			}
+		}
+		if strings.Contains(rr.Body.String(), "Rocco") {
+			t.Errorf("handler exibiu uma editora para quem não pode gerenciar editoras: %q", rr.Body.String())
+		}
+	})
+
+	t.Run("deve recusar quem não gerencia o catálogo", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, signedInAs(httptest.NewRequest("GET", "/trash", nil), auth.RoleMember))
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusForbidden)
+		}
+	})
+}
+
+func TestRestoreTrashItem(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		path                 string
+		repoErr              error
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{name: "deve restaurar o registro", path: "/trash/author/3/restore", expectedStatusCode: http.StatusOK, expectedBodyContains: "Registro restaurado com sucesso"},
+		{name: "deve retornar 409 para um nome cadastrado de novo", path: "/trash/author/3/restore", repoErr: repository.ErrTrashNameInUse, expectedStatusCode: http.StatusConflict, expectedBodyContains: "Já existe um registro ativo"},
+		{name: "deve retornar 422 para um livro de autor removido", path: "/trash/book/4/restore", repoErr: repository.ErrTrashAuthorDeleted, expectedStatusCode: http.StatusUnprocessableEntity, expectedBodyContains: "restaure-o primeiro"},
+		{name: "deve retornar 404 para um registro fora da lixeira", path: "/trash/author/9/restore", repoErr: repository.ErrTrashItemNotFound, expectedStatusCode: http.StatusNotFound, expectedBodyContains: "Registro não encontrado"},
+		{name: "deve retornar 404 para um tipo desconhecido", path: "/trash/user/1/restore", expectedStatusCode: http.StatusNotFound, expectedBodyContains: "Registro não encontrado"},
+		{name: "deve recusar quem não gerencia o tipo", path: "/trash/publisher/5/restore", expectedStatusCode: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &MockTrashRepository{
+				RestoreItemFunc: func(ctx context.Context, entityType string, id int64) error { return tc.repoErr },
+			}
+			router := mux.NewRouter()
+			NewTrashHandler(repo, time.Hour).DefineTrash(router)
+			rr := httptest.NewRecorder()
+
+			req := httptest.NewRequest("POST", tc.path, nil)
+			router.ServeHTTP(rr, signedInAs(req, auth.RoleLibrarian, auth.PermissionManageAuthors, auth.PermissionManageBooks))
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+}
+
+func TestPurgeTrashItem(t *testing.T) {
+	var purged []string
+	repo := &MockTrashRepository{
+		PurgeItemFunc: func(ctx context.Context, entityType string, id int64) error {
+			if id == 3 {
+				return repository.ErrTrashItemInUse
+			}
+			purged = append(purged, entityType)
+			return nil
+		},
+	}
+	router := mux.NewRouter()
+	NewTrashHandler(repo, time.Hour).DefineTrash(router)
+	handler := middleware.MethodOverride(router)
+
+	testCases := []struct {
+		name                 string
+		path                 string
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{name: "deve apagar o registro de vez", path: "/trash/publisher/5", expectedStatusCode: http.StatusOK, expectedBodyContains: "Registro apagado definitivamente"},
+		{name: "deve retornar 422 para um autor com livros na lixeira", path: "/trash/author/3", expectedStatusCode: http.StatusUnprocessableEntity, expectedBodyContains: "apague-os primeiro"},
+		{name: "deve retornar 400 para um ID inválido", path: "/trash/author/abc", expectedStatusCode: http.StatusBadRequest, expectedBodyContains: "ID inválido"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			form := url.Values{middleware.MethodOverrideField: {"DELETE"}}
+			req := httptest.NewRequest("POST", tc.path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, signedInAs(req, auth.RoleAdmin, auth.PermissionManageAuthors, auth.PermissionManagePublishers))
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+	if len(purged) != 1 || purged[0] != domain.AuditEntityPublisher {
+		t.Errorf("Esperava a editora apagada, mas obteve %v", purged)
+	}
+}
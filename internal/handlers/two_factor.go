@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"lucienne/internal/auth"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"lucienne/pkg/renderer"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// TwoFactorHandler agrupa as páginas em que o usuário ativa e desativa a verificação em duas etapas.
+type TwoFactorHandler struct {
+	twoFactor *auth.TwoFactor
+}
+
+// TwoFactorPageData reúne os dados da página de verificação em duas etapas.
+type TwoFactorPageData struct {
+	Enabled bool
+	// Required informa se o papel do usuário exige a verificação, que então não pode ser desativada.
+	Required bool
+	// RecoveryCodesLeft é a quantidade de códigos de recuperação ainda não usados.
+	RecoveryCodesLeft int
+	// Secret e QRCode são exibidos durante a ativação, para cadastrar a conta no aplicativo autenticador.
+	Secret string
+	QRCode template.URL
+	// RecoveryCodes são os códigos recém-gerados, exibidos uma única vez.
+	RecoveryCodes []string
+	Error         string
+}
+
+// NewTwoFactorHandler cria uma nova instância do TwoFactorHandler com suas dependências.
+func NewTwoFactorHandler(twoFactor *auth.TwoFactor) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactor: twoFactor}
+}
+
+// DefineTwoFactor registra as rotas de verificação em duas etapas do perfil, que exigem login.
+func (h *TwoFactorHandler) DefineTwoFactor(router *mux.Router) {
+	profile := router.NewRoute().Subrouter()
+	profile.Use(middleware.RequireUser)
+	profile.HandleFunc("/profile/2fa", h.ShowTwoFactor).Methods("GET")
+	profile.HandleFunc("/profile/2fa", h.EnableTwoFactor).Methods("POST")
+	profile.HandleFunc("/profile/2fa", h.DisableTwoFactor).Methods("DELETE")
+	profile.HandleFunc("/profile/2fa/recovery-codes", h.RegenerateRecoveryCodes).Methods("POST")
+}
+
+// ShowTwoFactor exibe a situação da verificação em duas etapas ou, se ela não estiver ativada, o QR code
+// para iniciar a ativação.
+func (h *TwoFactorHandler) ShowTwoFactor(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, http.StatusOK, TwoFactorPageData{})
+}
+
+// EnableTwoFactor conclui a ativação com o código do aplicativo e exibe os códigos de recuperação.
+func (h *TwoFactorHandler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.twoFactor.ConfirmEnrollment(r.Context(), auth.CurrentUser(r.Context()), r.FormValue("code"))
+	switch {
+	case errors.Is(err, auth.ErrInvalidTwoFactorCode):
+		h.render(w, r, http.StatusBadRequest, TwoFactorPageData{Error: "Código inválido. Confira o horário do celular e tente de novo."})
+		return
+	case errors.Is(err, repository.ErrTwoFactorNotFound):
+		http.Error(w, "Abra a página de verificação em duas etapas para iniciar a ativação", http.StatusBadRequest)
+		return
+	case errors.Is(err, repository.ErrTwoFactorEnabled):
+		http.Error(w, "A verificação em duas etapas já está ativada", http.StatusConflict)
+		return
+	case err != nil:
+		serverError(w, r, "Erro interno ao ativar a verificação em duas etapas", err)
+		return
+	}
+
+	h.renderRecoveryCodes(w, r, http.StatusCreated, codes)
+}
+
+// RegenerateRecoveryCodes troca os códigos de recuperação por novos, depois de conferir um código do usuário.
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.twoFactor.RegenerateRecoveryCodes(r.Context(), auth.CurrentUser(r.Context()), r.FormValue("code"))
+	if errors.Is(err, auth.ErrInvalidTwoFactorCode) {
+		h.render(w, r, http.StatusBadRequest, TwoFactorPageData{Error: "Código inválido"})
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro interno ao gerar os códigos de recuperação", err)
+		return
+	}
+
+	h.renderRecoveryCodes(w, r, http.StatusOK, codes)
+}
+
+// DisableTwoFactor desativa a verificação em duas etapas, depois de conferir um código do usuário.
+func (h *TwoFactorHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	err := h.twoFactor.Disable(r.Context(), auth.CurrentUser(r.Context()), r.FormValue("code"))
+	switch {
+	case errors.Is(err, auth.ErrTwoFactorRequired):
+		http.Error(w, "Seu papel exige a verificação em duas etapas", http.StatusForbidden)
+		return
+	case errors.Is(err, auth.ErrInvalidTwoFactorCode):
+		h.render(w, r, http.StatusBadRequest, TwoFactorPageData{Error: "Código inválido"})
+		return
+	case err != nil:
+		serverError(w, r, "Erro interno ao desativar a verificação em duas etapas", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Verificação em duas etapas desativada \n"))
+}
+
+// renderRecoveryCodes exibe os códigos de recuperação recém-gerados, que não podem ficar em cache.
+func (h *TwoFactorHandler) renderRecoveryCodes(w http.ResponseWriter, r *http.Request, status int, codes []string) {
+	w.Header().Set("Cache-Control", "no-store")
+	h.render(w, r, status, TwoFactorPageData{RecoveryCodes: codes})
+}
+
+// render completa os dados da página com a situação da verificação do usuário. Enquanto ela não está ativada,
+// a ativação é iniciada e o QR code é exibido.
+func (h *TwoFactorHandler) render(w http.ResponseWriter, r *http.Request, status int, data TwoFactorPageData) {
+	user := auth.CurrentUser(r.Context())
+	data.Required = h.twoFactor.Required(user)
+
+	current, err := h.twoFactor.Status(r.Context(), user)
+	switch {
+	case err == nil && current.Enabled():
+		data.Enabled = true
+		data.RecoveryCodesLeft = current.RecoveryCodes
+	case err == nil, errors.Is(err, repository.ErrTwoFactorNotFound):
+		enrollment, err := h.twoFactor.BeginEnrollment(r.Context(), user)
+		if errors.Is(err, auth.ErrPasswordNotSet) {
+			http.Error(w, "Sua conta entra pelo login único; a verificação em duas etapas é feita pelo provedor de identidade", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			serverError(w, r, "Erro interno ao iniciar a verificação em duas etapas", err)
+			return
+		}
+		png, err := qrcode.Encode(enrollment.URI, qrcode.Medium, 256)
+		if err != nil {
+			serverError(w, r, "Erro interno ao gerar o QR code", err)
+			return
+		}
+		data.Secret = enrollment.Secret
+		data.QRCode = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png))
+		// A página exibe o segredo.
+		w.Header().Set("Cache-Control", "no-store")
+	default:
+		serverError(w, r, "Erro interno ao carregar a verificação em duas etapas", err)
+		return
+	}
+
+	page, err := renderer.HTML.Render(r.Context(), "users/two_factor.html", data)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(page)
+}
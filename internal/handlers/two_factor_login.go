@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"net/url"
+)
+
+// TwoFactorLoginPageData reúne os dados do formulário do código da verificação em duas etapas.
+type TwoFactorLoginPageData struct {
+	Next  string
+	Error string
+}
+
+// TwoFactorForm exibe o formulário do código para o login que já passou pela senha.
+func (h *SessionHandler) TwoFactorForm(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.URL.Query().Get("next"))
+	pending, err := h.twoFactor.PendingLogin(r)
+	if err != nil {
+		serverError(w, r, "Erro interno ao entrar", err)
+		return
+	}
+	if !pending {
+		http.Redirect(w, r, "/login?next="+url.QueryEscape(next), http.StatusSeeOther)
+		return
+	}
+	h.renderTwoFactor(w, r, http.StatusOK, TwoFactorLoginPageData{Next: next})
+}
+
+// TwoFactorLogin confere o código do aplicativo ou de recuperação e inicia a sessão.
+func (h *SessionHandler) TwoFactorLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	data := TwoFactorLoginPageData{Next: safeRedirect(r.FormValue("next"))}
+	email, err := h.twoFactor.PendingLoginEmail(r)
+	if errors.Is(err, auth.ErrTwoFactorLoginExpired) {
+		h.renderLogin(w, r, http.StatusUnauthorized, LoginPageData{Next: data.Next, Error: "O prazo para informar o código terminou. Entre de novo."})
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro interno ao entrar", err)
+		return
+	}
+	// Os códigos errados contam como as senhas erradas, para que os códigos não sejam testados sem limite.
+	attempt, ok := h.beginLoginAttempt(w, r, email, func(message string) {
+		data.Error = message
+		h.renderTwoFactor(w, r, http.StatusTooManyRequests, data)
+	})
+	if !ok {
+		return
+	}
+
+	user, err := h.twoFactor.CompleteLogin(w, r, r.FormValue("code"))
+	switch {
+	case errors.Is(err, auth.ErrInvalidTwoFactorCode):
+		h.loginFailed(r, attempt)
+		data.Error = "Código inválido"
+		h.renderTwoFactor(w, r, http.StatusUnauthorized, data)
+		return
+	case errors.Is(err, auth.ErrTwoFactorLoginExpired):
+		h.releaseLoginAttempt(r, attempt)
+		h.renderLogin(w, r, http.StatusUnauthorized, LoginPageData{Next: data.Next, Error: "O prazo para informar o código terminou. Entre de novo."})
+		return
+	case err != nil:
+		h.releaseLoginAttempt(r, attempt)
+		serverError(w, r, "Erro interno ao entrar", err)
+		return
+	}
+
+	h.loginSucceeded(r, attempt)
+	if err := h.sessions.Login(w, r, user); err != nil {
+		serverError(w, r, "Erro interno ao iniciar a sessão", err)
+		return
+	}
+	http.Redirect(w, r, data.Next, http.StatusSeeOther)
+}
+
+func (h *SessionHandler) renderTwoFactor(w http.ResponseWriter, r *http.Request, status int, data TwoFactorLoginPageData) {
+	page, err := renderer.HTML.Render(r.Context(), "sessions/two_factor.html", data)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(page)
+}
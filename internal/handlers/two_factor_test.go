@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"lucienne/pkg/password"
+	"lucienne/pkg/totp"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// MockTwoFactorRepository é a implementação falsa do TwoFactorRepository para testes.
+type MockTwoFactorRepository struct {
+	GetTwoFactorFunc         func(ctx context.Context, userID int64) (*domain.TwoFactor, error)
+	SetTwoFactorSecretFunc   func(ctx context.Context, userID int64, secret string) error
+	EnableTwoFactorFunc      func(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error
+	UseTwoFactorStepFunc     func(ctx context.Context, userID int64, step int64) error
+	ReplaceRecoveryCodesFunc func(ctx context.Context, userID int64, recoveryCodeHashes []string) error
+	UseRecoveryCodeFunc      func(ctx context.Context, userID int64, hash string) error
+	DisableTwoFactorFunc     func(ctx context.Context, userID int64) error
+}
+
+func (m *MockTwoFactorRepository) GetTwoFactor(ctx context.Context, userID int64) (*domain.TwoFactor, error) {
+	if m.GetTwoFactorFunc != nil {
+		return m.GetTwoFactorFunc(ctx, userID)
+	}
+	return nil, repository.ErrTwoFactorNotFound
+}
+
+func (m *MockTwoFactorRepository) SetTwoFactorSecret(ctx context.Context, userID int64, secret string) error {
+	if m.SetTwoFactorSecretFunc != nil {
+		return m.SetTwoFactorSecretFunc(ctx, userID, secret)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func (m *MockTwoFactorRepository) EnableTwoFactor(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error {
+	if m.EnableTwoFactorFunc != nil {
+		return m.EnableTwoFactorFunc(ctx, userID, step, recoveryCodeHashes)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func (m *MockTwoFactorRepository) UseTwoFactorStep(ctx context.Context, userID int64, step int64) error {
+	if m.UseTwoFactorStepFunc != nil {
+		return m.UseTwoFactorStepFunc(ctx, userID, step)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func (m *MockTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodeHashes []string) error {
+	if m.ReplaceRecoveryCodesFunc != nil {
+		return m.ReplaceRecoveryCodesFunc(ctx, userID, recoveryCodeHashes)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func (m *MockTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int64, hash string) error {
+	if m.UseRecoveryCodeFunc != nil {
+		return m.UseRecoveryCodeFunc(ctx, userID, hash)
+	}
+	return repository.ErrRecoveryCodeNotFound
+}
+
+func (m *MockTwoFactorRepository) DisableTwoFactor(ctx context.Context, userID int64) error {
+	if m.DisableTwoFactorFunc != nil {
+		return m.DisableTwoFactorFunc(ctx, userID)
+	}
+	return errors.New("não implementado no mock")
+}
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+
+func newTestTwoFactor(repo repository.TwoFactorRepository, tokens repository.UserTokenRepository, users repository.UserRepository) *auth.TwoFactor {
+	return auth.NewTwoFactor(repo, tokens, users, auth.TwoFactorOptions{
+		Issuer:        "lucienne",
+		RequiredRoles: []string{auth.RoleAdmin},
+		LoginTTL:      5 * time.Minute,
+	})
+}
+
+// currentTOTPCode retorna o código atual do segredo de teste.
+func currentTOTPCode(t *testing.T) string {
+	t.Helper()
+	code, err := totp.Code(testTOTPSecret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// withPasswordUser retorna a requisição como se feita por um usuário que entra com senha.
+func withPasswordUser(req *http.Request, roles ...string) *http.Request {
+	user := &domain.User{ID: 1, Name: "Ana", Email: "ana@example.com", PasswordHash: "hash", Roles: roles}
+	return req.WithContext(auth.WithUser(req.Context(), user))
+}
+
+func TestShowTwoFactor(t *testing.T) {
+	t.Run("deve iniciar a ativação com o QR code", func(t *testing.T) {
+		var saved string
+		repo := &MockTwoFactorRepository{
+			SetTwoFactorSecretFunc: func(ctx context.Context, userID int64, secret string) error {
+				saved = secret
+				return nil
+			},
+		}
+		router := mux.NewRouter()
+		NewTwoFactorHandler(newTestTwoFactor(repo, nil, nil)).DefineTwoFactor(router)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, withPasswordUser(httptest.NewRequest("GET", "/profile/2fa", nil)))
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
+		}
+		for _, expected := range []string{`src="data:image/png;base64,`, `<code id="secret">` + saved + `</code>`} {
+			if saved == "" || !strings.Contains(rr.Body.String(), expected) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+			}
+		}
+		if cache := rr.Header().Get("Cache-Control"); cache != "no-store" {
+			t.Errorf("esperava Cache-Control no-store, obteve %q", cache)
+		}
+	})
+
+	t.Run("deve exibir a situação da verificação ativada", func(t *testing.T) {
+		enabledAt := time.Now()
+		repo := &MockTwoFactorRepository{
+			GetTwoFactorFunc: func(ctx context.Context, userID int64) (*domain.TwoFactor, error) {
+				return &domain.TwoFactor{UserID: userID, Secret: testTOTPSecret, EnabledAt: &enabledAt, RecoveryCodes: 7}, nil
+			},
+		}
+		router := mux.NewRouter()
+		NewTwoFactorHandler(newTestTwoFactor(repo, nil, nil)).DefineTwoFactor(router)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, withPasswordUser(httptest.NewRequest("GET", "/profile/2fa", nil), auth.RoleAdmin))
+
+		for _, expected := range []string{"Restam 7 códigos de recuperação", "Seu papel exige a verificação em duas etapas, por isso ela não pode ser desativada"} {
+			if !strings.Contains(rr.Body.String(), expected) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+			}
+		}
+		if strings.Contains(rr.Body.String(), testTOTPSecret) {
+			t.Error("o segredo não deveria ser exibido depois da ativação")
+		}
+	})
+}
+
+func TestEnableTwoFactor(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		code                 func(t *testing.T) string
+		expectedStatusCode   int
+		expectedBodyContains string
+		expectedEnabled      bool
+	}{
+		{name: "deve ativar e exibir os códigos de recuperação", code: currentTOTPCode, expectedStatusCode: http.StatusCreated, expectedBodyContains: `<ul id="recovery-codes">`, expectedEnabled: true},
+		{name: "deve recusar um código errado", code: func(*testing.T) string { return "000000" }, expectedStatusCode: http.StatusBadRequest, expectedBodyContains: "Código inválido"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var enabledAt *time.Time
+			var hashes []string
+			repo := &MockTwoFactorRepository{
+				GetTwoFactorFunc: func(ctx context.Context, userID int64) (*domain.TwoFactor, error) {
+					return &domain.TwoFactor{UserID: userID, Secret: testTOTPSecret, EnabledAt: enabledAt, RecoveryCodes: len(hashes)}, nil
+				},
+				EnableTwoFactorFunc: func(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error {
+					now := time.Now()
+					enabledAt = &now
+					hashes = recoveryCodeHashes
+					return nil
+				},
+			}
+			router := mux.NewRouter()
+			NewTwoFactorHandler(newTestTwoFactor(repo, nil, nil)).DefineTwoFactor(router)
+
+			form := url.Values{"code": {tc.code(t)}}
+			req := withPasswordUser(httptest.NewRequest("POST", "/profile/2fa", strings.NewReader(form.Encode())))
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if (enabledAt != nil) != tc.expectedEnabled {
+				t.Errorf("verificação ativada: got %v want %v", enabledAt != nil, tc.expectedEnabled)
+			}
+			if tc.expectedEnabled && (len(hashes) != 10 || strings.Count(rr.Body.String(), "<li><code>") != 10) {
+				t.Errorf("esperava 10 códigos de recuperação, obteve %d hashes", len(hashes))
+			}
+		})
+	}
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	enabledAt := time.Now()
+	testCases := []struct {
+		name               string
+		roles              []string
+		expectedStatusCode int
+		expectedDisabled   bool
+	}{
+		{name: "deve desativar com um código válido", expectedStatusCode: http.StatusOK, expectedDisabled: true},
+		{name: "deve recusar quando o papel exige a verificação", roles: []string{auth.RoleAdmin}, expectedStatusCode: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			disabled := false
+			repo := &MockTwoFactorRepository{
+				GetTwoFactorFunc: func(ctx context.Context, userID int64) (*domain.TwoFactor, error) {
+					return &domain.TwoFactor{UserID: userID, Secret: testTOTPSecret, EnabledAt: &enabledAt}, nil
+				},
+				UseTwoFactorStepFunc: func(ctx context.Context, userID int64, step int64) error { return nil },
+				DisableTwoFactorFunc: func(ctx context.Context, userID int64) error {
+					disabled = true
+					return nil
+				},
+			}
+			router := mux.NewRouter()
+			NewTwoFactorHandler(newTestTwoFactor(repo, nil, nil)).DefineTwoFactor(router)
+
+			// O formulário envia um POST com o campo _method, como no navegador.
+			form := url.Values{"code": {currentTOTPCode(t)}, middleware.MethodOverrideField: {"DELETE"}}
+			req := withPasswordUser(httptest.NewRequest("POST", "/profile/2fa", strings.NewReader(form.Encode())), tc.roles...)
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+
+			middleware.MethodOverride(router).ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if disabled != tc.expectedDisabled {
+				t.Errorf("verificação desativada: got %v want %v", disabled, tc.expectedDisabled)
+			}
+		})
+	}
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	hash, err := password.Hash("uma senha bem longa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &domain.User{ID: 1, Email: "ana@example.com", PasswordHash: hash, TwoFactorEnabled: true}
+	users := &MockUserRepository{
+		GetUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) { return user, nil },
+		GetUserByIDFunc:    func(ctx context.Context, id int64) (*domain.User, error) { return user, nil },
+	}
+	enabledAt := time.Now()
+	var lastStep int64
+	repo := &MockTwoFactorRepository{
+		GetTwoFactorFunc: func(ctx context.Context, userID int64) (*domain.TwoFactor, error) {
+			return &domain.TwoFactor{UserID: userID, Secret: testTOTPSecret, EnabledAt: &enabledAt, LastStep: lastStep}, nil
+		},
+		UseTwoFactorStepFunc: func(ctx context.Context, userID int64, step int64) error {
+			if step <= lastStep {
+				return repository.ErrTwoFactorStepUsed
+			}
+			lastStep = step
+			return nil
+		},
+	}
+	pending := map[string]*domain.UserToken{}
+	tokens := &MockUserTokenRepository{
+		CreateUserTokenFunc: func(ctx context.Context, token *domain.UserToken) error {
+			pending[token.TokenHash] = token
+			return nil
+		},
+		GetUserTokenFunc: func(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+			if token, ok := pending[hash]; ok && token.Purpose == purpose {
+				return token, nil
+			}
+			return nil, repository.ErrUserTokenNotFound
+		},
+		ConsumeUserTokenFunc: func(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+			token, ok := pending[hash]
+			if !ok {
+				return nil, repository.ErrUserTokenNotFound
+			}
+			delete(pending, hash)
+			return token, nil
+		},
+	}
+	var created *domain.Session
+	sessions := auth.NewSessionManager(&MockSessionRepository{
+		CreateSessionFunc: func(ctx context.Context, session *domain.Session) error {
+			created = session
+			return nil
+		},
+	}, users, auth.SessionOptions{IdleTimeout: time.Hour, Lifetime: time.Hour})
+	// A senha certa também reserva uma tentativa, desfeita enquanto o código não é informado; só as reservas
+	// mantidas contam como falhas.
+	var reserved []string
+	released := map[int64]bool{}
+	failures := func() []string {
+		var kept []string
+		for i, reservation := range reserved {
+			if !released[int64(i+1)] {
+				kept = append(kept, reservation)
+			}
+		}
+		return kept
+	}
+	throttles := &MockLoginThrottleRepository{
+		ReserveLoginAttemptFunc: func(ctx context.Context, scope string, subject string, ip string, at time.Time, policy domain.LoginThrottlePolicy) (*domain.LoginThrottle, error) {
+			reserved = append(reserved, scope+":"+subject)
+			return &domain.LoginThrottle{ID: int64(len(reserved)), Scope: scope, Subject: subject, Failures: 1, LastFailureAt: at}, nil
+		},
+		ReleaseLoginAttemptFunc: func(ctx context.Context, id int64, at time.Time) error {
+			released[id] = true
+			return nil
+		},
+	}
+	handler := NewSessionHandler(users, sessions)
+	handler.EnableTwoFactor(newTestTwoFactor(repo, tokens, users))
+	handler.EnableLoginGuard(newTestLoginGuard(throttles, users), false)
+	router := mux.NewRouter()
+	handler.DefineSessions(router)
+
+	post := func(path string, form url.Values, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	login := post("/login", url.Values{"email": {"ana@example.com"}, "password": {"uma senha bem longa"}, "next": {"/authors"}}, nil)
+	if status := login.Code; status != http.StatusSeeOther {
+		t.Fatalf("handler retornou status code errado: got %v want %v", status, http.StatusSeeOther)
+	}
+	if location := login.Header().Get("Location"); location != "/login/2fa?next=%2Fauthors" {
+		t.Errorf("handler redirecionou para o lugar errado: got %q want %q", location, "/login/2fa?next=%2Fauthors")
+	}
+	if created != nil {
+		t.Fatal("a sessão não deveria ser criada antes do código")
+	}
+	cookies := login.Result().Cookies()
+
+	t.Run("deve exibir o formulário do código para o login pendente", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/login/2fa?next=%2Fauthors", nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("deve voltar ao login sem um login pendente", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/login/2fa", nil))
+
+		if status := rr.Code; status != http.StatusSeeOther {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusSeeOther)
+		}
+	})
+
+	t.Run("deve recusar um código errado", func(t *testing.T) {
+		rr := post("/login/2fa", url.Values{"code": {"000000"}, "next": {"/authors"}}, cookies)
+
+		if status := rr.Code; status != http.StatusUnauthorized {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusUnauthorized)
+		}
+		if !strings.Contains(rr.Body.String(), "Código inválido") || created != nil {
+			t.Errorf("esperava o formulário com o erro e nenhuma sessão: %q", rr.Body.String())
+		}
+		if kept := strings.Join(failures(), ","); kept != "account:ana@example.com,ip:192.0.2.1" {
+			t.Errorf("o código errado deveria contar como uma falha de login: %v", kept)
+		}
+	})
+
+	t.Run("deve iniciar a sessão com o código do aplicativo", func(t *testing.T) {
+		rr := post("/login/2fa", url.Values{"code": {currentTOTPCode(t)}, "next": {"/authors"}}, cookies)
+
+		if status := rr.Code; status != http.StatusSeeOther {
+			t.Fatalf("handler retornou status code errado: got %v want %v: %s", status, http.StatusSeeOther, rr.Body.String())
+		}
+		if location := rr.Header().Get("Location"); location != "/authors" {
+			t.Errorf("handler redirecionou para o lugar errado: got %q want %q", location, "/authors")
+		}
+		if created == nil || created.UserID != 1 {
+			t.Errorf("esperava a sessão do usuário: %+v", created)
+		}
+	})
+
+	t.Run("não deve aceitar o mesmo login pendente de novo", func(t *testing.T) {
+		rr := post("/login/2fa", url.Values{"code": {currentTOTPCode(t)}}, cookies)
+
+		if status := rr.Code; status != http.StatusUnauthorized {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusUnauthorized)
+		}
+		if !strings.Contains(rr.Body.String(), "O prazo para informar o código terminou") {
+			t.Errorf("handler retornou corpo inesperado: %q", rr.Body.String())
+		}
+	})
+}
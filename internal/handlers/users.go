@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/internal/middleware"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// UserHandler agrupa os handlers de cadastro e perfil de usuários.
+type UserHandler struct {
+	repo     repository.UserRepository
+	accounts *auth.AccountTokens
+}
+
+// NewUserHandler cria uma nova instância do UserHandler com suas dependências.
+func NewUserHandler(repo repository.UserRepository, accounts *auth.AccountTokens) *UserHandler {
+	return &UserHandler{repo: repo, accounts: accounts}
+}
+
+// DefineUsers registra as rotas de usuário no roteador.
+func (h *UserHandler) DefineUsers(router *mux.Router) {
+	router.HandleFunc("/register", h.RegisterForm).Methods("GET")
+	router.HandleFunc("/register", h.Register).Methods("POST")
+	router.HandleFunc("/email/verify", h.VerifyEmail).Methods("GET")
+
+	profile := router.NewRoute().Subrouter()
+	profile.Use(middleware.RequireUser)
+	profile.HandleFunc("/profile", h.Profile).Methods("GET")
+	profile.HandleFunc("/profile", h.UpdateProfile).Methods("PUT")
+	profile.HandleFunc("/profile/password", h.UpdatePassword).Methods("PUT")
+	profile.HandleFunc("/profile/email/verification", h.ResendEmailVerification).Methods("POST")
+}
+
+// RegisterForm exibe o formulário de cadastro.
+func (h *UserHandler) RegisterForm(w http.ResponseWriter, r *http.Request) {
+	page, err := renderer.HTML.Render(r.Context(), "users/register.html", nil)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+}
+
+// Register cria uma nova conta de usuário.
+func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	if r.FormValue("password") != r.FormValue("password_confirmation") {
+		http.Error(w, "As senhas não conferem", http.StatusBadRequest)
+		return
+	}
+
+	user, err := auth.Register(r.Context(), h.repo, r.FormValue("name"), r.FormValue("email"), r.FormValue("password"))
+	if err != nil {
+		h.userError(w, r, "Erro interno ao criar usuário", err)
+		return
+	}
+	h.sendEmailVerification(r, user)
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(fmt.Sprintf("Conta criada com sucesso: %s", user.Email)))
+}
+
+// Profile exibe os dados do usuário autenticado.
+func (h *UserHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	user := auth.CurrentUser(r.Context())
+	if user == nil {
+		http.Error(w, "É preciso entrar para acessar esta página", http.StatusUnauthorized)
+		return
+	}
+
+	page, err := renderer.HTML.Render(r.Context(), "users/profile.html", user)
+	if err != nil {
+		serverError(w, r, "Erro ao renderizar a página", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(page)
+}
+
+// UpdateProfile altera o nome e o email do usuário autenticado.
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	user := auth.CurrentUser(r.Context())
+	if user == nil {
+		http.Error(w, "É preciso entrar para acessar esta página", http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		http.Error(w, `O campo "name" é obrigatório`, http.StatusBadRequest)
+		return
+	}
+	email, err := auth.NormalizeEmail(r.FormValue("email"))
+	if err != nil {
+		http.Error(w, "Email inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.UpdateUserProfile(r.Context(), user.ID, name, email); err != nil {
+		h.userError(w, r, "Erro interno ao atualizar perfil", err)
+		return
+	}
+	// O novo email precisa ser confirmado.
+	if !strings.EqualFold(user.Email, email) {
+		user.Email, user.EmailVerifiedAt = email, nil
+		h.sendEmailVerification(r, user)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Perfil atualizado com sucesso \n"))
+}
+
+// UpdatePassword troca a senha do usuário autenticado, exigindo a senha atual.
+func (h *UserHandler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
+	user := auth.CurrentUser(r.Context())
+	if user == nil {
+		http.Error(w, "É preciso entrar para acessar esta página", http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Erro ao processar o formulário", http.StatusBadRequest)
+		return
+	}
+
+	if r.FormValue("password") != r.FormValue("password_confirmation") {
+		http.Error(w, "As senhas não conferem", http.StatusBadRequest)
+		return
+	}
+
+	err := auth.ChangePassword(r.Context(), h.repo, user, r.FormValue("current_password"), r.FormValue("password"))
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		http.Error(w, "Senha atual incorreta", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, auth.ErrPasswordNotSet) {
+		http.Error(w, "Sua conta entra pelo login único e não tem senha", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.userError(w, r, "Erro interno ao alterar senha", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Senha alterada com sucesso \n"))
+}
+
+// ResendEmailVerification envia de novo o link de confirmação do email do usuário autenticado.
+func (h *UserHandler) ResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	user := auth.CurrentUser(r.Context())
+	if user == nil {
+		http.Error(w, "É preciso entrar para acessar esta página", http.StatusUnauthorized)
+		return
+	}
+	if user.EmailVerified() {
+		http.Error(w, "Seu email já está confirmado", http.StatusConflict)
+		return
+	}
+
+	if err := h.accounts.SendEmailVerification(r.Context(), user); err != nil {
+		serverError(w, r, "Erro interno ao enviar o email de confirmação", err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte(fmt.Sprintf("Enviamos o link de confirmação para %s \n", user.Email)))
+}
+
+// VerifyEmail confirma o email com o link enviado por email.
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	err := h.accounts.VerifyEmail(r.Context(), r.URL.Query().Get("token"))
+	if errors.Is(err, auth.ErrInvalidUserToken) {
+		http.Error(w, "Link de confirmação inválido ou expirado", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		serverError(w, r, "Erro interno ao confirmar o email", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Email confirmado com sucesso \n"))
+}
+
+// sendEmailVerification envia o link de confirmação sem interromper a requisição: o usuário pode pedir
+// um novo link no perfil se o envio falhar.
+func (h *UserHandler) sendEmailVerification(r *http.Request, user *domain.User) {
+	if err := h.accounts.SendEmailVerification(r.Context(), user); err != nil {
+		slog.ErrorContext(r.Context(), "erro ao enviar o email de confirmação", "error", err, "user_id", user.ID)
+	}
+}
+
+// userError traduz os erros de validação e de conflito de usuários em respostas 4xx.
+func (h *UserHandler) userError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrUserAlreadyExists):
+		http.Error(w, "Erro: Este email já está cadastrado.", http.StatusConflict)
+	case errors.Is(err, auth.ErrNameRequired):
+		http.Error(w, `O campo "name" é obrigatório`, http.StatusBadRequest)
+	case errors.Is(err, auth.ErrInvalidEmail):
+		http.Error(w, "Email inválido", http.StatusBadRequest)
+	case errors.Is(err, auth.ErrWeakPassword):
+		http.Error(w, fmt.Sprintf("A senha deve ter entre %d e %d caracteres", auth.MinPasswordLength, auth.MaxPasswordLength), http.StatusBadRequest)
+	default:
+		serverError(w, r, msg, err)
+	}
+}
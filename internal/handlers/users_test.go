@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/password"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// MockUserRepository é a implementação falsa do UserRepository para testes.
+type MockUserRepository struct {
+	CreateUserFunc         func(ctx context.Context, user *domain.User) error
+	GetUsersFunc           func(ctx context.Context) ([]domain.User, error)
+	GetUserByIDFunc        func(ctx context.Context, id int64) (*domain.User, error)
+	GetUserByEmailFunc     func(ctx context.Context, email string) (*domain.User, error)
+	UpdateUserProfileFunc  func(ctx context.Context, id int64, name string, email string) error
+	UpdateUserPasswordFunc func(ctx context.Context, id int64, passwordHash string) error
+	MarkEmailVerifiedFunc  func(ctx context.Context, id int64, email string) error
+}
+
+func (m *MockUserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	if m.CreateUserFunc != nil {
+		return m.CreateUserFunc(ctx, user)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func (m *MockUserRepository) GetUsers(ctx context.Context) ([]domain.User, error) {
+	if m.GetUsersFunc != nil {
+		return m.GetUsersFunc(ctx)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
+	if m.GetUserByIDFunc != nil {
+		return m.GetUserByIDFunc(ctx, id)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	if m.GetUserByEmailFunc != nil {
+		return m.GetUserByEmailFunc(ctx, email)
+	}
+	return nil, errors.New("não implementado no mock")
+}
+
+func (m *MockUserRepository) UpdateUserProfile(ctx context.Context, id int64, name string, email string) error {
+	if m.UpdateUserProfileFunc != nil {
+		return m.UpdateUserProfileFunc(ctx, id, name, email)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func (m *MockUserRepository) UpdateUserPassword(ctx context.Context, id int64, passwordHash string) error {
+	if m.UpdateUserPasswordFunc != nil {
+		return m.UpdateUserPasswordFunc(ctx, id, passwordHash)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id int64, email string) error {
+	if m.MarkEmailVerifiedFunc != nil {
+		return m.MarkEmailVerifiedFunc(ctx, id, email)
+	}
+	return errors.New("não implementado no mock")
+}
+
+func TestRegister(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		form                 url.Values
+		mockRepo             *MockUserRepository
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{
+			name: "deve criar a conta com a senha em hash",
+			form: url.Values{"name": {"Ana"}, "email": {"ana@example.com"}, "password": {"uma senha bem longa"}, "password_confirmation": {"uma senha bem longa"}},
+			mockRepo: &MockUserRepository{
+				CreateUserFunc: func(ctx context.Context, user *domain.User) error {
+					if !strings.HasPrefix(user.PasswordHash, "$argon2id$") {
+						return errors.New("senha não está em hash")
+					}
+					return nil
+				},
+			},
+			expectedStatusCode:   http.StatusCreated,
+			expectedBodyContains: "Conta criada com sucesso: ana@example.com",
+		},
+		{
+			name: "deve retornar 409 para um email já cadastrado",
+			form: url.Values{"name": {"Ana"}, "email": {"ANA@example.com"}, "password": {"uma senha bem longa"}, "password_confirmation": {"uma senha bem longa"}},
+			mockRepo: &MockUserRepository{
+				CreateUserFunc: func(ctx context.Context, user *domain.User) error {
+					return repository.ErrUserAlreadyExists
+				},
+			},
+			expectedStatusCode:   http.StatusConflict,
+			expectedBodyContains: "Este email já está cadastrado",
+		},
+		{
+			name:                 "deve retornar 400 se as senhas não conferem",
+			form:                 url.Values{"name": {"Ana"}, "email": {"ana@example.com"}, "password": {"uma senha bem longa"}, "password_confirmation": {"outra senha longa"}},
+			mockRepo:             &MockUserRepository{},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "As senhas não conferem",
+		},
+		{
+			name:                 "deve retornar 400 para uma senha curta",
+			form:                 url.Values{"name": {"Ana"}, "email": {"ana@example.com"}, "password": {"curta"}, "password_confirmation": {"curta"}},
+			mockRepo:             &MockUserRepository{},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "A senha deve ter entre 12 e 128 caracteres",
+		},
+		{
+			name:                 "deve retornar 400 para um email inválido",
+			form:                 url.Values{"name": {"Ana"}, "email": {"ana"}, "password": {"uma senha bem longa"}, "password_confirmation": {"uma senha bem longa"}},
+			mockRepo:             &MockUserRepository{},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "Email inválido",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			accounts, mail := newTestAccounts(tc.mockRepo, &MockUserTokenRepository{})
+			handler := NewUserHandler(tc.mockRepo, accounts)
+
+			req := httptest.NewRequest("POST", "/register", strings.NewReader(tc.form.Encode()))
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+
+			handler.Register(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if tc.expectedStatusCode == http.StatusCreated && (len(mail.messages) != 1 || !strings.Contains(mail.messages[0].Text, "/email/verify?token=")) {
+				t.Errorf("esperava o email de confirmação, obteve %+v", mail.messages)
+			}
+		})
+	}
+}
+
+func TestProfile(t *testing.T) {
+	accounts, _ := newTestAccounts(&MockUserRepository{}, &MockUserTokenRepository{})
+	handler := NewUserHandler(&MockUserRepository{}, accounts)
+
+	t.Run("deve exigir um usuário autenticado", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.Profile(rr, httptest.NewRequest("GET", "/profile", nil))
+
+		if status := rr.Code; status != http.StatusUnauthorized {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("deve exibir os dados do usuário autenticado", func(t *testing.T) {
+		user := &domain.User{ID: 1, Name: "Ana", Email: "ana@example.com"}
+		req := httptest.NewRequest("GET", "/profile", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), user))
+		rr := httptest.NewRecorder()
+
+		handler.Profile(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler retornou status code errado: got %v want %v", status, http.StatusOK)
+		}
+		for _, expected := range []string{`value="Ana"`, `value="ana@example.com"`, `name="_method" value="PUT"`} {
+			if !strings.Contains(rr.Body.String(), expected) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), expected)
+			}
+		}
+	})
+}
+
+func TestUpdatePassword(t *testing.T) {
+	hash, err := password.Hash("uma senha bem longa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name                 string
+		current              string
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{name: "deve trocar a senha", current: "uma senha bem longa", expectedStatusCode: http.StatusOK, expectedBodyContains: "Senha alterada com sucesso"},
+		{name: "deve recusar a senha atual incorreta", current: "senha errada!!", expectedStatusCode: http.StatusBadRequest, expectedBodyContains: "Senha atual incorreta"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var saved string
+			handler := NewUserHandler(&MockUserRepository{
+				UpdateUserPasswordFunc: func(ctx context.Context, id int64, passwordHash string) error {
+					saved = passwordHash
+					return nil
+				},
+			}, nil)
+
+			form := url.Values{"current_password": {tc.current}, "password": {"uma nova senha longa"}, "password_confirmation": {"uma nova senha longa"}}
+			req := httptest.NewRequest("PUT", "/profile/password", strings.NewReader(form.Encode()))
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			req = req.WithContext(auth.WithUser(req.Context(), &domain.User{ID: 1, PasswordHash: hash}))
+			rr := httptest.NewRecorder()
+
+			handler.UpdatePassword(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if tc.expectedStatusCode == http.StatusOK {
+				if match, _, _ := password.Verify("uma nova senha longa", saved); !match {
+					t.Errorf("a nova senha não foi salva: %q", saved)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		consumed             *domain.UserToken
+		markErr              error
+		expectedStatusCode   int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "deve confirmar o email do link",
+			consumed:             &domain.UserToken{UserID: 1, Email: "ana@example.com"},
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: "Email confirmado com sucesso",
+		},
+		{
+			name:                 "deve recusar um link usado ou expirado",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "Link de confirmação inválido ou expirado",
+		},
+		{
+			name:                 "deve recusar o link de um email que já mudou",
+			consumed:             &domain.UserToken{UserID: 1, Email: "antigo@example.com"},
+			markErr:              repository.ErrUserNotFound,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBodyContains: "Link de confirmação inválido ou expirado",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var verified string
+			users := &MockUserRepository{
+				MarkEmailVerifiedFunc: func(ctx context.Context, id int64, email string) error {
+					verified = email
+					return tc.markErr
+				},
+			}
+			tokens := &MockUserTokenRepository{
+				ConsumeUserTokenFunc: func(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+					if tc.consumed == nil || purpose != domain.TokenPurposeEmailVerification {
+						return nil, repository.ErrUserTokenNotFound
+					}
+					return tc.consumed, nil
+				},
+			}
+			accounts, _ := newTestAccounts(users, tokens)
+			handler := NewUserHandler(users, accounts)
+			rr := httptest.NewRecorder()
+
+			handler.VerifyEmail(rr, httptest.NewRequest("GET", "/email/verify?token=abc", nil))
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler retornou corpo inesperado: got %q want to contain %q", rr.Body.String(), tc.expectedBodyContains)
+			}
+			if tc.consumed != nil && verified != tc.consumed.Email {
+				t.Errorf("esperava confirmar %q, obteve %q", tc.consumed.Email, verified)
+			}
+		})
+	}
+}
+
+func TestResendEmailVerification(t *testing.T) {
+	verifiedAt := time.Now()
+	testCases := []struct {
+		name               string
+		user               *domain.User
+		expectedStatusCode int
+		expectedMessages   int
+	}{
+		{name: "deve enviar um novo link", user: &domain.User{ID: 1, Name: "Ana", Email: "ana@example.com"}, expectedStatusCode: http.StatusAccepted, expectedMessages: 1},
+		{name: "deve recusar um email já confirmado", user: &domain.User{ID: 1, Name: "Ana", Email: "ana@example.com", EmailVerifiedAt: &verifiedAt}, expectedStatusCode: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			accounts, mail := newTestAccounts(&MockUserRepository{}, &MockUserTokenRepository{})
+			handler := NewUserHandler(&MockUserRepository{}, accounts)
+			req := httptest.NewRequest("POST", "/profile/email/verification", nil)
+			req = req.WithContext(auth.WithUser(req.Context(), tc.user))
+			rr := httptest.NewRecorder()
+
+			handler.ResendEmailVerification(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Errorf("handler retornou status code errado: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if len(mail.messages) != tc.expectedMessages {
+				t.Errorf("esperava %d emails, obteve %d", tc.expectedMessages, len(mail.messages))
+			}
+		})
+	}
+}
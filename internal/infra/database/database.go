@@ -2,14 +2,16 @@ package database
 
 import (
 	"context"
+	"errors"
 	"log"
+	"log/slog"
 	"lucienne/config"
 	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-var Conn *pgx.Conn
+var Conn *pgxpool.Pool
 
 func ConnectDB() {
 	dbURL := config.EnvVariables.DatabaseURL
@@ -17,7 +19,16 @@ func ConnectDB() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	conn, err := pgx.Connect(ctx, dbURL)
+	poolConfig, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		log.Fatalf("Erro ao ler a configuração do banco: %v", err)
+	}
+	if config.EnvVariables.DatabaseMaxConns > 0 {
+		poolConfig.MaxConns = config.EnvVariables.DatabaseMaxConns
+	}
+	poolConfig.ConnConfig.Tracer = queryTracer{}
+
+	conn, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		log.Fatalf("Erro ao conectar ao banco: %v", err)
 	}
@@ -26,6 +37,22 @@ func ConnectDB() {
 		log.Fatalf("Erro ao dar ping no banco: %v", err)
 	}
 
-	log.Println("Conectado!")
+	slog.Info("Conectado ao banco de dados", "max_conns", poolConfig.MaxConns)
 	Conn = conn
 }
+
+// Close encerra o pool de conexões, aguardando as conexões em uso serem devolvidas.
+func Close(_ context.Context) error {
+	if Conn != nil {
+		Conn.Close()
+	}
+	return nil
+}
+
+// Ping verifica se o banco de dados está acessível.
+func Ping(ctx context.Context) error {
+	if Conn == nil {
+		return errors.New("banco de dados não conectado")
+	}
+	return Conn.Ping(ctx)
+}
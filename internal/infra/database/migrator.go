@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrate aplica todas as migrações pendentes encontradas em migrations no banco de dados indicado.
+func Migrate(migrations fs.FS, databaseURL string) error {
+	source, err := iofs.New(migrations, ".")
+	if err != nil {
+		return fmt.Errorf("erro ao ler as migrações: %w", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, databaseURL)
+	if err != nil {
+		return fmt.Errorf("erro ao criar instância de migração: %w", err)
+	}
+
+	slog.Info("Iniciando migrações")
+	if err := m.Up(); err != nil {
+		if !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("erro ao aplicar migrações: %w", err)
+		}
+		slog.Info("Nenhuma migração pendente. Banco de dados já está atualizado")
+	} else {
+		slog.Info("Migrações aplicadas com sucesso")
+	}
+
+	// Log do estado atual das migrações
+	version, dirty, err := m.Version()
+	if err != nil {
+		return fmt.Errorf("erro ao obter versão das migrações: %w", err)
+	}
+	slog.Info("Versão atual do banco de dados", "version", version, "dirty", dirty)
+
+	// Fechar a instância de migração para liberar a conexão com o banco de dados.
+	sourceErr, dbErr := m.Close()
+	if sourceErr != nil {
+		return fmt.Errorf("erro ao fechar o source da migração: %w", sourceErr)
+	}
+	if dbErr != nil {
+		return fmt.Errorf("erro ao fechar a conexão do banco de dados da migração: %w", dbErr)
+	}
+	return nil
+}
+
+// LatestMigrationVersion retorna a versão da última migração disponível em migrations.
+func LatestMigrationVersion(migrations fs.FS) (uint, error) {
+	source, err := iofs.New(migrations, ".")
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// CheckMigrations retorna uma verificação que falha quando o banco está atrás da última migração conhecida pelo
+// binário ou está dirty. Um banco à frente é aceito: durante um deploy gradual, as réplicas antigas continuam
+// prontas depois que as novas aplicam as migrações.
+func CheckMigrations(migrations fs.FS) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if Conn == nil {
+			return errors.New("banco de dados não conectado")
+		}
+		expected, err := LatestMigrationVersion(migrations)
+		if err != nil {
+			return fmt.Errorf("erro ao ler as migrações: %w", err)
+		}
+
+		var version uint
+		var dirty bool
+		err = Conn.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if err != nil {
+			return fmt.Errorf("erro ao obter versão das migrações: %w", err)
+		}
+		return checkMigrationVersion(version, dirty, expected)
+	}
+}
+
+// checkMigrationVersion compara a versão do banco com a última migração do binário.
+func checkMigrationVersion(version uint, dirty bool, expected uint) error {
+	if dirty {
+		return fmt.Errorf("migração %d está dirty", version)
+	}
+	if version < expected {
+		return fmt.Errorf("banco na versão %d, esperada %d", version, expected)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLatestMigrationVersion(t *testing.T) {
+	t.Run("retorna a versão da última migração", func(t *testing.T) {
+		migrations := fstest.MapFS{
+			"000001_create_a.up.sql":   {Data: []byte("SELECT 1;")},
+			"000001_create_a.down.sql": {Data: []byte("SELECT 1;")},
+			"000010_create_b.up.sql":   {Data: []byte("SELECT 1;")},
+			"000002_create_c.up.sql":   {Data: []byte("SELECT 1;")},
+		}
+
+		version, err := LatestMigrationVersion(migrations)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if version != 10 {
+			t.Errorf("Expected: 10, Got: %d", version)
+		}
+	})
+
+	t.Run("lê as migrações do projeto", func(t *testing.T) {
+		if _, err := LatestMigrationVersion(os.DirFS("../../../db/migrations")); err != nil {
+			t.Errorf("Erro inesperado: %v", err)
+		}
+	})
+}
+
+func TestCheckMigrations(t *testing.T) {
+	testCases := []struct {
+		name     string
+		version  uint
+		dirty    bool
+		expected uint
+		wantErr  bool
+	}{
+		{name: "aceita o banco na última versão", version: 10, expected: 10},
+		{name: "aceita o banco à frente do binário", version: 12, expected: 10},
+		{name: "recusa o banco atrás do binário", version: 9, expected: 10, wantErr: true},
+		{name: "recusa o banco dirty", version: 10, dirty: true, expected: 10, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkMigrationVersion(tc.version, tc.dirty, tc.expected)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error: %v, Got: %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("retorna um erro sem conexão com o banco", func(t *testing.T) {
+		if Conn != nil {
+			t.Skip("banco conectado")
+		}
+		if err := CheckMigrations(os.DirFS("../../../db/migrations"))(context.Background()); err == nil {
+			t.Error("Expected: error, Got: nil")
+		}
+	})
+}
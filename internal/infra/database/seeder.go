@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrSeedSetNotFound é retornado quando o conjunto de seeds solicitado não existe.
+var ErrSeedSetNotFound = errors.New("conjunto de seeds não encontrado")
+
+// seedSetsByEnvironment indica qual conjunto de seeds é aplicado por padrão em cada ambiente.
+// Ambientes fora deste mapa (como produção) não recebem seeds automaticamente.
+var seedSetsByEnvironment = map[string]string{
+	"development": "dev",
+	"demo":        "demo",
+	"test":        "test",
+	"staging":     "staging",
+}
+
+// SeedSetFor retorna o conjunto de seeds padrão para o ambiente informado.
+func SeedSetFor(environment string) (string, bool) {
+	set, ok := seedSetsByEnvironment[environment]
+	return set, ok
+}
+
+// Seeder aplica conjuntos de seeds nomeados, onde cada conjunto é um diretório com arquivos .sql.
+// Os arquivos devem ser idempotentes, pois são executados novamente a cada aplicação.
+type Seeder struct {
+	seeds fs.FS
+}
+
+// NewSeeder cria um Seeder que lê os conjuntos de seeds a partir de seeds.
+func NewSeeder(seeds fs.FS) *Seeder {
+	return &Seeder{seeds: seeds}
+}
+
+// Sets lista os conjuntos de seeds disponíveis.
+func (s *Seeder) Sets() ([]string, error) {
+	entries, err := fs.ReadDir(s.seeds, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			sets = append(sets, entry.Name())
+		}
+	}
+	return sets, nil
+}
+
+// Files lista, em ordem de execução, os arquivos do conjunto de seeds informado.
+func (s *Seeder) Files(set string) ([]string, error) {
+	entries, err := fs.ReadDir(s.seeds, set)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrSeedSetNotFound, set)
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			files = append(files, path.Join(set, entry.Name()))
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// Apply executa todos os arquivos do conjunto de seeds em uma única transação.
+func (s *Seeder) Apply(ctx context.Context, set string) error {
+	files, err := s.Files(set)
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Aplicando seeds", "set", set)
+	err = pgx.BeginFunc(ctx, Conn, func(tx pgx.Tx) error {
+		for _, file := range files {
+			content, err := fs.ReadFile(s.seeds, file)
+			if err != nil {
+				return err
+			}
+			// Sem argumentos o pgx usa o protocolo simples, que aceita várias instruções por arquivo.
+			if _, err := tx.Exec(ctx, string(content)); err != nil {
+				return fmt.Errorf("erro ao aplicar seed %s: %w", file, err)
+			}
+			slog.InfoContext(ctx, "Seed aplicada", "file", file)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Conjunto de seeds aplicado com sucesso", "set", set)
+	return nil
+}
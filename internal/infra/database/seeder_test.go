@@ -0,0 +1,75 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSeedSetFor(t *testing.T) {
+	t.Run("retorna o conjunto padrão do ambiente", func(t *testing.T) {
+		set, ok := SeedSetFor("development")
+		if !ok || set != "dev" {
+			t.Errorf("Expected: dev, Got: %q (ok: %v)", set, ok)
+		}
+	})
+
+	t.Run("não retorna conjunto para ambientes sem seeds", func(t *testing.T) {
+		if set, ok := SeedSetFor("production"); ok {
+			t.Errorf("Expected: no seed set, Got: %q", set)
+		}
+	})
+
+	t.Run("todos os conjuntos padrão existem em db/seeds", func(t *testing.T) {
+		seeder := NewSeeder(os.DirFS("../../../db/seeds"))
+		sets, err := seeder.Sets()
+		if err != nil {
+			t.Fatalf("Erro ao listar conjuntos: %v", err)
+		}
+		for environment, set := range seedSetsByEnvironment {
+			if !slices.Contains(sets, set) {
+				t.Errorf("Conjunto %q do ambiente %q não existe em db/seeds", set, environment)
+			}
+		}
+	})
+}
+
+func TestSeederFiles(t *testing.T) {
+	seeder := NewSeeder(fstest.MapFS{
+		"dev/002_authors.sql":    {Data: []byte("SELECT 2;")},
+		"dev/001_publishers.sql": {Data: []byte("SELECT 1;")},
+		"dev/README.md":          {Data: []byte("ignorado")},
+		"demo/001_books.sql":     {Data: []byte("SELECT 3;")},
+	})
+
+	t.Run("lista os arquivos .sql do conjunto em ordem", func(t *testing.T) {
+		files, err := seeder.Files("dev")
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		expected := []string{"dev/001_publishers.sql", "dev/002_authors.sql"}
+		if !slices.Equal(expected, files) {
+			t.Errorf("Expected: %v, Got: %v", expected, files)
+		}
+	})
+
+	t.Run("retorna ErrSeedSetNotFound para um conjunto inexistente", func(t *testing.T) {
+		_, err := seeder.Files("production")
+		if !errors.Is(err, ErrSeedSetNotFound) {
+			t.Errorf("Expected: ErrSeedSetNotFound, Got: %v", err)
+		}
+	})
+
+	t.Run("lista os conjuntos disponíveis", func(t *testing.T) {
+		sets, err := seeder.Sets()
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		expected := []string{"demo", "dev"}
+		if !slices.Equal(expected, sets) {
+			t.Errorf("Expected: %v, Got: %v", expected, sets)
+		}
+	})
+}
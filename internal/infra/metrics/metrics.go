@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "lucienne"
+
+// Registry agrupa todas as métricas expostas em /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total de requisições HTTP, por método, rota e status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duração das requisições HTTP, por método e rota.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duração das consultas ao banco de dados, por repositório e método.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"repository", "method"})
+
+	// AuthorsCreated conta os autores criados com sucesso.
+	AuthorsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "authors_created_total",
+		Help:      "Total de autores criados.",
+	})
+
+	// AuthorDeletionConflicts conta as remoções de autor recusadas por existirem livros associados.
+	AuthorDeletionConflicts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "author_deletion_conflicts_total",
+		Help:      "Total de remoções de autor recusadas por existirem livros associados.",
+	})
+
+	// PublishersCreated conta as editoras criadas com sucesso.
+	PublishersCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "publishers_created_total",
+		Help:      "Total de editoras criadas.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		queryDuration,
+		AuthorsCreated,
+		AuthorDeletionConflicts,
+		PublishersCreated,
+	)
+}
+
+// Handler retorna o handler HTTP que expõe as métricas no formato do Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest registra uma requisição HTTP atendida pela rota informada.
+func ObserveRequest(method string, route string, status string, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, status).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveQuery inicia a medição de uma consulta e retorna a função que a encerra.
+// Uso: defer metrics.ObserveQuery("authors", "GetAuthors")()
+func ObserveQuery(repository string, method string) func() {
+	start := time.Now()
+	return func() {
+		queryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterPool expõe as estatísticas do pool de conexões do banco de dados.
+func RegisterPool(pool *pgxpool.Pool) {
+	Registry.MustRegister(&poolCollector{pool: pool})
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrAPITokenNotFound é retornado quando o token não existe, pertence a outro usuário ou já foi revogado.
+var ErrAPITokenNotFound = errors.New("token de API não encontrado")
+
+const (
+	createAPITokenQuery = `INSERT INTO api_tokens (user_id, name, token_hash, prefix, scopes, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+	selectAPITokenQuery    = `SELECT id, user_id, name, token_hash, prefix, scopes, expires_at, created_at, last_used_at, revoked_at FROM api_tokens`
+	getUserAPITokensQuery  = selectAPITokenQuery + ` WHERE user_id = $1 ORDER BY created_at DESC`
+	getAPITokenByHashQuery = selectAPITokenQuery + ` WHERE token_hash = $1`
+	touchAPITokenQuery     = `UPDATE api_tokens SET last_used_at = $2 WHERE id = $1`
+	revokeAPITokenQuery    = `UPDATE api_tokens SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+)
+
+// APITokenRepository define a interface para as operações de tokens de API no banco de dados.
+type APITokenRepository interface {
+	CreateAPIToken(ctx context.Context, token *domain.APIToken) error
+	GetUserAPITokens(ctx context.Context, userID int64) ([]domain.APIToken, error)
+	GetAPITokenByHash(ctx context.Context, hash string) (*domain.APIToken, error)
+	TouchAPIToken(ctx context.Context, id int64, lastUsedAt time.Time) error
+	RevokeAPIToken(ctx context.Context, userID int64, id int64) error
+}
+
+// PostgresAPITokenRepository é a implementação do APITokenRepository para o PostgreSQL.
+type PostgresAPITokenRepository struct{}
+
+// NewPostgresAPITokenRepository cria uma nova instância do repositório.
+func NewPostgresAPITokenRepository() *PostgresAPITokenRepository {
+	return &PostgresAPITokenRepository{}
+}
+
+// CreateAPIToken insere um novo token, preenchendo o ID e a data de criação.
+func (r *PostgresAPITokenRepository) CreateAPIToken(ctx context.Context, token *domain.APIToken) error {
+	defer metrics.ObserveQuery("api_tokens", "CreateAPIToken")()
+
+	return database.Conn.QueryRow(ctx, createAPITokenQuery,
+		token.UserID, token.Name, token.TokenHash, token.Prefix, token.Scopes, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetUserAPITokens retorna os tokens do usuário, inclusive os revogados e expirados, do mais novo ao mais antigo.
+func (r *PostgresAPITokenRepository) GetUserAPITokens(ctx context.Context, userID int64) ([]domain.APIToken, error) {
+	defer metrics.ObserveQuery("api_tokens", "GetUserAPITokens")()
+
+	rows, err := database.Conn.Query(ctx, getUserAPITokensQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []domain.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+// GetAPITokenByHash busca um token pelo hash, mesmo que revogado ou expirado.
+func (r *PostgresAPITokenRepository) GetAPITokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	defer metrics.ObserveQuery("api_tokens", "GetAPITokenByHash")()
+	return scanAPIToken(database.Conn.QueryRow(ctx, getAPITokenByHashQuery, hash))
+}
+
+// TouchAPIToken registra o último uso do token.
+func (r *PostgresAPITokenRepository) TouchAPIToken(ctx context.Context, id int64, lastUsedAt time.Time) error {
+	defer metrics.ObserveQuery("api_tokens", "TouchAPIToken")()
+
+	_, err := database.Conn.Exec(ctx, touchAPITokenQuery, id, lastUsedAt)
+	return err
+}
+
+// RevokeAPIToken revoga um token do usuário. O registro é mantido, para que o token continue listado como revogado.
+func (r *PostgresAPITokenRepository) RevokeAPIToken(ctx context.Context, userID int64, id int64) error {
+	defer metrics.ObserveQuery("api_tokens", "RevokeAPIToken")()
+
+	res, err := database.Conn.Exec(ctx, revokeAPITokenQuery, id, userID)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+func scanAPIToken(row pgx.Row) (*domain.APIToken, error) {
+	var token domain.APIToken
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.TokenHash, &token.Prefix, &token.Scopes,
+		&token.ExpiresAt, &token.CreatedAt, &token.LastUsedAt, &token.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAPITokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostgresAPITokenRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	users := repository.NewPostgresUserRepository()
+	repo := repository.NewPostgresAPITokenRepository()
+
+	user := &domain.User{Email: "ana@example.com", Name: "Ana", PasswordHash: "x"}
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("Falha ao criar usuário: %v", err)
+	}
+
+	token := &domain.APIToken{
+		UserID:    user.ID,
+		Name:      "sincronização",
+		TokenHash: strings.Repeat("a", 64),
+		Prefix:    "lct_aaaaaa",
+		Scopes:    []string{"authors:read", "publishers:read"},
+		ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Microsecond),
+	}
+	if err := repo.CreateAPIToken(ctx, token); err != nil {
+		t.Fatalf("CreateAPIToken retornou um erro inesperado: %v", err)
+	}
+	if token.ID == 0 {
+		t.Fatal("Esperava o ID do token preenchido")
+	}
+
+	t.Run("deve buscar o token pelo hash e registrar o último uso", func(t *testing.T) {
+		lastUsed := time.Now().Truncate(time.Microsecond)
+		if err := repo.TouchAPIToken(ctx, token.ID, lastUsed); err != nil {
+			t.Fatalf("TouchAPIToken retornou um erro inesperado: %v", err)
+		}
+		found, err := repo.GetAPITokenByHash(ctx, token.TokenHash)
+		if err != nil {
+			t.Fatalf("GetAPITokenByHash retornou um erro inesperado: %v", err)
+		}
+		if found.ID != token.ID || len(found.Scopes) != 2 || found.LastUsedAt == nil || !found.LastUsedAt.Equal(lastUsed) {
+			t.Errorf("Token inesperado: %+v", found)
+		}
+	})
+
+	t.Run("deve retornar ErrAPITokenNotFound para um hash desconhecido", func(t *testing.T) {
+		if _, err := repo.GetAPITokenByHash(ctx, strings.Repeat("b", 64)); !errors.Is(err, repository.ErrAPITokenNotFound) {
+			t.Errorf("Esperava ErrAPITokenNotFound, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve revogar apenas o token do próprio usuário", func(t *testing.T) {
+		if err := repo.RevokeAPIToken(ctx, user.ID+1, token.ID); !errors.Is(err, repository.ErrAPITokenNotFound) {
+			t.Errorf("Esperava ErrAPITokenNotFound, mas obtive %v", err)
+		}
+		if err := repo.RevokeAPIToken(ctx, user.ID, token.ID); err != nil {
+			t.Fatalf("RevokeAPIToken retornou um erro inesperado: %v", err)
+		}
+		if err := repo.RevokeAPIToken(ctx, user.ID, token.ID); !errors.Is(err, repository.ErrAPITokenNotFound) {
+			t.Errorf("Esperava ErrAPITokenNotFound ao revogar de novo, mas obtive %v", err)
+		}
+
+		tokens, err := repo.GetUserAPITokens(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetUserAPITokens retornou um erro inesperado: %v", err)
+		}
+		if len(tokens) != 1 || tokens[0].RevokedAt == nil {
+			t.Errorf("Esperava o token revogado na lista, mas obtive %+v", tokens)
+		}
+	})
+}
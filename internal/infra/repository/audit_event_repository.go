@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"lucienne/internal/audit"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+	"lucienne/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	recordAuditEventQuery = `INSERT INTO audit_events (actor_id, actor_email, action, entity_type, entity_id, before, after, request_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	// Os filtros vazios são ignorados, para que a consulta seja sempre a mesma.
+	getAuditEventsQuery = `SELECT id, occurred_at, actor_id, COALESCE(actor_email, ''), action, entity_type, entity_id, before, after, COALESCE(request_id, '')
+	FROM audit_events
+	WHERE ($1::text = '' OR entity_type = $1) AND ($2::bigint = 0 OR entity_id = $2) AND ($3::bigint = 0 OR id < $3)
+	ORDER BY id DESC
+	LIMIT $4`
+)
+
+// AuditEventRepository define a interface para a consulta do histórico de alterações. Os eventos são gravados
+// pelos próprios repositórios do catálogo, na transação de cada alteração.
+type AuditEventRepository interface {
+	GetAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error)
+}
+
+// PostgresAuditEventRepository é a implementação do AuditEventRepository para o PostgreSQL.
+type PostgresAuditEventRepository struct{}
+
+// NewPostgresAuditEventRepository cria uma nova instância do repositório.
+func NewPostgresAuditEventRepository() *PostgresAuditEventRepository {
+	return &PostgresAuditEventRepository{}
+}
+
+// GetAuditEvents lista os eventos do histórico, dos mais recentes para os mais antigos.
+func (r *PostgresAuditEventRepository) GetAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	defer metrics.ObserveQuery("audit_events", "GetAuditEvents")()
+
+	rows, err := database.Conn.Query(ctx, getAuditEventsQuery, filter.EntityType, filter.EntityID, filter.BeforeID, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.AuditEvent{}
+	for rows.Next() {
+		var event domain.AuditEvent
+		err := rows.Scan(
+			&event.ID, &event.OccurredAt, &event.ActorID, &event.ActorEmail, &event.Action,
+			&event.EntityType, &event.EntityID, &event.Before, &event.After, &event.RequestID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// recordAuditEvent grava a alteração no histórico dentro de tx, com o autor e o ID da requisição de ctx. before e
+// after são os campos do registro antes e depois da alteração; apenas os que mudaram são gravados, e uma
+// atualização que não muda nada não é registrada.
+func recordAuditEvent(ctx context.Context, tx pgx.Tx, action string, entityType string, entityID int64, before map[string]any, after map[string]any) error {
+	beforeJSON, afterJSON, changed, err := audit.Diff(before, after)
+	if err != nil || !changed {
+		return err
+	}
+
+	var actorID *int64
+	var actorEmail, requestID *string
+	if actor, ok := audit.ActorFrom(ctx); ok {
+		actorID = &actor.UserID
+		actorEmail = &actor.Email
+	}
+	if id := logger.RequestID(ctx); id != "" {
+		requestID = &id
+	}
+
+	_, err = tx.Exec(ctx, recordAuditEventQuery, actorID, actorEmail, action, entityType, entityID, beforeJSON, afterJSON, requestID)
+	return err
+}
@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"lucienne/internal/audit"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/logger"
+	"testing"
+)
+
+func TestPostgresAuditEventRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := logger.WithRequestID(audit.WithActor(context.Background(), audit.Actor{UserID: 7, Email: "ana@example.com"}), "req-1")
+	authors := repository.NewPostgresAuthorRepository()
+	publishers := repository.NewPostgresPublisherRepository()
+	repo := repository.NewPostgresAuditEventRepository()
+
+	author := &domain.Author{Name: "Neil"}
+	if err := authors.CreateAuthor(ctx, author); err != nil {
+		t.Fatalf("Falha ao criar autor: %v", err)
+	}
+	if err := authors.UpdateAuthor(ctx, int(author.ID), "Neil Gaiman"); err != nil {
+		t.Fatalf("Falha ao atualizar autor: %v", err)
+	}
+	if err := authors.UpdateAuthor(ctx, int(author.ID), "Neil Gaiman"); err != nil {
+		t.Fatalf("Falha ao atualizar autor: %v", err)
+	}
+	if err := authors.RemoveAuthor(ctx, author.ID); err != nil {
+		t.Fatalf("Falha ao remover autor: %v", err)
+	}
+	publisher := &domain.Publisher{Name: "Rocco"}
+	if err := publishers.CreatePublisher(context.Background(), publisher); err != nil {
+		t.Fatalf("Falha ao criar editora: %v", err)
+	}
+
+	t.Run("deve registrar cada alteração do registro com o autor e a requisição", func(t *testing.T) {
+		events, err := repo.GetAuditEvents(ctx, domain.AuditFilter{EntityType: domain.AuditEntityAuthor, EntityID: author.ID, Limit: 10})
+		if err != nil {
+			t.Fatalf("GetAuditEvents retornou um erro inesperado: %v", err)
+		}
+		if len(events) != 3 {
+			t.Fatalf("Esperava 3 eventos, sem a atualização que não mudou nada, mas obtive %d", len(events))
+		}
+		expected := []struct{ action, before, after string }{
+			{domain.AuditActionDelete, `{"name": "Neil Gaiman"}`, ``},
+			{domain.AuditActionUpdate, `{"name": "Neil"}`, `{"name": "Neil Gaiman"}`},
+			{domain.AuditActionCreate, ``, `{"name": "Neil"}`},
+		}
+		for i, event := range events {
+			if event.Action != expected[i].action || string(event.Before) != expected[i].before || string(event.After) != expected[i].after {
+				t.Errorf("Esperava o evento %+v, mas obtive %s %s %s", expected[i], event.Action, event.Before, event.After)
+			}
+			if event.ActorID == nil || *event.ActorID != 7 || event.ActorEmail != "ana@example.com" || event.RequestID != "req-1" {
+				t.Errorf("Esperava o autor e a requisição do contexto, mas obtive %+v", event)
+			}
+		}
+	})
+
+	t.Run("deve paginar e registrar alterações sem autor", func(t *testing.T) {
+		events, err := repo.GetAuditEvents(ctx, domain.AuditFilter{Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 1 || events[0].EntityType != domain.AuditEntityPublisher || events[0].ActorID != nil {
+			t.Fatalf("Esperava a criação da editora sem autor, mas obtive %+v", events)
+		}
+
+		older, err := repo.GetAuditEvents(ctx, domain.AuditFilter{BeforeID: events[0].ID, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(older) != 3 {
+			t.Errorf("Esperava 3 eventos anteriores, mas obtive %d", len(older))
+		}
+	})
+
+	t.Run("deve recusar a alteração do histórico", func(t *testing.T) {
+		if _, err := database.Conn.Exec(ctx, `UPDATE audit_events SET action = 'create'`); err == nil {
+			t.Error("Esperava um erro ao alterar o histórico")
+		}
+		if _, err := database.Conn.Exec(ctx, `DELETE FROM audit_events`); err == nil {
+			t.Error("Esperava um erro ao remover o histórico")
+		}
+	})
+}
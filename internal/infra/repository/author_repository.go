@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"lucienne/internal/domain"
 	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
@@ -29,12 +31,15 @@ var (
 )
 
 const (
-	// Não precisamos retornar o ID por enquanto, então usamos um INSERT simples.
-	createAuthorQuery     = `INSERT INTO authors (name) VALUES ($1)`
-	updateAuthorQuery     = `UPDATE authors SET name = $1 WHERE id = $2`
-	getAuthorByIDQuery    = `SELECT id, name FROM authors WHERE id = $1`
-	removeAuthorByIDQuery = `DELETE FROM authors WHERE id = $1`
-	getAuthorsQuery       = `SELECT id, name FROM authors ORDER BY name ASC`
+	createAuthorQuery  = `INSERT INTO authors (name) VALUES ($1) RETURNING id`
+	updateAuthorQuery  = `UPDATE authors SET name = $1 WHERE id = $2`
+	getAuthorByIDQuery = `SELECT id, name FROM authors WHERE id = $1 AND deleted_at IS NULL`
+	lockAuthorQuery    = `SELECT name FROM authors WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	// A remoção leva o autor para a lixeira; o FOREIGN KEY de books não a impede, então os livros ativos são
+	// verificados antes.
+	authorHasBooksQuery   = `SELECT EXISTS (SELECT 1 FROM books WHERE author_id = $1 AND deleted_at IS NULL)`
+	removeAuthorByIDQuery = `UPDATE authors SET deleted_at = now() WHERE id = $1`
+	getAuthorsQuery       = `SELECT id, name FROM authors WHERE deleted_at IS NULL ORDER BY name ASC`
 )
 
 // AuthorRepository define a interface para as operações de autor no banco de dados.
@@ -57,13 +62,18 @@ func NewPostgresAuthorRepository() *PostgresAuthorRepository {
 }
 
 func (r *PostgresAuthorRepository) GetAuthors(ctx context.Context) ([]domain.Author, error) {
+	defer metrics.ObserveQuery("authors", "GetAuthors")()
+
 	rows, err := database.Conn.Query(ctx, getAuthorsQuery)
 	if err != nil {
+		// O erro original é substituído por ErrSearchAuthors, então é registrado aqui para não se perder.
+		slog.ErrorContext(ctx, "erro ao buscar autores", "error", err)
 		return nil, ErrSearchAuthors
 	}
 
 	authors, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.Author])
 	if err != nil {
+		slog.ErrorContext(ctx, "erro ao ler autores", "error", err)
 		return nil, ErrSearchAuthors
 	}
 	return authors, nil
@@ -71,6 +81,8 @@ func (r *PostgresAuthorRepository) GetAuthors(ctx context.Context) ([]domain.Aut
 
 // GetAuthorByID busca um autor pelo ID.
 func (r *PostgresAuthorRepository) GetAuthorByID(ctx context.Context, id int64) (*domain.Author, error) {
+	defer metrics.ObserveQuery("authors", "GetAuthorByID")()
+
 	row := database.Conn.QueryRow(ctx, getAuthorByIDQuery, id)
 	var author domain.Author
 	err := row.Scan(&author.ID, &author.Name)
@@ -83,9 +95,16 @@ func (r *PostgresAuthorRepository) GetAuthorByID(ctx context.Context, id int64)
 	return &author, nil
 }
 
-// CreateAuthor insere um novo autor no banco de dados.
+// CreateAuthor insere um novo autor no banco de dados, preenchendo o ID. A criação é registrada no histórico.
 func (r *PostgresAuthorRepository) CreateAuthor(ctx context.Context, author *domain.Author) error {
-	_, err := database.Conn.Exec(ctx, createAuthorQuery, author.Name)
+	defer metrics.ObserveQuery("authors", "CreateAuthor")()
+
+	err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, createAuthorQuery, author.Name).Scan(&author.ID); err != nil {
+			return err
+		}
+		return recordAuditEvent(ctx, tx, domain.AuditActionCreate, domain.AuditEntityAuthor, author.ID, nil, authorFields(author.Name))
+	})
 	if err != nil {
 		// Verifica se o erro é uma violação de chave única (unique_violation).
 		// O código '23505' é o código de erro padrão do PostgreSQL para isso.
@@ -96,17 +115,33 @@ func (r *PostgresAuthorRepository) CreateAuthor(ctx context.Context, author *dom
 		// Se for outro tipo de erro, retorna o erro original.
 		return err
 	}
+
+	metrics.AuthorsCreated.Inc()
 	return nil
 }
 
-// UpdateAuthor atualiza o nome de um autor existente no banco de dados.
+// UpdateAuthor atualiza o nome de um autor existente no banco de dados. A alteração é registrada no histórico.
 func (r *PostgresAuthorRepository) UpdateAuthor(ctx context.Context, id int, name string) error {
 	// Adiciona validação para impedir nomes vazios.
 	if strings.TrimSpace(name) == "" {
 		return ErrAuthorNameCannotBeEmpty
 	}
 
-	res, err := database.Conn.Exec(ctx, updateAuthorQuery, name, id)
+	defer metrics.ObserveQuery("authors", "UpdateAuthor")()
+	err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		// O nome anterior é lido com a linha travada, para que o histórico não misture alterações simultâneas.
+		var before string
+		if err := tx.QueryRow(ctx, lockAuthorQuery, id).Scan(&before); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrAuthorNotFound
+			}
+			return err
+		}
+		if _, err := tx.Exec(ctx, updateAuthorQuery, name, id); err != nil {
+			return err
+		}
+		return recordAuditEvent(ctx, tx, domain.AuditActionUpdate, domain.AuditEntityAuthor, int64(id), authorFields(before), authorFields(name))
+	})
 	if err != nil {
 		// Adiciona tratamento para erro de nome duplicado
 		var pgErr *pgconn.PgError
@@ -115,29 +150,41 @@ func (r *PostgresAuthorRepository) UpdateAuthor(ctx context.Context, id int, nam
 		}
 		return err
 	}
-
-	// Verifica se alguma linha foi de fato alterada.
-	rows := res.RowsAffected()
-	if rows == 0 {
-		return ErrAuthorNotFound
-	}
 	return nil
 }
 
-// RemoveAuthor remove um autor do banco de dados, mas somente se ele não tiver livros associados.
+// RemoveAuthor move um autor para a lixeira, mas somente se ele não tiver livros associados. A remoção é
+// registrada no histórico.
 func (r *PostgresAuthorRepository) RemoveAuthor(ctx context.Context, id int64) error {
-	res, err := database.Conn.Exec(ctx, removeAuthorByIDQuery, id)
-	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+	defer metrics.ObserveQuery("authors", "RemoveAuthor")()
+
+	err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		var before string
+		if err := tx.QueryRow(ctx, lockAuthorQuery, id).Scan(&before); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrAuthorNotFound
+			}
+			return err
+		}
+		var hasBooks bool
+		if err := tx.QueryRow(ctx, authorHasBooksQuery, id).Scan(&hasBooks); err != nil {
+			return err
+		}
+		if hasBooks {
 			return ErrAuthorHasBooks
 		}
-		return err
-	}
-
-	if res.RowsAffected() == 0 {
-		return ErrAuthorNotFound
+		if _, err := tx.Exec(ctx, removeAuthorByIDQuery, id); err != nil {
+			return err
+		}
+		return recordAuditEvent(ctx, tx, domain.AuditActionDelete, domain.AuditEntityAuthor, id, authorFields(before), nil)
+	})
+	if errors.Is(err, ErrAuthorHasBooks) {
+		metrics.AuthorDeletionConflicts.Inc()
 	}
+	return err
+}
 
-	return nil
+// authorFields são os campos do autor gravados no histórico.
+func authorFields(name string) map[string]any {
+	return map[string]any{"name": name}
 }
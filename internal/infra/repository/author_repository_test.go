@@ -9,7 +9,6 @@ import (
 
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/jackc/pgx/v5"
 )
 
 const (
@@ -194,10 +193,15 @@ func TestPostgresAuthorRepository_RemoveAuthor(t *testing.T) {
 			t.Errorf("esperava sucesso na remoção, mas obteve erro: %v", err)
 		}
 
-		var name string
-		err = database.Conn.QueryRow(ctx, selectQuery, authorID).Scan(&name)
-		if !errors.Is(err, pgx.ErrNoRows) {
-			t.Errorf("esperava erro pgx.ErrNoRows ao buscar autor removido, mas obteve: %v", err)
+		if _, err := repo.GetAuthorByID(ctx, authorID); !errors.Is(err, repository.ErrAuthorNotFound) {
+			t.Errorf("esperava erro ErrAuthorNotFound ao buscar autor removido, mas obteve: %v", err)
+		}
+
+		// O autor vai para a lixeira em vez de ser apagado.
+		var deleted bool
+		err = database.Conn.QueryRow(ctx, "SELECT deleted_at IS NOT NULL FROM authors WHERE id = $1", authorID).Scan(&deleted)
+		if err != nil || !deleted {
+			t.Errorf("esperava o autor removido na lixeira, mas obteve: %v, %v", deleted, err)
 		}
 	})
 
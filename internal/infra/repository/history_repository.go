@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrHistoryRecordNotFound é retornado quando o registro não existe ou, ao reverter, está na lixeira.
+	ErrHistoryRecordNotFound = errors.New("registro não encontrado")
+
+	// ErrVersionNotFound é retornado quando o registro não tem a versão pedida.
+	ErrVersionNotFound = errors.New("versão não encontrada")
+
+	// ErrRevertNameInUse é retornado ao reverter para um nome que outro registro ativo usa agora.
+	ErrRevertNameInUse = errors.New("o nome da versão está em uso")
+
+	// ErrRevertAuthorDeleted é retornado ao reverter um livro para um autor que está na lixeira ou foi apagado.
+	ErrRevertAuthorDeleted = errors.New("o autor da versão não está ativo")
+)
+
+const activeAuthorQuery = `SELECT EXISTS (SELECT 1 FROM authors WHERE id = $1 AND deleted_at IS NULL)`
+
+// versionTable reúne as consultas do histórico de uma tabela. As versões são gravadas pelos triggers da
+// tabela; o repositório apenas as consulta e reverte o registro.
+type versionTable struct {
+	versions string
+	at       string
+	version  string
+	// lock trava o registro ativo e retorna os campos atuais, para o histórico de alterações.
+	lock   string
+	revert string
+	// check valida os campos da versão antes da reversão.
+	check func(ctx context.Context, tx pgx.Tx, fields map[string]any) error
+}
+
+// newVersionTable monta as consultas de table, cujas versões estão em versions com a chave key. columns são os
+// campos restaurados pela reversão.
+func newVersionTable(table string, versions string, key string, columns string) versionTable {
+	fields := `to_jsonb(v) - '` + key + `' - 'version' - 'valid_from' - 'valid_to'`
+	selectVersion := `SELECT version, valid_from, valid_to, ` + fields + ` FROM ` + versions + ` v WHERE ` + key + ` = $1`
+	return versionTable{
+		versions: selectVersion + ` ORDER BY version DESC`,
+		at:       selectVersion + ` AND valid_from <= $2 AND (valid_to IS NULL OR valid_to > $2)`,
+		version:  selectVersion + ` AND version = $2`,
+		lock:     `SELECT to_jsonb(t) - 'id' - 'deleted_at' FROM ` + table + ` t WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		revert: `UPDATE ` + table + ` SET (` + columns + `) = (SELECT ` + columns + ` FROM ` + versions + `
+		WHERE ` + key + ` = $1 AND version = $2) WHERE id = $1`,
+	}
+}
+
+var versionTables = map[string]versionTable{
+	domain.AuditEntityAuthor: newVersionTable("authors", "author_versions", "author_id", "name"),
+	domain.AuditEntityBook: func() versionTable {
+		table := newVersionTable("books", "book_versions", "book_id", "name, author_id")
+		table.check = func(ctx context.Context, tx pgx.Tx, fields map[string]any) error {
+			// Os números em JSON são lidos como float64.
+			authorID, _ := fields["author_id"].(float64)
+			var active bool
+			if err := tx.QueryRow(ctx, activeAuthorQuery, int64(authorID)).Scan(&active); err != nil {
+				return err
+			}
+			if !active {
+				return ErrRevertAuthorDeleted
+			}
+			return nil
+		}
+		return table
+	}(),
+}
+
+// HistoryRepository define a interface para o histórico de versões de autores e livros.
+type HistoryRepository interface {
+	GetVersions(ctx context.Context, entityType string, id int64) ([]domain.EntityVersion, error)
+	GetVersionAt(ctx context.Context, entityType string, id int64, at time.Time) (*domain.EntityVersion, error)
+	RevertToVersion(ctx context.Context, entityType string, id int64, version int) error
+}
+
+// PostgresHistoryRepository é a implementação do HistoryRepository para o PostgreSQL.
+type PostgresHistoryRepository struct{}
+
+// NewPostgresHistoryRepository cria uma nova instância do repositório.
+func NewPostgresHistoryRepository() *PostgresHistoryRepository {
+	return &PostgresHistoryRepository{}
+}
+
+// GetVersions lista as versões do registro, da mais recente para a mais antiga.
+func (r *PostgresHistoryRepository) GetVersions(ctx context.Context, entityType string, id int64) ([]domain.EntityVersion, error) {
+	defer metrics.ObserveQuery("versions", "GetVersions")()
+
+	table, ok := versionTables[entityType]
+	if !ok {
+		return nil, ErrHistoryRecordNotFound
+	}
+	rows, err := database.Conn.Query(ctx, table.versions, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []domain.EntityVersion{}
+	for rows.Next() {
+		version, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrHistoryRecordNotFound
+	}
+	return versions, nil
+}
+
+// GetVersionAt retorna a versão do registro em vigor no instante at.
+func (r *PostgresHistoryRepository) GetVersionAt(ctx context.Context, entityType string, id int64, at time.Time) (*domain.EntityVersion, error) {
+	defer metrics.ObserveQuery("versions", "GetVersionAt")()
+
+	table, ok := versionTables[entityType]
+	if !ok {
+		return nil, ErrHistoryRecordNotFound
+	}
+	return scanVersion(database.Conn.QueryRow(ctx, table.at, id, at))
+}
+
+// RevertToVersion devolve os campos do registro aos da versão, o que grava uma nova versão. O registro precisa
+// estar ativo; os que estão na lixeira são restaurados por ela. A reversão é registrada no histórico de
+// alterações como uma atualização.
+func (r *PostgresHistoryRepository) RevertToVersion(ctx context.Context, entityType string, id int64, version int) error {
+	defer metrics.ObserveQuery("versions", "RevertToVersion")()
+
+	table, ok := versionTables[entityType]
+	if !ok {
+		return ErrHistoryRecordNotFound
+	}
+	err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		var current map[string]any
+		if err := tx.QueryRow(ctx, table.lock, id).Scan(&current); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrHistoryRecordNotFound
+			}
+			return err
+		}
+		target, err := scanVersion(tx.QueryRow(ctx, table.version, id, version))
+		if err != nil {
+			return err
+		}
+
+		// Apenas os campos restaurados entram na comparação; deleted_at continua como está.
+		after := map[string]any{}
+		for field := range current {
+			after[field] = target.Fields[field]
+		}
+		if table.check != nil {
+			if err := table.check(ctx, tx, after); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(ctx, table.revert, id, version); err != nil {
+			return err
+		}
+		return recordAuditEvent(ctx, tx, domain.AuditActionUpdate, entityType, id, current, after)
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrRevertNameInUse
+	}
+	return err
+}
+
+func scanVersion(row pgx.Row) (*domain.EntityVersion, error) {
+	var version domain.EntityVersion
+	err := row.Scan(&version.Version, &version.ValidFrom, &version.ValidTo, &version.Fields)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrVersionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
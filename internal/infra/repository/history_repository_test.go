@@ -0,0 +1,103 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/repository"
+	"testing"
+	"time"
+)
+
+func TestPostgresHistoryRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	authors := repository.NewPostgresAuthorRepository()
+	repo := repository.NewPostgresHistoryRepository()
+
+	author := &domain.Author{Name: "Neil"}
+	if err := authors.CreateAuthor(ctx, author); err != nil {
+		t.Fatalf("Falha ao criar autor: %v", err)
+	}
+	created := time.Now()
+	if err := authors.UpdateAuthor(ctx, int(author.ID), "Neil Gaiman"); err != nil {
+		t.Fatalf("Falha ao atualizar autor: %v", err)
+	}
+
+	t.Run("deve gravar uma versão a cada alteração", func(t *testing.T) {
+		if err := authors.UpdateAuthor(ctx, int(author.ID), "Neil Gaiman"); err != nil {
+			t.Fatal(err)
+		}
+		versions, err := repo.GetVersions(ctx, domain.AuditEntityAuthor, author.ID)
+		if err != nil {
+			t.Fatalf("GetVersions retornou um erro inesperado: %v", err)
+		}
+		if len(versions) != 2 || versions[0].Version != 2 || !versions[0].Current() || versions[1].Fields["name"] != "Neil" || versions[1].ValidTo == nil {
+			t.Fatalf("Esperava 2 versões, sem a atualização que não mudou nada, mas obtive %+v", versions)
+		}
+		if changes := versions[0].Changes(&versions[1]); len(changes) != 1 || changes[0].After != "Neil Gaiman" {
+			t.Errorf("Esperava a mudança do nome, mas obtive %+v", changes)
+		}
+	})
+
+	t.Run("deve retornar a versão em vigor na data", func(t *testing.T) {
+		version, err := repo.GetVersionAt(ctx, domain.AuditEntityAuthor, author.ID, created)
+		if err != nil {
+			t.Fatalf("GetVersionAt retornou um erro inesperado: %v", err)
+		}
+		if version.Version != 1 {
+			t.Errorf("Esperava a versão 1, mas obtive %+v", version)
+		}
+		if _, err := repo.GetVersionAt(ctx, domain.AuditEntityAuthor, author.ID, created.Add(-time.Hour)); !errors.Is(err, repository.ErrVersionNotFound) {
+			t.Errorf("Esperava ErrVersionNotFound antes da criação, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve reverter para uma versão anterior", func(t *testing.T) {
+		if err := repo.RevertToVersion(ctx, domain.AuditEntityAuthor, author.ID, 1); err != nil {
+			t.Fatalf("RevertToVersion retornou um erro inesperado: %v", err)
+		}
+		reverted, err := authors.GetAuthorByID(ctx, author.ID)
+		if err != nil || reverted.Name != "Neil" {
+			t.Errorf("Esperava o nome da versão 1, mas obtive %+v (%v)", reverted, err)
+		}
+		versions, err := repo.GetVersions(ctx, domain.AuditEntityAuthor, author.ID)
+		if err != nil || len(versions) != 3 {
+			t.Errorf("Esperava a reversão como uma nova versão, mas obtive %+v (%v)", versions, err)
+		}
+		if err := repo.RevertToVersion(ctx, domain.AuditEntityAuthor, author.ID, 9); !errors.Is(err, repository.ErrVersionNotFound) {
+			t.Errorf("Esperava ErrVersionNotFound, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve recusar um nome em uso por outro autor", func(t *testing.T) {
+		if err := authors.CreateAuthor(ctx, &domain.Author{Name: "Neil Gaiman"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.RevertToVersion(ctx, domain.AuditEntityAuthor, author.ID, 2); !errors.Is(err, repository.ErrRevertNameInUse) {
+			t.Errorf("Esperava ErrRevertNameInUse, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve exigir o autor ativo do livro", func(t *testing.T) {
+		other := &domain.Author{Name: "Terry Pratchett"}
+		if err := authors.CreateAuthor(ctx, other); err != nil {
+			t.Fatal(err)
+		}
+		var bookID int64
+		if err := database.Conn.QueryRow(ctx, "INSERT INTO books (name, author_id) VALUES ('Coraline', $1) RETURNING id", other.ID).Scan(&bookID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := database.Conn.Exec(ctx, "UPDATE books SET author_id = $1 WHERE id = $2", author.ID, bookID); err != nil {
+			t.Fatal(err)
+		}
+		if err := authors.RemoveAuthor(ctx, other.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repo.RevertToVersion(ctx, domain.AuditEntityBook, bookID, 1); !errors.Is(err, repository.ErrRevertAuthorDeleted) {
+			t.Errorf("Esperava ErrRevertAuthorDeleted, mas obtive %v", err)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrIdentityAlreadyLinked é retornado ao ligar uma conta do provedor de identidade que já pertence a um usuário.
+var ErrIdentityAlreadyLinked = errors.New("identidade já ligada a um usuário")
+
+const (
+	getUserByIdentityQuery = selectUserQuery + ` WHERE users.id = (
+		SELECT user_id FROM user_identities WHERE issuer = $1 AND subject = $2)`
+	linkIdentityQuery = `INSERT INTO user_identities (user_id, issuer, subject) VALUES ($1, $2, $3)`
+)
+
+// IdentityRepository define a interface para as contas dos provedores de identidade ligadas aos usuários.
+type IdentityRepository interface {
+	GetUserByIdentity(ctx context.Context, issuer string, subject string) (*domain.User, error)
+	LinkIdentity(ctx context.Context, userID int64, issuer string, subject string) error
+}
+
+// PostgresIdentityRepository é a implementação do IdentityRepository para o PostgreSQL.
+type PostgresIdentityRepository struct{}
+
+// NewPostgresIdentityRepository cria uma nova instância do repositório.
+func NewPostgresIdentityRepository() *PostgresIdentityRepository {
+	return &PostgresIdentityRepository{}
+}
+
+// GetUserByIdentity busca o usuário ligado à conta do provedor, retornando ErrUserNotFound se não houver.
+func (r *PostgresIdentityRepository) GetUserByIdentity(ctx context.Context, issuer string, subject string) (*domain.User, error) {
+	defer metrics.ObserveQuery("user_identities", "GetUserByIdentity")()
+	return scanUser(database.Conn.QueryRow(ctx, getUserByIdentityQuery, issuer, subject))
+}
+
+// LinkIdentity liga a conta do provedor ao usuário.
+func (r *PostgresIdentityRepository) LinkIdentity(ctx context.Context, userID int64, issuer string, subject string) error {
+	defer metrics.ObserveQuery("user_identities", "LinkIdentity")()
+
+	_, err := database.Conn.Exec(ctx, linkIdentityQuery, userID, issuer, subject)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return ErrIdentityAlreadyLinked
+			case "23503":
+				return ErrUserNotFound
+			}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"testing"
+)
+
+func TestPostgresIdentityRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	users := repository.NewPostgresUserRepository()
+	repo := repository.NewPostgresIdentityRepository()
+
+	// Os usuários do login único são criados sem senha.
+	user := &domain.User{Email: "ana@escola.edu", Name: "Ana"}
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("Falha ao criar usuário sem senha: %v", err)
+	}
+
+	t.Run("deve retornar ErrUserNotFound para uma conta não ligada", func(t *testing.T) {
+		if _, err := repo.GetUserByIdentity(ctx, "https://idp.escola.edu", "ana-123"); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Esperava ErrUserNotFound, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve ligar a conta e buscar o usuário por ela", func(t *testing.T) {
+		if err := repo.LinkIdentity(ctx, user.ID, "https://idp.escola.edu", "ana-123"); err != nil {
+			t.Fatalf("LinkIdentity retornou um erro inesperado: %v", err)
+		}
+		found, err := repo.GetUserByIdentity(ctx, "https://idp.escola.edu", "ana-123")
+		if err != nil {
+			t.Fatalf("GetUserByIdentity retornou um erro inesperado: %v", err)
+		}
+		if found.ID != user.ID || found.HasPassword() || len(found.Roles) != 1 {
+			t.Errorf("Usuário inesperado: %+v", found)
+		}
+	})
+
+	t.Run("deve recusar uma conta já ligada", func(t *testing.T) {
+		if err := repo.LinkIdentity(ctx, user.ID, "https://idp.escola.edu", "ana-123"); !errors.Is(err, repository.ErrIdentityAlreadyLinked) {
+			t.Errorf("Esperava ErrIdentityAlreadyLinked, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve retornar ErrUserNotFound ao ligar a um usuário inexistente", func(t *testing.T) {
+		if err := repo.LinkIdentity(ctx, user.ID+100, "https://idp.escola.edu", "bia-456"); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Esperava ErrUserNotFound, mas obtive %v", err)
+		}
+	})
+}
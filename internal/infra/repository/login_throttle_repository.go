@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrLoginThrottleNotFound é retornado quando a conta ou o IP não tem falhas de login recentes.
+	ErrLoginThrottleNotFound = errors.New("falhas de login não encontradas")
+
+	// ErrLoginThrottled é retornado quando a tentativa não pode ser reservada, por haver um atraso ou um bloqueio.
+	ErrLoginThrottled = errors.New("tentativas de login recusadas")
+)
+
+const (
+	loginThrottleColumns  = `id, scope, subject, failures, last_ip, last_failure_at, locked_until, locked_at`
+	getLoginThrottleQuery = `SELECT ` + loginThrottleColumns + `, '' FROM login_throttles WHERE scope = $1 AND subject = $2`
+
+	// Os parâmetros da reserva: $1 escopo, $2 assunto, $3 IP, $4 horário, $5 janela, $6 falhas sem atraso,
+	// $7 atraso inicial, $8 falhas para o bloqueio e $9 duração do bloqueio, com as durações em segundos.
+	// As falhas mais antigas que a janela são esquecidas: a contagem recomeça, e o bloqueio anterior também.
+	reserveWindowExpired = `t.last_failure_at < $4 - make_interval(secs => $5::float8)`
+	reserveFailures      = `(CASE WHEN ` + reserveWindowExpired + ` THEN 1 ELSE t.failures + 1 END)`
+	reserveLockedUntil   = `CASE WHEN ` + reserveFailures + ` >= $8::int THEN $4 + make_interval(secs => $9::float8)
+		WHEN ` + reserveFailures + ` >= $6::int THEN $4 + make_interval(secs => LEAST($7::float8 * power(2, ` + reserveFailures + ` - $6::int), $9::float8))
+		ELSE t.locked_until END`
+	reserveLockedAt = `CASE WHEN ` + reserveFailures + ` >= $8::int THEN COALESCE(CASE WHEN ` + reserveWindowExpired + ` THEN NULL ELSE t.locked_at END, $4)
+		WHEN ` + reserveWindowExpired + ` THEN NULL ELSE t.locked_at END`
+	// A tentativa é contada e o atraso ou o bloqueio que ela causa é aplicado na mesma instrução, que só altera a
+	// linha se não houver um atraso em vigor. Como o ON CONFLICT trava a linha, tentativas simultâneas são
+	// conferidas uma depois da outra, e nenhuma passa sem ver as anteriores.
+	reserveLoginAttemptQuery = `INSERT INTO login_throttles AS t (scope, subject, failures, last_ip, last_failure_at, locked_until, locked_at)
+	VALUES ($1, $2, 1, $3, $4,
+		CASE WHEN 1 >= $8::int THEN $4 + make_interval(secs => $9::float8) WHEN 1 >= $6::int THEN $4 + make_interval(secs => $7::float8) END,
+		CASE WHEN 1 >= $8::int THEN $4::timestamptz END)
+	ON CONFLICT (scope, subject) DO UPDATE SET
+		failures = ` + reserveFailures + `,
+		locked_until = ` + reserveLockedUntil + `,
+		locked_at = ` + reserveLockedAt + `,
+		last_ip = $3,
+		last_failure_at = $4
+	WHERE t.locked_until IS NULL OR t.locked_until <= $4
+	RETURNING ` + loginThrottleColumns + `, ''`
+	// O atraso só é retirado se nenhuma outra tentativa foi reservada depois, já que seria dela.
+	releaseLoginAttemptQuery = `UPDATE login_throttles SET
+		failures = GREATEST(failures - 1, 0),
+		locked_until = CASE WHEN last_failure_at = $2 THEN NULL ELSE locked_until END,
+		locked_at = CASE WHEN locked_at = $2 THEN NULL ELSE locked_at END
+	WHERE id = $1`
+	clearLoginThrottleQuery = `DELETE FROM login_throttles WHERE scope = $1 AND subject = $2`
+	getLockedLoginsQuery    = `SELECT t.id, t.scope, t.subject, t.failures, t.last_ip, t.last_failure_at, t.locked_until, t.locked_at, COALESCE(users.name, '')
+	FROM login_throttles t
+	LEFT JOIN users ON t.scope = 'account' AND lower(users.email) = t.subject
+	WHERE t.locked_at IS NOT NULL AND t.locked_until > now()
+	ORDER BY t.locked_at DESC`
+	unlockLoginQuery        = `DELETE FROM login_throttles WHERE id = $1`
+	purgeLoginThrottleQuery = `DELETE FROM login_throttles
+	WHERE last_failure_at < now() - make_interval(secs => $1) AND (locked_until IS NULL OR locked_until < now())`
+)
+
+// LoginThrottleRepository define a interface para as falhas de login por conta e por IP.
+type LoginThrottleRepository interface {
+	GetLoginThrottle(ctx context.Context, scope string, subject string) (*domain.LoginThrottle, error)
+	ReserveLoginAttempt(ctx context.Context, scope string, subject string, ip string, at time.Time, policy domain.LoginThrottlePolicy) (*domain.LoginThrottle, error)
+	ReleaseLoginAttempt(ctx context.Context, id int64, at time.Time) error
+	ClearLoginThrottle(ctx context.Context, scope string, subject string) error
+	GetLockedLogins(ctx context.Context) ([]domain.LoginThrottle, error)
+	UnlockLogin(ctx context.Context, id int64) error
+}
+
+// PostgresLoginThrottleRepository é a implementação do LoginThrottleRepository para o PostgreSQL.
+type PostgresLoginThrottleRepository struct{}
+
+// NewPostgresLoginThrottleRepository cria uma nova instância do repositório.
+func NewPostgresLoginThrottleRepository() *PostgresLoginThrottleRepository {
+	return &PostgresLoginThrottleRepository{}
+}
+
+// GetLoginThrottle busca as falhas recentes da conta ou do IP.
+func (r *PostgresLoginThrottleRepository) GetLoginThrottle(ctx context.Context, scope string, subject string) (*domain.LoginThrottle, error) {
+	defer metrics.ObserveQuery("login_throttles", "GetLoginThrottle")()
+	return scanLoginThrottle(database.Conn.QueryRow(ctx, getLoginThrottleQuery, scope, subject))
+}
+
+// ReserveLoginAttempt conta a tentativa como uma falha antes de a senha ou o código ser conferido, como em
+// domain.LoginThrottle.Reserve, e retorna a contagem atualizada. Se houver um atraso ou um bloqueio em vigor,
+// nada muda e o erro é ErrLoginThrottled, com a contagem atual.
+func (r *PostgresLoginThrottleRepository) ReserveLoginAttempt(ctx context.Context, scope string, subject string, ip string, at time.Time, policy domain.LoginThrottlePolicy) (*domain.LoginThrottle, error) {
+	defer metrics.ObserveQuery("login_throttles", "ReserveLoginAttempt")()
+
+	throttle, err := scanLoginThrottle(database.Conn.QueryRow(ctx, reserveLoginAttemptQuery, scope, subject, ip, at,
+		policy.Window.Seconds(), policy.DelayAfter, policy.BaseDelay.Seconds(), policy.MaxFailures, policy.LockDuration.Seconds()))
+	if !errors.Is(err, ErrLoginThrottleNotFound) {
+		return throttle, err
+	}
+	// Nenhuma linha alterada: a conta ou o IP já está atrasado ou bloqueado.
+	throttle, err = scanLoginThrottle(database.Conn.QueryRow(ctx, getLoginThrottleQuery, scope, subject))
+	if err != nil {
+		return nil, err
+	}
+	return throttle, ErrLoginThrottled
+}
+
+// ReleaseLoginAttempt desfaz a reserva feita em at, quando a senha ou o código estava certo.
+func (r *PostgresLoginThrottleRepository) ReleaseLoginAttempt(ctx context.Context, id int64, at time.Time) error {
+	defer metrics.ObserveQuery("login_throttles", "ReleaseLoginAttempt")()
+
+	_, err := database.Conn.Exec(ctx, releaseLoginAttemptQuery, id, at)
+	return err
+}
+
+// ClearLoginThrottle esquece as falhas da conta ou do IP, depois de um login bem-sucedido.
+func (r *PostgresLoginThrottleRepository) ClearLoginThrottle(ctx context.Context, scope string, subject string) error {
+	defer metrics.ObserveQuery("login_throttles", "ClearLoginThrottle")()
+
+	_, err := database.Conn.Exec(ctx, clearLoginThrottleQuery, scope, subject)
+	return err
+}
+
+// GetLockedLogins lista as contas e os IPs bloqueados agora, dos bloqueios mais recentes para os mais antigos.
+func (r *PostgresLoginThrottleRepository) GetLockedLogins(ctx context.Context) ([]domain.LoginThrottle, error) {
+	defer metrics.ObserveQuery("login_throttles", "GetLockedLogins")()
+
+	rows, err := database.Conn.Query(ctx, getLockedLoginsQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	throttles := []domain.LoginThrottle{}
+	for rows.Next() {
+		throttle, err := scanLoginThrottle(rows)
+		if err != nil {
+			return nil, err
+		}
+		throttles = append(throttles, *throttle)
+	}
+	return throttles, rows.Err()
+}
+
+// UnlockLogin remove o bloqueio e as falhas da conta ou do IP.
+func (r *PostgresLoginThrottleRepository) UnlockLogin(ctx context.Context, id int64) error {
+	defer metrics.ObserveQuery("login_throttles", "UnlockLogin")()
+
+	res, err := database.Conn.Exec(ctx, unlockLoginQuery, id)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrLoginThrottleNotFound
+	}
+	return nil
+}
+
+// PurgeLoginThrottles remove as falhas mais antigas que window cujo bloqueio já terminou.
+func (r *PostgresLoginThrottleRepository) PurgeLoginThrottles(ctx context.Context, window time.Duration) (int64, error) {
+	defer metrics.ObserveQuery("login_throttles", "PurgeLoginThrottles")()
+
+	res, err := database.Conn.Exec(ctx, purgeLoginThrottleQuery, window.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// Run chama PurgeLoginThrottles a cada interval até ctx ser cancelado.
+func (r *PostgresLoginThrottleRepository) Run(ctx context.Context, interval time.Duration, window time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := r.PurgeLoginThrottles(ctx, window); err != nil {
+				slog.ErrorContext(ctx, "erro ao remover falhas de login antigas", "error", err)
+			}
+		}
+	}
+}
+
+func scanLoginThrottle(row pgx.Row) (*domain.LoginThrottle, error) {
+	var throttle domain.LoginThrottle
+	err := row.Scan(
+		&throttle.ID, &throttle.Scope, &throttle.Subject, &throttle.Failures, &throttle.LastIP,
+		&throttle.LastFailureAt, &throttle.LockedUntil, &throttle.LockedAt, &throttle.UserName,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrLoginThrottleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
@@ -0,0 +1,134 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"testing"
+	"time"
+)
+
+func TestPostgresLoginThrottleRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	users := repository.NewPostgresUserRepository()
+	repo := repository.NewPostgresLoginThrottleRepository()
+
+	user := &domain.User{Email: "Ana@example.com", Name: "Ana", PasswordHash: "x"}
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("Falha ao criar usuário: %v", err)
+	}
+	now := time.Now().Truncate(time.Microsecond)
+	policy := domain.LoginThrottlePolicy{DelayAfter: 3, BaseDelay: 2 * time.Second, MaxFailures: 5, LockDuration: 15 * time.Minute, Window: time.Hour}
+
+	t.Run("deve reservar as tentativas e recusar durante o atraso", func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			throttle, err := repo.ReserveLoginAttempt(ctx, domain.LoginThrottleAccount, "ana@example.com", "10.0.0.1", now, policy)
+			if err != nil {
+				t.Fatalf("ReserveLoginAttempt retornou um erro inesperado: %v", err)
+			}
+			if throttle.Failures != i {
+				t.Errorf("Esperava %d falhas, mas obtive %d", i, throttle.Failures)
+			}
+		}
+
+		throttle, err := repo.ReserveLoginAttempt(ctx, domain.LoginThrottleAccount, "ana@example.com", "10.0.0.1", now, policy)
+		if !errors.Is(err, repository.ErrLoginThrottled) {
+			t.Fatalf("Esperava ErrLoginThrottled, mas obtive %v", err)
+		}
+		if throttle.Failures != 3 || throttle.LockedUntil == nil || !throttle.LockedUntil.Equal(now.Add(2*time.Second)) {
+			t.Errorf("Esperava o atraso de 2s sem uma nova falha, mas obtive %+v", throttle)
+		}
+	})
+
+	t.Run("deve desfazer a reserva", func(t *testing.T) {
+		later := now.Add(2 * time.Second)
+		throttle, err := repo.ReserveLoginAttempt(ctx, domain.LoginThrottleAccount, "ana@example.com", "10.0.0.1", later, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.ReleaseLoginAttempt(ctx, throttle.ID, later); err != nil {
+			t.Fatalf("ReleaseLoginAttempt retornou um erro inesperado: %v", err)
+		}
+		throttle, err = repo.GetLoginThrottle(ctx, domain.LoginThrottleAccount, "ana@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if throttle.Failures != 3 || throttle.LockedUntil != nil {
+			t.Errorf("Esperava as 3 falhas anteriores sem atraso, mas obtive %+v", throttle)
+		}
+	})
+
+	t.Run("deve recomeçar a contagem depois da janela", func(t *testing.T) {
+		throttle, err := repo.ReserveLoginAttempt(ctx, domain.LoginThrottleAccount, "ana@example.com", "10.0.0.2", now.Add(2*time.Hour), policy)
+		if err != nil {
+			t.Fatalf("ReserveLoginAttempt retornou um erro inesperado: %v", err)
+		}
+		if throttle.Failures != 1 || throttle.LastIP != "10.0.0.2" || throttle.LockedUntil != nil {
+			t.Errorf("Esperava a contagem recomeçada depois da janela, mas obtive %+v", throttle)
+		}
+	})
+
+	t.Run("deve listar apenas os bloqueios ativos com o dono da conta", func(t *testing.T) {
+		if err := users.CreateUser(ctx, &domain.User{Email: "bia@example.com", Name: "Bia", PasswordHash: "x"}); err != nil {
+			t.Fatalf("Falha ao criar usuário: %v", err)
+		}
+		var account *domain.LoginThrottle
+		for i := range policy.MaxFailures {
+			throttle, err := repo.ReserveLoginAttempt(ctx, domain.LoginThrottleAccount, "bia@example.com", "10.0.0.1", now.Add(time.Duration(i)*time.Minute), policy)
+			if err != nil {
+				t.Fatalf("ReserveLoginAttempt retornou um erro inesperado: %v", err)
+			}
+			account = throttle
+		}
+		if account.LockedAt == nil || account.LockedUntil == nil || !account.LockedUntil.Equal(account.LastFailureAt.Add(15*time.Minute)) {
+			t.Fatalf("Esperava a conta bloqueada por 15 minutos, mas obtive %+v", account)
+		}
+		// Um IP apenas atrasado não aparece na lista.
+		delayed := domain.LoginThrottlePolicy{DelayAfter: 1, BaseDelay: time.Minute, MaxFailures: 10, LockDuration: time.Hour, Window: time.Hour}
+		if _, err := repo.ReserveLoginAttempt(ctx, domain.LoginThrottleIP, "10.0.0.1", "10.0.0.1", time.Now(), delayed); err != nil {
+			t.Fatal(err)
+		}
+
+		locked, err := repo.GetLockedLogins(ctx)
+		if err != nil {
+			t.Fatalf("GetLockedLogins retornou um erro inesperado: %v", err)
+		}
+		if len(locked) != 1 || locked[0].ID != account.ID || locked[0].UserName != "Bia" || locked[0].LockedAt == nil {
+			t.Errorf("Esperava apenas a conta bloqueada, mas obtive %+v", locked)
+		}
+	})
+
+	t.Run("deve desbloquear e esquecer as falhas", func(t *testing.T) {
+		account, err := repo.GetLoginThrottle(ctx, domain.LoginThrottleAccount, "ana@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.UnlockLogin(ctx, account.ID); err != nil {
+			t.Fatalf("UnlockLogin retornou um erro inesperado: %v", err)
+		}
+		if err := repo.UnlockLogin(ctx, account.ID); !errors.Is(err, repository.ErrLoginThrottleNotFound) {
+			t.Errorf("Esperava ErrLoginThrottleNotFound, mas obtive %v", err)
+		}
+		if err := repo.ClearLoginThrottle(ctx, domain.LoginThrottleIP, "10.0.0.1"); err != nil {
+			t.Fatalf("ClearLoginThrottle retornou um erro inesperado: %v", err)
+		}
+		if _, err := repo.GetLoginThrottle(ctx, domain.LoginThrottleIP, "10.0.0.1"); !errors.Is(err, repository.ErrLoginThrottleNotFound) {
+			t.Errorf("Esperava ErrLoginThrottleNotFound, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve remover as falhas antigas", func(t *testing.T) {
+		if _, err := repo.ReserveLoginAttempt(ctx, domain.LoginThrottleIP, "10.0.0.3", "10.0.0.3", now.Add(-2*time.Hour), policy); err != nil {
+			t.Fatal(err)
+		}
+		purged, err := repo.PurgeLoginThrottles(ctx, time.Hour)
+		if err != nil {
+			t.Fatalf("PurgeLoginThrottles retornou um erro inesperado: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("Esperava 1 registro removido, mas obtive %d", purged)
+		}
+	})
+}
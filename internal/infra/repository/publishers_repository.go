@@ -3,23 +3,40 @@ package repository
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"lucienne/internal/domain"
 	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
-// ErrPublisherAlreadyExists é retornado quando uma tentativa de criar um autor que já existe é feita.
-var ErrPublisherAlreadyExists = errors.New("Publisher already exists")
+var (
+	// ErrPublisherAlreadyExists é retornado quando uma tentativa de criar um autor que já existe é feita.
+	ErrPublisherAlreadyExists = errors.New("Publisher already exists")
+
+	// ErrPublisherNotFound é retornado quando uma editora não é encontrada para uma operação.
+	ErrPublisherNotFound = errors.New("editora não encontrada")
+
+	// ErrSearchPublishers é retornado quando ocorre uma falha ao buscar as editoras no banco de dados.
+	ErrSearchPublishers = errors.New("erro ao buscar editoras")
+)
 
 const (
-	// Não precisamos retornar o ID por enquanto, então usamos um INSERT simples.
-	createPublisherQuery = `INSERT INTO publishers (name) VALUES ($1)`
+	createPublisherQuery     = `INSERT INTO publishers (name) VALUES ($1) RETURNING id`
+	getPublishersQuery       = `SELECT id, name FROM publishers WHERE deleted_at IS NULL ORDER BY name ASC`
+	getPublisherByIDQuery    = `SELECT id, name FROM publishers WHERE id = $1 AND deleted_at IS NULL`
+	lockPublisherQuery       = `SELECT name FROM publishers WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	removePublisherByIDQuery = `UPDATE publishers SET deleted_at = now() WHERE id = $1`
 )
 
 // PublisherRepository define a interface para as operações de publisher no banco de dados.
 type PublisherRepository interface {
 	CreatePublisher(ctx context.Context, Publisher *domain.Publisher) error
+	GetPublishers(ctx context.Context) ([]domain.Publisher, error)
+	GetPublisherByID(ctx context.Context, id int64) (*domain.Publisher, error)
+	RemovePublisher(ctx context.Context, id int64) error
 }
 
 // PostgresPublisherRepository é a implementação do PublisherRepository para o PostgreSQL.
@@ -32,9 +49,17 @@ func NewPostgresPublisherRepository() *PostgresPublisherRepository {
 	return &PostgresPublisherRepository{}
 }
 
-// CreatePublisher insere um novo publisher no banco de dados.
+// CreatePublisher insere um novo publisher no banco de dados, preenchendo o ID. A criação é registrada no
+// histórico.
 func (r *PostgresPublisherRepository) CreatePublisher(ctx context.Context, Publisher *domain.Publisher) error {
-	_, err := database.Conn.Exec(ctx, createPublisherQuery, Publisher.Name)
+	defer metrics.ObserveQuery("publishers", "CreatePublisher")()
+
+	err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, createPublisherQuery, Publisher.Name).Scan(&Publisher.ID); err != nil {
+			return err
+		}
+		return recordAuditEvent(ctx, tx, domain.AuditActionCreate, domain.AuditEntityPublisher, Publisher.ID, nil, publisherFields(Publisher.Name))
+	})
 	if err != nil {
 		// Verifica se o erro é uma violação de chave única (unique_violation).
 		// O código '23505' é o código de erro padrão do PostgreSQL para isso.
@@ -45,5 +70,64 @@ func (r *PostgresPublisherRepository) CreatePublisher(ctx context.Context, Publi
 		// Se for outro tipo de erro, retorna o erro original.
 		return err
 	}
+
+	metrics.PublishersCreated.Inc()
 	return nil
 }
+
+// GetPublishers retorna todas as editoras ordenadas pelo nome.
+func (r *PostgresPublisherRepository) GetPublishers(ctx context.Context) ([]domain.Publisher, error) {
+	defer metrics.ObserveQuery("publishers", "GetPublishers")()
+
+	rows, err := database.Conn.Query(ctx, getPublishersQuery)
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao buscar editoras", "error", err)
+		return nil, ErrSearchPublishers
+	}
+
+	publishers, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.Publisher])
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao ler editoras", "error", err)
+		return nil, ErrSearchPublishers
+	}
+	return publishers, nil
+}
+
+// GetPublisherByID busca uma editora pelo ID.
+func (r *PostgresPublisherRepository) GetPublisherByID(ctx context.Context, id int64) (*domain.Publisher, error) {
+	defer metrics.ObserveQuery("publishers", "GetPublisherByID")()
+
+	var publisher domain.Publisher
+	err := database.Conn.QueryRow(ctx, getPublisherByIDQuery, id).Scan(&publisher.ID, &publisher.Name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPublisherNotFound
+		}
+		return nil, err
+	}
+	return &publisher, nil
+}
+
+// RemovePublisher move uma editora para a lixeira. A remoção é registrada no histórico.
+func (r *PostgresPublisherRepository) RemovePublisher(ctx context.Context, id int64) error {
+	defer metrics.ObserveQuery("publishers", "RemovePublisher")()
+
+	return pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		var before string
+		if err := tx.QueryRow(ctx, lockPublisherQuery, id).Scan(&before); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrPublisherNotFound
+			}
+			return err
+		}
+		if _, err := tx.Exec(ctx, removePublisherByIDQuery, id); err != nil {
+			return err
+		}
+		return recordAuditEvent(ctx, tx, domain.AuditActionDelete, domain.AuditEntityPublisher, id, publisherFields(before), nil)
+	})
+}
+
+// publisherFields são os campos da editora gravados no histórico.
+func publisherFields(name string) map[string]any {
+	return map[string]any{"name": name}
+}
@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"testing"
+)
+
+func TestPostgresPublisherRepository_RemovePublisher(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	repo := repository.NewPostgresPublisherRepository()
+
+	if err := repo.CreatePublisher(ctx, &domain.Publisher{Name: "Editora A"}); err != nil {
+		t.Fatalf("Falha ao criar editora: %v", err)
+	}
+	publishers, err := repo.GetPublishers(ctx)
+	if err != nil || len(publishers) != 1 {
+		t.Fatalf("Esperava 1 editora, mas obtive %d (erro: %v)", len(publishers), err)
+	}
+	id := publishers[0].ID
+
+	t.Run("deve buscar a editora pelo ID", func(t *testing.T) {
+		publisher, err := repo.GetPublisherByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetPublisherByID retornou um erro inesperado: %v", err)
+		}
+		if publisher.Name != "Editora A" {
+			t.Errorf("Esperava 'Editora A', mas obtive %q", publisher.Name)
+		}
+	})
+
+	t.Run("deve remover a editora", func(t *testing.T) {
+		if err := repo.RemovePublisher(ctx, id); err != nil {
+			t.Fatalf("RemovePublisher retornou um erro inesperado: %v", err)
+		}
+		if _, err := repo.GetPublisherByID(ctx, id); !errors.Is(err, repository.ErrPublisherNotFound) {
+			t.Errorf("Esperava ErrPublisherNotFound após a remoção, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve retornar ErrPublisherNotFound para uma editora inexistente", func(t *testing.T) {
+		if err := repo.RemovePublisher(ctx, 9999); !errors.Is(err, repository.ErrPublisherNotFound) {
+			t.Errorf("Esperava ErrPublisherNotFound, mas obtive %v", err)
+		}
+	})
+}
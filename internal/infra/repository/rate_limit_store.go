@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+	"lucienne/pkg/ratelimit"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// O balde é criado cheio; ON CONFLICT garante que requisições simultâneas não o recriem.
+	insertRateLimitBucketQuery = `INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES ($1, $2, now()) ON CONFLICT (key) DO NOTHING`
+	// O relógio do banco é usado em vez do relógio de cada réplica.
+	selectRateLimitBucketQuery     = `SELECT tokens, updated_at, now() FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`
+	updateRateLimitBucketQuery     = `UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`
+	purgeIdleRateLimitBucketsQuery = `DELETE FROM rate_limit_buckets WHERE updated_at < now() - make_interval(secs => $1)`
+)
+
+// PostgresRateLimitStore guarda os baldes do rate limit no PostgreSQL, para que os limites valham para todas as réplicas.
+type PostgresRateLimitStore struct{}
+
+var _ ratelimit.Store = (*PostgresRateLimitStore)(nil)
+
+// NewPostgresRateLimitStore cria uma nova instância do store.
+func NewPostgresRateLimitStore() *PostgresRateLimitStore {
+	return &PostgresRateLimitStore{}
+}
+
+// Take consome uma ficha do balde identificado por key, bloqueando a linha até o fim da transação.
+func (s *PostgresRateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	defer metrics.ObserveQuery("rate_limit", "Take")()
+
+	var result ratelimit.Result
+	err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, insertRateLimitBucketQuery, key, float64(limit.Burst)); err != nil {
+			return err
+		}
+
+		var bucket ratelimit.Bucket
+		var now time.Time
+		if err := tx.QueryRow(ctx, selectRateLimitBucketQuery, key).Scan(&bucket.Tokens, &bucket.UpdatedAt, &now); err != nil {
+			return err
+		}
+
+		result = bucket.Take(limit, now)
+		_, err := tx.Exec(ctx, updateRateLimitBucketQuery, key, bucket.Tokens, bucket.UpdatedAt)
+		return err
+	})
+	return result, err
+}
+
+// Purge remove os baldes sem uso há mais de idle. Um balde parado por mais tempo que o necessário para
+// se encher de novo se comporta como um inexistente, então idle deve ser maior que esse tempo.
+func (s *PostgresRateLimitStore) Purge(ctx context.Context, idle time.Duration) (int64, error) {
+	defer metrics.ObserveQuery("rate_limit", "Purge")()
+
+	res, err := database.Conn.Exec(ctx, purgeIdleRateLimitBucketsQuery, idle.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// Run chama Purge a cada interval, removendo os baldes parados há mais de idle, até ctx ser cancelado.
+// idle deve ser ao menos o maior ratelimit.Limit.RefillTime entre os limites que usam o store.
+func (s *PostgresRateLimitStore) Run(ctx context.Context, interval, idle time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := s.Purge(ctx, idle); err != nil {
+				slog.ErrorContext(ctx, "erro ao remover baldes de rate limit", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package repository_test
+
+import (
+	"context"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/repository"
+	"lucienne/pkg/ratelimit"
+	"testing"
+	"time"
+)
+
+func TestPostgresRateLimitStore_Take(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	store := repository.NewPostgresRateLimitStore()
+	limit := ratelimit.Limit{Requests: 1, Period: time.Hour, Burst: 2}
+
+	t.Run("deve consumir as fichas até esvaziar o balde", func(t *testing.T) {
+		for i := range 2 {
+			result, err := store.Take(ctx, "write:ip:10.0.0.1", limit)
+			if err != nil {
+				t.Fatalf("Take retornou um erro inesperado: %v", err)
+			}
+			if !result.Allowed {
+				t.Fatalf("Esperava a requisição %d permitida, mas foi negada", i)
+			}
+		}
+
+		result, err := store.Take(ctx, "write:ip:10.0.0.1", limit)
+		if err != nil {
+			t.Fatalf("Take retornou um erro inesperado: %v", err)
+		}
+		if result.Allowed || result.RetryAfter <= 0 {
+			t.Errorf("Esperava a requisição negada com Retry-After, mas obtive %#v", result)
+		}
+	})
+
+	t.Run("deve manter um balde por chave", func(t *testing.T) {
+		result, err := store.Take(ctx, "write:ip:10.0.0.2", limit)
+		if err != nil {
+			t.Fatalf("Take retornou um erro inesperado: %v", err)
+		}
+		if !result.Allowed {
+			t.Error("Esperava a requisição de outra chave permitida")
+		}
+	})
+
+	t.Run("deve remover os baldes parados", func(t *testing.T) {
+		_, err := database.Conn.Exec(ctx, "UPDATE rate_limit_buckets SET updated_at = now() - interval '2 hours'")
+		if err != nil {
+			t.Fatalf("Falha ao envelhecer os baldes: %v", err)
+		}
+
+		removed, err := store.Purge(ctx, time.Hour)
+		if err != nil {
+			t.Fatalf("Purge retornou um erro inesperado: %v", err)
+		}
+		if removed != 2 {
+			t.Errorf("Esperava remover 2 baldes, mas removeu %d", removed)
+		}
+	})
+}
@@ -88,7 +88,7 @@ func setupTestDBAndMigrate(t testing.TB) {
 			t.Logf("AVISO: Falha ao fechar DB da migração: %v", dbErr)
 		}
 
-		database.Conn.Close(ctx)
+		database.Close(ctx)
 
 		if err := container.Terminate(context.Background()); err != nil {
 			t.Logf("AVISO: Falha ao terminar o container: %s", err)
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrRoleNotFound é retornado quando algum dos papéis informados não existe.
+var ErrRoleNotFound = errors.New("papel não encontrado")
+
+const (
+	getRolesQuery = `SELECT roles.id, roles.name, roles.description,
+		ARRAY(SELECT permissions.name FROM role_permissions JOIN permissions ON permissions.id = role_permissions.permission_id
+			WHERE role_permissions.role_id = roles.id ORDER BY permissions.name)
+	FROM roles ORDER BY roles.id`
+	deleteUserRolesQuery = `DELETE FROM user_roles WHERE user_id = $1`
+	insertUserRolesQuery = `INSERT INTO user_roles (user_id, role_id) SELECT $1, id FROM roles WHERE name = ANY($2)`
+)
+
+// RoleRepository define a interface para as operações de papéis no banco de dados.
+type RoleRepository interface {
+	GetRoles(ctx context.Context) ([]domain.Role, error)
+	SetUserRoles(ctx context.Context, userID int64, roles []string) error
+}
+
+// PostgresRoleRepository é a implementação do RoleRepository para o PostgreSQL.
+type PostgresRoleRepository struct{}
+
+// NewPostgresRoleRepository cria uma nova instância do repositório.
+func NewPostgresRoleRepository() *PostgresRoleRepository {
+	return &PostgresRoleRepository{}
+}
+
+// GetRoles retorna todos os papéis com as permissões que concedem.
+func (r *PostgresRoleRepository) GetRoles(ctx context.Context) ([]domain.Role, error) {
+	defer metrics.ObserveQuery("roles", "GetRoles")()
+
+	rows, err := database.Conn.Query(ctx, getRolesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []domain.Role
+	for rows.Next() {
+		var role domain.Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.Permissions); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// SetUserRoles substitui os papéis do usuário pelos informados, em uma transação.
+func (r *PostgresRoleRepository) SetUserRoles(ctx context.Context, userID int64, roles []string) error {
+	defer metrics.ObserveQuery("roles", "SetUserRoles")()
+
+	err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteUserRolesQuery, userID); err != nil {
+			return err
+		}
+		res, err := tx.Exec(ctx, insertUserRolesQuery, userID, roles)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() != int64(len(roles)) {
+			return ErrRoleNotFound
+		}
+		return nil
+	})
+	// Violação de chave estrangeira: o usuário não existe.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrUserNotFound
+	}
+	return err
+}
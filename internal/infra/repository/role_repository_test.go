@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"slices"
+	"testing"
+)
+
+func TestPostgresRoleRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	users := repository.NewPostgresUserRepository()
+	repo := repository.NewPostgresRoleRepository()
+
+	user := &domain.User{Email: "ana@example.com", Name: "Ana", PasswordHash: "x"}
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("Falha ao criar usuário: %v", err)
+	}
+
+	t.Run("deve criar o usuário como membro, sem permissões", func(t *testing.T) {
+		found, err := users.GetUserByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetUserByID retornou um erro inesperado: %v", err)
+		}
+		if !slices.Equal(found.Roles, []string{"member"}) || len(found.Permissions) != 0 {
+			t.Errorf("Esperava apenas o papel member, mas obtive %v com as permissões %v", found.Roles, found.Permissions)
+		}
+	})
+
+	t.Run("deve listar os papéis criados pela migração", func(t *testing.T) {
+		roles, err := repo.GetRoles(ctx)
+		if err != nil {
+			t.Fatalf("GetRoles retornou um erro inesperado: %v", err)
+		}
+		if len(roles) != 3 || roles[2].Name != "admin" || !slices.Contains(roles[2].Permissions, "users.manage") {
+			t.Errorf("Papéis inesperados: %+v", roles)
+		}
+	})
+
+	t.Run("deve carregar as permissões dos novos papéis", func(t *testing.T) {
+		if err := repo.SetUserRoles(ctx, user.ID, []string{"member", "librarian"}); err != nil {
+			t.Fatalf("SetUserRoles retornou um erro inesperado: %v", err)
+		}
+		found, err := users.GetUserByEmail(ctx, "ana@example.com")
+		if err != nil {
+			t.Fatalf("GetUserByEmail retornou um erro inesperado: %v", err)
+		}
+		want := []string{"authors.manage", "books.manage", "publishers.manage"}
+		if !slices.Equal(found.Permissions, want) {
+			t.Errorf("Esperava as permissões %v, mas obtive %v", want, found.Permissions)
+		}
+	})
+
+	t.Run("deve recusar um papel inexistente sem alterar os atuais", func(t *testing.T) {
+		if err := repo.SetUserRoles(ctx, user.ID, []string{"superuser"}); !errors.Is(err, repository.ErrRoleNotFound) {
+			t.Errorf("Esperava ErrRoleNotFound, mas obtive %v", err)
+		}
+		found, _ := users.GetUserByID(ctx, user.ID)
+		if !slices.Equal(found.Roles, []string{"librarian", "member"}) {
+			t.Errorf("Os papéis não deveriam ter mudado, mas obtive %v", found.Roles)
+		}
+	})
+
+	t.Run("deve retornar ErrUserNotFound para um usuário inexistente", func(t *testing.T) {
+		if err := repo.SetUserRoles(ctx, 9999, []string{"member"}); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Esperava ErrUserNotFound, mas obtive %v", err)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrSessionNotFound é retornado quando a sessão não existe ou já foi removida.
+var ErrSessionNotFound = errors.New("sessão não encontrada")
+
+const (
+	createSessionQuery       = `INSERT INTO sessions (id, user_id, last_seen_at, expires_at) VALUES ($1, $2, $3, $4) RETURNING created_at`
+	getSessionQuery          = `SELECT id, user_id, created_at, last_seen_at, expires_at FROM sessions WHERE id = $1`
+	touchSessionQuery        = `UPDATE sessions SET last_seen_at = $2 WHERE id = $1`
+	deleteSessionQuery       = `DELETE FROM sessions WHERE id = $1`
+	deleteUserSessionsQuery  = `DELETE FROM sessions WHERE user_id = $1`
+	purgeExpiredSessionQuery = `DELETE FROM sessions WHERE expires_at < now() OR last_seen_at < now() - make_interval(secs => $1)`
+)
+
+// SessionRepository define a interface para as operações de sessão no banco de dados.
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *domain.Session) error
+	GetSession(ctx context.Context, id string) (*domain.Session, error)
+	TouchSession(ctx context.Context, id string, lastSeenAt time.Time) error
+	DeleteSession(ctx context.Context, id string) error
+	DeleteUserSessions(ctx context.Context, userID int64) error
+}
+
+// PostgresSessionRepository é a implementação do SessionRepository para o PostgreSQL.
+type PostgresSessionRepository struct{}
+
+// NewPostgresSessionRepository cria uma nova instância do repositório.
+func NewPostgresSessionRepository() *PostgresSessionRepository {
+	return &PostgresSessionRepository{}
+}
+
+// CreateSession insere uma nova sessão, preenchendo a data de criação.
+func (r *PostgresSessionRepository) CreateSession(ctx context.Context, session *domain.Session) error {
+	defer metrics.ObserveQuery("sessions", "CreateSession")()
+
+	return database.Conn.QueryRow(ctx, createSessionQuery, session.ID, session.UserID, session.LastSeenAt, session.ExpiresAt).Scan(&session.CreatedAt)
+}
+
+// GetSession busca uma sessão pelo ID, mesmo que já tenha expirado.
+func (r *PostgresSessionRepository) GetSession(ctx context.Context, id string) (*domain.Session, error) {
+	defer metrics.ObserveQuery("sessions", "GetSession")()
+
+	var session domain.Session
+	err := database.Conn.QueryRow(ctx, getSessionQuery, id).Scan(&session.ID, &session.UserID, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// TouchSession registra o último uso da sessão, que conta para a expiração por inatividade.
+func (r *PostgresSessionRepository) TouchSession(ctx context.Context, id string, lastSeenAt time.Time) error {
+	defer metrics.ObserveQuery("sessions", "TouchSession")()
+
+	_, err := database.Conn.Exec(ctx, touchSessionQuery, id, lastSeenAt)
+	return err
+}
+
+// DeleteSession remove uma sessão. Remover uma sessão inexistente não é um erro.
+func (r *PostgresSessionRepository) DeleteSession(ctx context.Context, id string) error {
+	defer metrics.ObserveQuery("sessions", "DeleteSession")()
+
+	_, err := database.Conn.Exec(ctx, deleteSessionQuery, id)
+	return err
+}
+
+// DeleteUserSessions encerra todas as sessões do usuário, em todos os navegadores.
+func (r *PostgresSessionRepository) DeleteUserSessions(ctx context.Context, userID int64) error {
+	defer metrics.ObserveQuery("sessions", "DeleteUserSessions")()
+
+	_, err := database.Conn.Exec(ctx, deleteUserSessionsQuery, userID)
+	return err
+}
+
+// PurgeExpiredSessions remove as sessões que passaram do prazo absoluto ou estão paradas há mais de idle.
+func (r *PostgresSessionRepository) PurgeExpiredSessions(ctx context.Context, idle time.Duration) (int64, error) {
+	defer metrics.ObserveQuery("sessions", "PurgeExpiredSessions")()
+
+	res, err := database.Conn.Exec(ctx, purgeExpiredSessionQuery, idle.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// Run chama PurgeExpiredSessions a cada interval até ctx ser cancelado.
+func (r *PostgresSessionRepository) Run(ctx context.Context, interval time.Duration, idle time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := r.PurgeExpiredSessions(ctx, idle); err != nil {
+				slog.ErrorContext(ctx, "erro ao remover sessões expiradas", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostgresSessionRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	users := repository.NewPostgresUserRepository()
+	repo := repository.NewPostgresSessionRepository()
+
+	user := &domain.User{Email: "ana@example.com", Name: "Ana", PasswordHash: "x"}
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("Falha ao criar usuário: %v", err)
+	}
+
+	now := time.Now()
+	active := &domain.Session{ID: strings.Repeat("a", 64), UserID: user.ID, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)}
+	idle := &domain.Session{ID: strings.Repeat("b", 64), UserID: user.ID, LastSeenAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour)}
+	expired := &domain.Session{ID: strings.Repeat("c", 64), UserID: user.ID, LastSeenAt: now, ExpiresAt: now.Add(-time.Minute)}
+	for _, session := range []*domain.Session{active, idle, expired} {
+		if err := repo.CreateSession(ctx, session); err != nil {
+			t.Fatalf("Falha ao criar sessão: %v", err)
+		}
+	}
+
+	t.Run("deve buscar e atualizar o último uso da sessão", func(t *testing.T) {
+		lastSeen := now.Add(time.Minute).Truncate(time.Microsecond)
+		if err := repo.TouchSession(ctx, active.ID, lastSeen); err != nil {
+			t.Fatalf("TouchSession retornou um erro inesperado: %v", err)
+		}
+		session, err := repo.GetSession(ctx, active.ID)
+		if err != nil {
+			t.Fatalf("GetSession retornou um erro inesperado: %v", err)
+		}
+		if session.UserID != user.ID || !session.LastSeenAt.Equal(lastSeen) {
+			t.Errorf("Sessão inesperada: %+v", session)
+		}
+	})
+
+	t.Run("deve remover as sessões expiradas e as paradas", func(t *testing.T) {
+		purged, err := repo.PurgeExpiredSessions(ctx, time.Hour)
+		if err != nil {
+			t.Fatalf("PurgeExpiredSessions retornou um erro inesperado: %v", err)
+		}
+		if purged != 2 {
+			t.Errorf("Esperava 2 sessões removidas, mas obtive %d", purged)
+		}
+		if _, err := repo.GetSession(ctx, active.ID); err != nil {
+			t.Errorf("A sessão ativa não deveria ter sido removida: %v", err)
+		}
+	})
+
+	t.Run("deve remover a sessão", func(t *testing.T) {
+		if err := repo.DeleteSession(ctx, active.ID); err != nil {
+			t.Fatalf("DeleteSession retornou um erro inesperado: %v", err)
+		}
+		if _, err := repo.GetSession(ctx, active.ID); !errors.Is(err, repository.ErrSessionNotFound) {
+			t.Errorf("Esperava ErrSessionNotFound, mas obtive %v", err)
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrTrashItemNotFound é retornado quando o registro não existe ou não está na lixeira.
+	ErrTrashItemNotFound = errors.New("registro não encontrado na lixeira")
+
+	// ErrTrashNameInUse é retornado ao restaurar um registro cujo nome já foi cadastrado de novo.
+	ErrTrashNameInUse = errors.New("já existe um registro ativo com esse nome")
+
+	// ErrTrashAuthorDeleted é retornado ao restaurar um livro cujo autor também está na lixeira.
+	ErrTrashAuthorDeleted = errors.New("o autor do livro está na lixeira")
+
+	// ErrTrashItemInUse é retornado ao apagar de vez um autor que ainda tem livros na lixeira.
+	ErrTrashItemInUse = errors.New("o autor tem livros na lixeira")
+)
+
+const (
+	getTrashQuery = `SELECT 'author', id, name, deleted_at FROM authors WHERE deleted_at IS NOT NULL
+	UNION ALL SELECT 'publisher', id, name, deleted_at FROM publishers WHERE deleted_at IS NOT NULL
+	UNION ALL SELECT 'book', id, name, deleted_at FROM books WHERE deleted_at IS NOT NULL
+	ORDER BY deleted_at DESC`
+	bookAuthorDeletedQuery = `SELECT authors.deleted_at IS NOT NULL FROM books JOIN authors ON authors.id = books.author_id WHERE books.id = $1`
+)
+
+// trashTable reúne as consultas da lixeira de uma tabela do catálogo.
+type trashTable struct {
+	lock    string
+	restore string
+	purge   string
+	expire  string
+}
+
+// newTrashTable monta as consultas da tabela. purgeable restringe os registros que o prazo de retenção apaga.
+func newTrashTable(name string, purgeable string) trashTable {
+	return trashTable{
+		lock:    `SELECT name FROM ` + name + ` WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`,
+		restore: `UPDATE ` + name + ` SET deleted_at = NULL WHERE id = $1`,
+		purge:   `DELETE FROM ` + name + ` WHERE id = $1`,
+		expire: `DELETE FROM ` + name + ` WHERE deleted_at < now() - make_interval(secs => $1) AND ` + purgeable + `
+		RETURNING id, name`,
+	}
+}
+
+// trashTables relaciona os tipos de registro às tabelas. A ordem de trashPurgeOrder apaga os livros antes dos
+// autores que eles referenciam.
+var (
+	trashTables = map[string]trashTable{
+		domain.AuditEntityAuthor:    newTrashTable("authors", `NOT EXISTS (SELECT 1 FROM books WHERE books.author_id = authors.id)`),
+		domain.AuditEntityPublisher: newTrashTable("publishers", `true`),
+		domain.AuditEntityBook:      newTrashTable("books", `true`),
+	}
+	trashPurgeOrder = []string{domain.AuditEntityBook, domain.AuditEntityPublisher, domain.AuditEntityAuthor}
+)
+
+// TrashRepository define a interface para a lixeira de autores, editoras e livros.
+type TrashRepository interface {
+	GetTrash(ctx context.Context) ([]domain.TrashItem, error)
+	RestoreItem(ctx context.Context, entityType string, id int64) error
+	PurgeItem(ctx context.Context, entityType string, id int64) error
+}
+
+// PostgresTrashRepository é a implementação do TrashRepository para o PostgreSQL.
+type PostgresTrashRepository struct{}
+
+// NewPostgresTrashRepository cria uma nova instância do repositório.
+func NewPostgresTrashRepository() *PostgresTrashRepository {
+	return &PostgresTrashRepository{}
+}
+
+// GetTrash lista os registros removidos, dos mais recentes para os mais antigos.
+func (r *PostgresTrashRepository) GetTrash(ctx context.Context) ([]domain.TrashItem, error) {
+	defer metrics.ObserveQuery("trash", "GetTrash")()
+
+	rows, err := database.Conn.Query(ctx, getTrashQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []domain.TrashItem{}
+	for rows.Next() {
+		var item domain.TrashItem
+		if err := rows.Scan(&item.EntityType, &item.ID, &item.Name, &item.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// RestoreItem tira o registro da lixeira. A restauração é registrada no histórico.
+func (r *PostgresTrashRepository) RestoreItem(ctx context.Context, entityType string, id int64) error {
+	defer metrics.ObserveQuery("trash", "RestoreItem")()
+
+	table, ok := trashTables[entityType]
+	if !ok {
+		return ErrTrashItemNotFound
+	}
+	err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		name, err := lockTrashItem(ctx, tx, table, id)
+		if err != nil {
+			return err
+		}
+		if entityType == domain.AuditEntityBook {
+			var authorDeleted bool
+			if err := tx.QueryRow(ctx, bookAuthorDeletedQuery, id).Scan(&authorDeleted); err != nil {
+				return err
+			}
+			if authorDeleted {
+				return ErrTrashAuthorDeleted
+			}
+		}
+		if _, err := tx.Exec(ctx, table.restore, id); err != nil {
+			return err
+		}
+		return recordAuditEvent(ctx, tx, domain.AuditActionRestore, entityType, id, nil, map[string]any{"name": name})
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrTrashNameInUse
+	}
+	return err
+}
+
+// PurgeItem apaga de vez um registro da lixeira. A exclusão é registrada no histórico.
+func (r *PostgresTrashRepository) PurgeItem(ctx context.Context, entityType string, id int64) error {
+	defer metrics.ObserveQuery("trash", "PurgeItem")()
+
+	table, ok := trashTables[entityType]
+	if !ok {
+		return ErrTrashItemNotFound
+	}
+	err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		name, err := lockTrashItem(ctx, tx, table, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, table.purge, id); err != nil {
+			return err
+		}
+		return recordAuditEvent(ctx, tx, domain.AuditActionPurge, entityType, id, map[string]any{"name": name}, nil)
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrTrashItemInUse
+	}
+	return err
+}
+
+// PurgeExpired apaga de vez os registros que estão na lixeira há mais de retention. Os autores que ainda têm
+// livros na lixeira esperam que os livros sejam apagados.
+func (r *PostgresTrashRepository) PurgeExpired(ctx context.Context, retention time.Duration) (int64, error) {
+	defer metrics.ObserveQuery("trash", "PurgeExpired")()
+
+	var purged int64
+	for _, entityType := range trashPurgeOrder {
+		err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+			rows, err := tx.Query(ctx, trashTables[entityType].expire, retention.Seconds())
+			if err != nil {
+				return err
+			}
+			items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.TrashItem, error) {
+				var item domain.TrashItem
+				err := row.Scan(&item.ID, &item.Name)
+				return item, err
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, item := range items {
+				err := recordAuditEvent(ctx, tx, domain.AuditActionPurge, entityType, item.ID, map[string]any{"name": item.Name}, nil)
+				if err != nil {
+					return err
+				}
+			}
+			purged += int64(len(items))
+			return nil
+		})
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+// Run chama PurgeExpired a cada interval até ctx ser cancelado.
+func (r *PostgresTrashRepository) Run(ctx context.Context, interval time.Duration, retention time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := r.PurgeExpired(ctx, retention); err != nil {
+				slog.ErrorContext(ctx, "erro ao esvaziar a lixeira", "error", err)
+			}
+		}
+	}
+}
+
+// lockTrashItem trava o registro da lixeira e retorna o nome dele.
+func lockTrashItem(ctx context.Context, tx pgx.Tx, table trashTable, id int64) (string, error) {
+	var name string
+	err := tx.QueryRow(ctx, table.lock, id).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrTrashItemNotFound
+	}
+	return name, err
+}
@@ -0,0 +1,119 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/repository"
+	"testing"
+	"time"
+)
+
+func TestPostgresTrashRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	authors := repository.NewPostgresAuthorRepository()
+	publishers := repository.NewPostgresPublisherRepository()
+	repo := repository.NewPostgresTrashRepository()
+
+	author := &domain.Author{Name: "Neil Gaiman"}
+	if err := authors.CreateAuthor(ctx, author); err != nil {
+		t.Fatalf("Falha ao criar autor: %v", err)
+	}
+	publisher := &domain.Publisher{Name: "Rocco"}
+	if err := publishers.CreatePublisher(ctx, publisher); err != nil {
+		t.Fatalf("Falha ao criar editora: %v", err)
+	}
+	var bookID int64
+	if err := database.Conn.QueryRow(ctx, "INSERT INTO books (name, author_id) VALUES ('Coraline', $1) RETURNING id", author.ID).Scan(&bookID); err != nil {
+		t.Fatalf("Falha ao criar livro: %v", err)
+	}
+
+	t.Run("deve listar os registros removidos", func(t *testing.T) {
+		if _, err := database.Conn.Exec(ctx, "UPDATE books SET deleted_at = now() WHERE id = $1", bookID); err != nil {
+			t.Fatal(err)
+		}
+		if err := authors.RemoveAuthor(ctx, author.ID); err != nil {
+			t.Fatalf("RemoveAuthor retornou um erro inesperado: %v", err)
+		}
+		if err := publishers.RemovePublisher(ctx, publisher.ID); err != nil {
+			t.Fatalf("RemovePublisher retornou um erro inesperado: %v", err)
+		}
+
+		items, err := repo.GetTrash(ctx)
+		if err != nil {
+			t.Fatalf("GetTrash retornou um erro inesperado: %v", err)
+		}
+		if len(items) != 3 || items[0].EntityType != domain.AuditEntityPublisher || items[0].Name != "Rocco" {
+			t.Errorf("Esperava os 3 registros removidos, do mais recente ao mais antigo, mas obtive %+v", items)
+		}
+		if authors, _ := authors.GetAuthors(ctx); len(authors) != 0 {
+			t.Errorf("Esperava o autor fora da listagem, mas obtive %+v", authors)
+		}
+	})
+
+	t.Run("deve exigir o autor ativo para restaurar o livro", func(t *testing.T) {
+		if err := repo.RestoreItem(ctx, domain.AuditEntityBook, bookID); !errors.Is(err, repository.ErrTrashAuthorDeleted) {
+			t.Errorf("Esperava ErrTrashAuthorDeleted, mas obtive %v", err)
+		}
+		if err := repo.PurgeItem(ctx, domain.AuditEntityAuthor, author.ID); !errors.Is(err, repository.ErrTrashItemInUse) {
+			t.Errorf("Esperava ErrTrashItemInUse, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve restaurar um registro", func(t *testing.T) {
+		if err := repo.RestoreItem(ctx, domain.AuditEntityAuthor, author.ID); err != nil {
+			t.Fatalf("RestoreItem retornou um erro inesperado: %v", err)
+		}
+		if _, err := authors.GetAuthorByID(ctx, author.ID); err != nil {
+			t.Errorf("Esperava o autor restaurado, mas obtive %v", err)
+		}
+		if err := repo.RestoreItem(ctx, domain.AuditEntityAuthor, author.ID); !errors.Is(err, repository.ErrTrashItemNotFound) {
+			t.Errorf("Esperava ErrTrashItemNotFound para um autor ativo, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve recusar a restauração de um nome cadastrado de novo", func(t *testing.T) {
+		if err := publishers.CreatePublisher(ctx, &domain.Publisher{Name: "Rocco"}); err != nil {
+			t.Fatalf("Esperava poder cadastrar de novo o nome na lixeira, mas obtive %v", err)
+		}
+		if err := repo.RestoreItem(ctx, domain.AuditEntityPublisher, publisher.ID); !errors.Is(err, repository.ErrTrashNameInUse) {
+			t.Errorf("Esperava ErrTrashNameInUse, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve apagar de vez um registro", func(t *testing.T) {
+		if err := repo.PurgeItem(ctx, domain.AuditEntityPublisher, publisher.ID); err != nil {
+			t.Fatalf("PurgeItem retornou um erro inesperado: %v", err)
+		}
+		if err := repo.PurgeItem(ctx, domain.AuditEntityPublisher, publisher.ID); !errors.Is(err, repository.ErrTrashItemNotFound) {
+			t.Errorf("Esperava ErrTrashItemNotFound, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve apagar de vez apenas os registros mais antigos que o prazo", func(t *testing.T) {
+		if _, err := database.Conn.Exec(ctx, "UPDATE books SET deleted_at = now() - interval '2 days' WHERE id = $1", bookID); err != nil {
+			t.Fatal(err)
+		}
+		other := &domain.Author{Name: "Terry Pratchett"}
+		if err := authors.CreateAuthor(ctx, other); err != nil {
+			t.Fatal(err)
+		}
+		if err := authors.RemoveAuthor(ctx, other.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		purged, err := repo.PurgeExpired(ctx, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("PurgeExpired retornou um erro inesperado: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("Esperava 1 registro apagado, mas obtive %d", purged)
+		}
+		items, err := repo.GetTrash(ctx)
+		if err != nil || len(items) != 1 || items[0].ID != other.ID {
+			t.Errorf("Esperava apenas o autor recente na lixeira, mas obtive %+v (%v)", items, err)
+		}
+	})
+}
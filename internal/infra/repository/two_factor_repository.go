@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrTwoFactorNotFound é retornado quando o usuário não iniciou a ativação da verificação em duas etapas.
+	ErrTwoFactorNotFound = errors.New("verificação em duas etapas não encontrada")
+	// ErrTwoFactorEnabled é retornado ao tentar reiniciar a ativação de quem já a concluiu.
+	ErrTwoFactorEnabled = errors.New("verificação em duas etapas já ativada")
+	// ErrTwoFactorStepUsed é retornado quando o código do intervalo já foi aceito antes.
+	ErrTwoFactorStepUsed = errors.New("código já usado")
+	// ErrRecoveryCodeNotFound é retornado quando o código de recuperação não existe ou já foi usado.
+	ErrRecoveryCodeNotFound = errors.New("código de recuperação não encontrado")
+)
+
+const (
+	getTwoFactorQuery = `SELECT id, totp_secret, totp_enabled_at, totp_last_step,
+		(SELECT count(*) FROM user_recovery_codes WHERE user_id = users.id AND used_at IS NULL)
+	FROM users WHERE id = $1 AND totp_secret IS NOT NULL`
+	setTwoFactorSecretQuery = `UPDATE users SET totp_secret = $2, totp_last_step = 0 WHERE id = $1 AND totp_enabled_at IS NULL`
+	enableTwoFactorQuery    = `UPDATE users SET totp_enabled_at = now(), totp_last_step = $2
+	WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL`
+	// O intervalo só avança, de modo que duas requisições com o mesmo código não são aceitas ao mesmo tempo.
+	useTwoFactorStepQuery    = `UPDATE users SET totp_last_step = $2 WHERE id = $1 AND totp_enabled_at IS NOT NULL AND totp_last_step < $2`
+	disableTwoFactorQuery    = `UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0 WHERE id = $1`
+	deleteRecoveryCodesQuery = `DELETE FROM user_recovery_codes WHERE user_id = $1`
+	insertRecoveryCodesQuery = `INSERT INTO user_recovery_codes (user_id, code_hash) SELECT $1, unnest($2::text[])`
+	useRecoveryCodeQuery     = `UPDATE user_recovery_codes SET used_at = now() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+)
+
+// TwoFactorRepository define a interface para a verificação em duas etapas dos usuários.
+type TwoFactorRepository interface {
+	GetTwoFactor(ctx context.Context, userID int64) (*domain.TwoFactor, error)
+	SetTwoFactorSecret(ctx context.Context, userID int64, secret string) error
+	EnableTwoFactor(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error
+	UseTwoFactorStep(ctx context.Context, userID int64, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int64, hash string) error
+	DisableTwoFactor(ctx context.Context, userID int64) error
+}
+
+// PostgresTwoFactorRepository é a implementação do TwoFactorRepository para o PostgreSQL.
+type PostgresTwoFactorRepository struct{}
+
+// NewPostgresTwoFactorRepository cria uma nova instância do repositório.
+func NewPostgresTwoFactorRepository() *PostgresTwoFactorRepository {
+	return &PostgresTwoFactorRepository{}
+}
+
+// GetTwoFactor busca a verificação em duas etapas do usuário, ativada ou em ativação.
+func (r *PostgresTwoFactorRepository) GetTwoFactor(ctx context.Context, userID int64) (*domain.TwoFactor, error) {
+	defer metrics.ObserveQuery("users", "GetTwoFactor")()
+
+	var twoFactor domain.TwoFactor
+	err := database.Conn.QueryRow(ctx, getTwoFactorQuery, userID).Scan(
+		&twoFactor.UserID, &twoFactor.Secret, &twoFactor.EnabledAt, &twoFactor.LastStep, &twoFactor.RecoveryCodes,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTwoFactorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+// SetTwoFactorSecret inicia a ativação com um novo segredo, substituindo o de uma ativação não concluída.
+func (r *PostgresTwoFactorRepository) SetTwoFactorSecret(ctx context.Context, userID int64, secret string) error {
+	defer metrics.ObserveQuery("users", "SetTwoFactorSecret")()
+
+	res, err := database.Conn.Exec(ctx, setTwoFactorSecretQuery, userID, secret)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+// EnableTwoFactor conclui a ativação, registrando o intervalo do código confirmado, e grava os códigos de
+// recuperação, em uma transação.
+func (r *PostgresTwoFactorRepository) EnableTwoFactor(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error {
+	defer metrics.ObserveQuery("users", "EnableTwoFactor")()
+
+	return pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		res, err := tx.Exec(ctx, enableTwoFactorQuery, userID, step)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return ErrTwoFactorNotFound
+		}
+		return replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes)
+	})
+}
+
+// UseTwoFactorStep registra o intervalo do código aceito. Retorna ErrTwoFactorStepUsed se ele não for
+// posterior ao último aceito.
+func (r *PostgresTwoFactorRepository) UseTwoFactorStep(ctx context.Context, userID int64, step int64) error {
+	defer metrics.ObserveQuery("users", "UseTwoFactorStep")()
+
+	res, err := database.Conn.Exec(ctx, useTwoFactorStepQuery, userID, step)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrTwoFactorStepUsed
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes substitui todos os códigos de recuperação do usuário, em uma transação.
+func (r *PostgresTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodeHashes []string) error {
+	defer metrics.ObserveQuery("user_recovery_codes", "ReplaceRecoveryCodes")()
+
+	return pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes)
+	})
+}
+
+// UseRecoveryCode marca o código de recuperação como usado.
+func (r *PostgresTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int64, hash string) error {
+	defer metrics.ObserveQuery("user_recovery_codes", "UseRecoveryCode")()
+
+	res, err := database.Conn.Exec(ctx, useRecoveryCodeQuery, userID, hash)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+	return nil
+}
+
+// DisableTwoFactor remove o segredo e os códigos de recuperação do usuário, em uma transação.
+func (r *PostgresTwoFactorRepository) DisableTwoFactor(ctx context.Context, userID int64) error {
+	defer metrics.ObserveQuery("users", "DisableTwoFactor")()
+
+	return pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		res, err := tx.Exec(ctx, disableTwoFactorQuery, userID)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return ErrUserNotFound
+		}
+		_, err = tx.Exec(ctx, deleteRecoveryCodesQuery, userID)
+		return err
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int64, hashes []string) error {
+	if _, err := tx.Exec(ctx, deleteRecoveryCodesQuery, userID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, insertRecoveryCodesQuery, userID, hashes)
+	return err
+}
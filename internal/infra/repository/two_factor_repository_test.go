@@ -0,0 +1,97 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"strings"
+	"testing"
+)
+
+func TestPostgresTwoFactorRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	users := repository.NewPostgresUserRepository()
+	repo := repository.NewPostgresTwoFactorRepository()
+
+	user := &domain.User{Email: "ana@example.com", Name: "Ana", PasswordHash: "x"}
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("Falha ao criar usuário: %v", err)
+	}
+	hashes := []string{strings.Repeat("a", 64), strings.Repeat("b", 64)}
+
+	t.Run("deve ativar a verificação com os códigos de recuperação", func(t *testing.T) {
+		if _, err := repo.GetTwoFactor(ctx, user.ID); !errors.Is(err, repository.ErrTwoFactorNotFound) {
+			t.Errorf("Esperava ErrTwoFactorNotFound antes da ativação, mas obtive %v", err)
+		}
+		if err := repo.SetTwoFactorSecret(ctx, user.ID, "JBSWY3DPEHPK3PXP"); err != nil {
+			t.Fatalf("SetTwoFactorSecret retornou um erro inesperado: %v", err)
+		}
+		if err := repo.EnableTwoFactor(ctx, user.ID, 100, hashes); err != nil {
+			t.Fatalf("EnableTwoFactor retornou um erro inesperado: %v", err)
+		}
+
+		twoFactor, err := repo.GetTwoFactor(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetTwoFactor retornou um erro inesperado: %v", err)
+		}
+		if !twoFactor.Enabled() || twoFactor.Secret != "JBSWY3DPEHPK3PXP" || twoFactor.LastStep != 100 || twoFactor.RecoveryCodes != 2 {
+			t.Errorf("Verificação inesperada: %+v", twoFactor)
+		}
+		if err := repo.SetTwoFactorSecret(ctx, user.ID, "KRSXG5A"); !errors.Is(err, repository.ErrTwoFactorEnabled) {
+			t.Errorf("Esperava ErrTwoFactorEnabled ao trocar o segredo, mas obtive %v", err)
+		}
+		found, err := users.GetUserByID(ctx, user.ID)
+		if err != nil || !found.TwoFactorEnabled {
+			t.Errorf("Esperava TwoFactorEnabled no usuário, mas obtive %+v (%v)", found, err)
+		}
+	})
+
+	t.Run("deve aceitar apenas intervalos posteriores ao último", func(t *testing.T) {
+		if err := repo.UseTwoFactorStep(ctx, user.ID, 100); !errors.Is(err, repository.ErrTwoFactorStepUsed) {
+			t.Errorf("Esperava ErrTwoFactorStepUsed, mas obtive %v", err)
+		}
+		if err := repo.UseTwoFactorStep(ctx, user.ID, 101); err != nil {
+			t.Errorf("UseTwoFactorStep retornou um erro inesperado: %v", err)
+		}
+	})
+
+	t.Run("deve usar o código de recuperação uma única vez", func(t *testing.T) {
+		if err := repo.UseRecoveryCode(ctx, user.ID, hashes[0]); err != nil {
+			t.Fatalf("UseRecoveryCode retornou um erro inesperado: %v", err)
+		}
+		if err := repo.UseRecoveryCode(ctx, user.ID, hashes[0]); !errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+			t.Errorf("Esperava ErrRecoveryCodeNotFound ao reusar o código, mas obtive %v", err)
+		}
+		twoFactor, _ := repo.GetTwoFactor(ctx, user.ID)
+		if twoFactor.RecoveryCodes != 1 {
+			t.Errorf("Esperava 1 código restante, mas obtive %d", twoFactor.RecoveryCodes)
+		}
+	})
+
+	t.Run("deve substituir os códigos de recuperação", func(t *testing.T) {
+		fresh := []string{strings.Repeat("c", 64)}
+		if err := repo.ReplaceRecoveryCodes(ctx, user.ID, fresh); err != nil {
+			t.Fatalf("ReplaceRecoveryCodes retornou um erro inesperado: %v", err)
+		}
+		if err := repo.UseRecoveryCode(ctx, user.ID, hashes[1]); !errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+			t.Errorf("Esperava ErrRecoveryCodeNotFound para um código antigo, mas obtive %v", err)
+		}
+		if err := repo.UseRecoveryCode(ctx, user.ID, fresh[0]); err != nil {
+			t.Errorf("UseRecoveryCode retornou um erro inesperado: %v", err)
+		}
+	})
+
+	t.Run("deve desativar a verificação", func(t *testing.T) {
+		if err := repo.DisableTwoFactor(ctx, user.ID); err != nil {
+			t.Fatalf("DisableTwoFactor retornou um erro inesperado: %v", err)
+		}
+		if _, err := repo.GetTwoFactor(ctx, user.ID); !errors.Is(err, repository.ErrTwoFactorNotFound) {
+			t.Errorf("Esperava ErrTwoFactorNotFound depois de desativar, mas obtive %v", err)
+		}
+		if err := repo.DisableTwoFactor(ctx, 999); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Esperava ErrUserNotFound, mas obtive %v", err)
+		}
+	})
+}
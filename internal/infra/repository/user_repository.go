@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrUserAlreadyExists é retornado ao criar ou alterar um usuário com um email já cadastrado,
+	// sem diferenciar maiúsculas e minúsculas.
+	ErrUserAlreadyExists = errors.New("usuário já cadastrado")
+
+	// ErrUserNotFound é retornado quando um usuário não é encontrado para uma operação.
+	ErrUserNotFound = errors.New("usuário não encontrado")
+)
+
+const (
+	// Todo usuário novo recebe o papel member, na mesma instrução que o cria.
+	createUserQuery = `WITH new_user AS (
+		INSERT INTO users (email, name, password_hash) VALUES ($1, $2, NULLIF($3, '')) RETURNING id, created_at
+	), member AS (
+		INSERT INTO user_roles (user_id, role_id) SELECT new_user.id, roles.id FROM new_user, roles WHERE roles.name = 'member'
+	)
+	SELECT id, created_at FROM new_user`
+	// Os papéis e as permissões são carregados junto com o usuário, para as verificações de acesso.
+	// Os usuários do login único não têm senha, e o hash vem vazio.
+	selectUserQuery = `SELECT users.id, users.email, users.name, COALESCE(users.password_hash, ''), users.created_at, users.email_verified_at,
+		users.totp_enabled_at IS NOT NULL,
+		ARRAY(SELECT roles.name FROM user_roles JOIN roles ON roles.id = user_roles.role_id
+			WHERE user_roles.user_id = users.id ORDER BY roles.name),
+		ARRAY(SELECT DISTINCT permissions.name FROM user_roles
+			JOIN role_permissions ON role_permissions.role_id = user_roles.role_id
+			JOIN permissions ON permissions.id = role_permissions.permission_id
+			WHERE user_roles.user_id = users.id ORDER BY permissions.name)
+	FROM users`
+	getUsersQuery       = selectUserQuery + ` ORDER BY users.name ASC`
+	getUserByIDQuery    = selectUserQuery + ` WHERE users.id = $1`
+	getUserByEmailQuery = selectUserQuery + ` WHERE lower(users.email) = lower($1)`
+	// A confirmação do email é descartada quando o email muda.
+	updateUserProfileQuery = `UPDATE users SET name = $1, email = $2, updated_at = now(),
+		email_verified_at = CASE WHEN lower(email) = lower($2) THEN email_verified_at END
+	WHERE id = $3`
+	updateUserPasswordQuery = `UPDATE users SET password_hash = $1, updated_at = now() WHERE id = $2`
+	markEmailVerifiedQuery  = `UPDATE users SET email_verified_at = now() WHERE id = $1 AND lower(email) = lower($2)`
+)
+
+// UserRepository define a interface para as operações de usuário no banco de dados.
+type UserRepository interface {
+	CreateUser(ctx context.Context, user *domain.User) error
+	GetUsers(ctx context.Context) ([]domain.User, error)
+	GetUserByID(ctx context.Context, id int64) (*domain.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	UpdateUserProfile(ctx context.Context, id int64, name string, email string) error
+	UpdateUserPassword(ctx context.Context, id int64, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id int64, email string) error
+}
+
+// PostgresUserRepository é a implementação do UserRepository para o PostgreSQL.
+type PostgresUserRepository struct{}
+
+// NewPostgresUserRepository cria uma nova instância do repositório.
+func NewPostgresUserRepository() *PostgresUserRepository {
+	return &PostgresUserRepository{}
+}
+
+// CreateUser insere um novo usuário com o papel member, preenchendo o ID e a data de criação.
+// Um PasswordHash vazio cria o usuário sem senha, que só entra pelo login único.
+func (r *PostgresUserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	defer metrics.ObserveQuery("users", "CreateUser")()
+
+	err := database.Conn.QueryRow(ctx, createUserQuery, user.Email, user.Name, user.PasswordHash).Scan(&user.ID, &user.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrUserAlreadyExists
+		}
+		return err
+	}
+	user.Roles = []string{"member"}
+	return nil
+}
+
+// GetUsers retorna todos os usuários ordenados pelo nome.
+func (r *PostgresUserRepository) GetUsers(ctx context.Context) ([]domain.User, error) {
+	defer metrics.ObserveQuery("users", "GetUsers")()
+
+	rows, err := database.Conn.Query(ctx, getUsersQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+// GetUserByID busca um usuário pelo ID.
+func (r *PostgresUserRepository) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
+	defer metrics.ObserveQuery("users", "GetUserByID")()
+	return scanUser(database.Conn.QueryRow(ctx, getUserByIDQuery, id))
+}
+
+// GetUserByEmail busca um usuário pelo email, sem diferenciar maiúsculas e minúsculas.
+func (r *PostgresUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	defer metrics.ObserveQuery("users", "GetUserByEmail")()
+	return scanUser(database.Conn.QueryRow(ctx, getUserByEmailQuery, email))
+}
+
+// UpdateUserProfile atualiza o nome e o email de um usuário.
+func (r *PostgresUserRepository) UpdateUserProfile(ctx context.Context, id int64, name string, email string) error {
+	defer metrics.ObserveQuery("users", "UpdateUserProfile")()
+
+	res, err := database.Conn.Exec(ctx, updateUserProfileQuery, name, email, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrUserAlreadyExists
+		}
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateUserPassword substitui o hash da senha de um usuário.
+func (r *PostgresUserRepository) UpdateUserPassword(ctx context.Context, id int64, passwordHash string) error {
+	defer metrics.ObserveQuery("users", "UpdateUserPassword")()
+
+	res, err := database.Conn.Exec(ctx, updateUserPasswordQuery, passwordHash, id)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// MarkEmailVerified registra a confirmação do email. Retorna ErrUserNotFound se o usuário não existe
+// ou se o email dele já não é o confirmado.
+func (r *PostgresUserRepository) MarkEmailVerified(ctx context.Context, id int64, email string) error {
+	defer metrics.ObserveQuery("users", "MarkEmailVerified")()
+
+	res, err := database.Conn.Exec(ctx, markEmailVerifiedQuery, id, email)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func scanUser(row pgx.Row) (*domain.User, error) {
+	var user domain.User
+	err := row.Scan(&user.ID, &user.Email, &user.Name, &user.PasswordHash, &user.CreatedAt, &user.EmailVerifiedAt, &user.TwoFactorEnabled, &user.Roles, &user.Permissions)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,65 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"testing"
+)
+
+func TestPostgresUserRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	repo := repository.NewPostgresUserRepository()
+
+	user := &domain.User{Email: "Ana@Example.com", Name: "Ana", PasswordHash: "$argon2id$hash"}
+	if err := repo.CreateUser(ctx, user); err != nil {
+		t.Fatalf("Falha ao criar usuário: %v", err)
+	}
+	if user.ID == 0 || user.CreatedAt.IsZero() {
+		t.Fatalf("Esperava o ID e a data de criação preenchidos, mas obtive %+v", user)
+	}
+
+	t.Run("deve recusar um email já cadastrado com outra caixa", func(t *testing.T) {
+		err := repo.CreateUser(ctx, &domain.User{Email: "ana@example.COM", Name: "Outra Ana", PasswordHash: "x"})
+		if !errors.Is(err, repository.ErrUserAlreadyExists) {
+			t.Errorf("Esperava ErrUserAlreadyExists, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve buscar o usuário pelo email sem diferenciar a caixa", func(t *testing.T) {
+		found, err := repo.GetUserByEmail(ctx, "ANA@example.com")
+		if err != nil {
+			t.Fatalf("GetUserByEmail retornou um erro inesperado: %v", err)
+		}
+		if found.ID != user.ID || found.PasswordHash != user.PasswordHash {
+			t.Errorf("Esperava o usuário %d, mas obtive %+v", user.ID, found)
+		}
+	})
+
+	t.Run("deve atualizar o perfil e a senha", func(t *testing.T) {
+		if err := repo.UpdateUserProfile(ctx, user.ID, "Ana Maria", "ana.maria@example.com"); err != nil {
+			t.Fatalf("UpdateUserProfile retornou um erro inesperado: %v", err)
+		}
+		if err := repo.UpdateUserPassword(ctx, user.ID, "$argon2id$novo"); err != nil {
+			t.Fatalf("UpdateUserPassword retornou um erro inesperado: %v", err)
+		}
+		found, err := repo.GetUserByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetUserByID retornou um erro inesperado: %v", err)
+		}
+		if found.Name != "Ana Maria" || found.Email != "ana.maria@example.com" || found.PasswordHash != "$argon2id$novo" {
+			t.Errorf("Usuário não foi atualizado: %+v", found)
+		}
+	})
+
+	t.Run("deve retornar ErrUserNotFound para um usuário inexistente", func(t *testing.T) {
+		if _, err := repo.GetUserByID(ctx, 9999); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Esperava ErrUserNotFound, mas obtive %v", err)
+		}
+		if err := repo.UpdateUserPassword(ctx, 9999, "x"); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Esperava ErrUserNotFound, mas obtive %v", err)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrUserTokenNotFound é retornado quando o token não existe, já foi usado ou expirou.
+var ErrUserTokenNotFound = errors.New("token não encontrado ou expirado")
+
+const (
+	// Um novo token descarta os que ainda não foram usados para a mesma finalidade, de modo que só o último link enviado vale.
+	createUserTokenQuery = `WITH discarded AS (
+		DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	)
+	INSERT INTO user_tokens (user_id, purpose, token_hash, email, expires_at) VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at`
+	userTokenColumns      = `id, user_id, purpose, token_hash, email, expires_at, created_at, used_at`
+	getUserTokenQuery     = `SELECT ` + userTokenColumns + ` FROM user_tokens WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > now()`
+	consumeUserTokenQuery = `UPDATE user_tokens SET used_at = now()
+	WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > now()
+	RETURNING ` + userTokenColumns
+	purgeUserTokensQuery = `DELETE FROM user_tokens WHERE used_at IS NOT NULL OR expires_at < now()`
+)
+
+// UserTokenRepository define a interface para os tokens de uso único enviados por email.
+type UserTokenRepository interface {
+	CreateUserToken(ctx context.Context, token *domain.UserToken) error
+	GetUserToken(ctx context.Context, purpose string, hash string) (*domain.UserToken, error)
+	ConsumeUserToken(ctx context.Context, purpose string, hash string) (*domain.UserToken, error)
+	ResetPassword(ctx context.Context, hash string, passwordHash string) (*domain.UserToken, error)
+}
+
+// PostgresUserTokenRepository é a implementação do UserTokenRepository para o PostgreSQL.
+type PostgresUserTokenRepository struct{}
+
+// NewPostgresUserTokenRepository cria uma nova instância do repositório.
+func NewPostgresUserTokenRepository() *PostgresUserTokenRepository {
+	return &PostgresUserTokenRepository{}
+}
+
+// CreateUserToken insere um novo token, descartando os anteriores da mesma finalidade ainda não usados.
+func (r *PostgresUserTokenRepository) CreateUserToken(ctx context.Context, token *domain.UserToken) error {
+	defer metrics.ObserveQuery("user_tokens", "CreateUserToken")()
+
+	return database.Conn.QueryRow(ctx, createUserTokenQuery,
+		token.UserID, token.Purpose, token.TokenHash, token.Email, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetUserToken busca um token válido sem usá-lo, para exibir o formulário que o consome.
+func (r *PostgresUserTokenRepository) GetUserToken(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+	defer metrics.ObserveQuery("user_tokens", "GetUserToken")()
+	return scanUserToken(database.Conn.QueryRow(ctx, getUserTokenQuery, purpose, hash))
+}
+
+// ConsumeUserToken marca o token como usado e o retorna. Como a marcação é uma única instrução,
+// duas requisições com o mesmo token não conseguem usá-lo ao mesmo tempo.
+func (r *PostgresUserTokenRepository) ConsumeUserToken(ctx context.Context, purpose string, hash string) (*domain.UserToken, error) {
+	defer metrics.ObserveQuery("user_tokens", "ConsumeUserToken")()
+	return scanUserToken(database.Conn.QueryRow(ctx, consumeUserTokenQuery, purpose, hash))
+}
+
+// ResetPassword usa o token de redefinição de senha, troca a senha do usuário e encerra as sessões dele, em uma
+// transação: se uma das etapas falhar, o token continua valendo e nada muda.
+func (r *PostgresUserTokenRepository) ResetPassword(ctx context.Context, hash string, passwordHash string) (*domain.UserToken, error) {
+	defer metrics.ObserveQuery("user_tokens", "ResetPassword")()
+
+	var token *domain.UserToken
+	err := pgx.BeginFunc(ctx, database.Conn, func(tx pgx.Tx) error {
+		var err error
+		token, err = scanUserToken(tx.QueryRow(ctx, consumeUserTokenQuery, domain.TokenPurposePasswordReset, hash))
+		if err != nil {
+			return err
+		}
+		res, err := tx.Exec(ctx, updateUserPasswordQuery, passwordHash, token.UserID)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return ErrUserNotFound
+		}
+		_, err = tx.Exec(ctx, deleteUserSessionsQuery, token.UserID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// PurgeUserTokens remove os tokens usados e os expirados.
+func (r *PostgresUserTokenRepository) PurgeUserTokens(ctx context.Context) (int64, error) {
+	defer metrics.ObserveQuery("user_tokens", "PurgeUserTokens")()
+
+	res, err := database.Conn.Exec(ctx, purgeUserTokensQuery)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// Run chama PurgeUserTokens a cada interval até ctx ser cancelado.
+func (r *PostgresUserTokenRepository) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := r.PurgeUserTokens(ctx); err != nil {
+				slog.ErrorContext(ctx, "erro ao remover tokens de email expirados", "error", err)
+			}
+		}
+	}
+}
+
+func scanUserToken(row pgx.Row) (*domain.UserToken, error) {
+	var token domain.UserToken
+	err := row.Scan(&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.Email, &token.ExpiresAt, &token.CreatedAt, &token.UsedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserTokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
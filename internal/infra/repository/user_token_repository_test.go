@@ -0,0 +1,127 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/domain"
+	"lucienne/internal/infra/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostgresUserTokenRepository(t *testing.T) {
+	setupTestDBAndMigrate(t)
+	ctx := context.Background()
+	users := repository.NewPostgresUserRepository()
+	repo := repository.NewPostgresUserTokenRepository()
+
+	user := &domain.User{Email: "ana@example.com", Name: "Ana", PasswordHash: "x"}
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("Falha ao criar usuário: %v", err)
+	}
+
+	newToken := func(purpose string, hash string, ttl time.Duration) *domain.UserToken {
+		t.Helper()
+		token := &domain.UserToken{UserID: user.ID, Purpose: purpose, TokenHash: hash, Email: user.Email, ExpiresAt: time.Now().Add(ttl)}
+		if err := repo.CreateUserToken(ctx, token); err != nil {
+			t.Fatalf("CreateUserToken retornou um erro inesperado: %v", err)
+		}
+		return token
+	}
+
+	t.Run("deve usar o token uma única vez", func(t *testing.T) {
+		token := newToken(domain.TokenPurposePasswordReset, strings.Repeat("a", 64), time.Hour)
+
+		if _, err := repo.GetUserToken(ctx, domain.TokenPurposeEmailVerification, token.TokenHash); !errors.Is(err, repository.ErrUserTokenNotFound) {
+			t.Errorf("Esperava ErrUserTokenNotFound para outra finalidade, mas obtive %v", err)
+		}
+		consumed, err := repo.ConsumeUserToken(ctx, domain.TokenPurposePasswordReset, token.TokenHash)
+		if err != nil {
+			t.Fatalf("ConsumeUserToken retornou um erro inesperado: %v", err)
+		}
+		if consumed.ID != token.ID || consumed.UserID != user.ID || consumed.UsedAt == nil {
+			t.Errorf("Token inesperado: %+v", consumed)
+		}
+		if _, err := repo.ConsumeUserToken(ctx, domain.TokenPurposePasswordReset, token.TokenHash); !errors.Is(err, repository.ErrUserTokenNotFound) {
+			t.Errorf("Esperava ErrUserTokenNotFound ao reusar o token, mas obtive %v", err)
+		}
+	})
+
+	t.Run("deve descartar o token anterior da mesma finalidade", func(t *testing.T) {
+		first := newToken(domain.TokenPurposeEmailVerification, strings.Repeat("b", 64), time.Hour)
+		second := newToken(domain.TokenPurposeEmailVerification, strings.Repeat("c", 64), time.Hour)
+
+		if _, err := repo.GetUserToken(ctx, domain.TokenPurposeEmailVerification, first.TokenHash); !errors.Is(err, repository.ErrUserTokenNotFound) {
+			t.Errorf("Esperava ErrUserTokenNotFound para o primeiro token, mas obtive %v", err)
+		}
+		if _, err := repo.GetUserToken(ctx, domain.TokenPurposeEmailVerification, second.TokenHash); err != nil {
+			t.Errorf("GetUserToken retornou um erro inesperado: %v", err)
+		}
+	})
+
+	t.Run("deve ignorar e remover os tokens expirados", func(t *testing.T) {
+		expired := newToken(domain.TokenPurposePasswordReset, strings.Repeat("d", 64), -time.Minute)
+
+		if _, err := repo.ConsumeUserToken(ctx, domain.TokenPurposePasswordReset, expired.TokenHash); !errors.Is(err, repository.ErrUserTokenNotFound) {
+			t.Errorf("Esperava ErrUserTokenNotFound para o token expirado, mas obtive %v", err)
+		}
+		purged, err := repo.PurgeUserTokens(ctx)
+		if err != nil {
+			t.Fatalf("PurgeUserTokens retornou um erro inesperado: %v", err)
+		}
+		// O token usado no primeiro subteste e o expirado.
+		if purged != 2 {
+			t.Errorf("Esperava 2 tokens removidos, mas obtive %d", purged)
+		}
+	})
+
+	t.Run("deve confirmar apenas o email para o qual o link foi enviado", func(t *testing.T) {
+		if err := users.MarkEmailVerified(ctx, user.ID, "antigo@example.com"); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Esperava ErrUserNotFound, mas obtive %v", err)
+		}
+		if err := users.MarkEmailVerified(ctx, user.ID, user.Email); err != nil {
+			t.Fatalf("MarkEmailVerified retornou um erro inesperado: %v", err)
+		}
+		found, _ := users.GetUserByID(ctx, user.ID)
+		if !found.EmailVerified() {
+			t.Error("Esperava o email confirmado")
+		}
+
+		if err := users.UpdateUserProfile(ctx, user.ID, user.Name, "nova@example.com"); err != nil {
+			t.Fatalf("UpdateUserProfile retornou um erro inesperado: %v", err)
+		}
+		found, _ = users.GetUserByID(ctx, user.ID)
+		if found.EmailVerified() {
+			t.Error("Esperava a confirmação desfeita após a troca de email")
+		}
+	})
+
+	t.Run("deve trocar a senha e encerrar as sessões ao usar o token de redefinição", func(t *testing.T) {
+		sessions := repository.NewPostgresSessionRepository()
+		now := time.Now()
+		session := &domain.Session{ID: strings.Repeat("e", 64), UserID: user.ID, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)}
+		if err := sessions.CreateSession(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+		token := newToken(domain.TokenPurposePasswordReset, strings.Repeat("f", 64), time.Hour)
+
+		consumed, err := repo.ResetPassword(ctx, token.TokenHash, "novo-hash")
+		if err != nil {
+			t.Fatalf("ResetPassword retornou um erro inesperado: %v", err)
+		}
+		if consumed.ID != token.ID || consumed.UsedAt == nil {
+			t.Errorf("Token inesperado: %+v", consumed)
+		}
+		found, _ := users.GetUserByID(ctx, user.ID)
+		if found.PasswordHash != "novo-hash" {
+			t.Errorf("Esperava a nova senha, mas obtive %q", found.PasswordHash)
+		}
+		if _, err := sessions.GetSession(ctx, session.ID); !errors.Is(err, repository.ErrSessionNotFound) {
+			t.Errorf("Esperava a sessão encerrada, mas obtive %v", err)
+		}
+		if _, err := repo.ResetPassword(ctx, token.TokenHash, "outro-hash"); !errors.Is(err, repository.ErrUserTokenNotFound) {
+			t.Errorf("Esperava ErrUserTokenNotFound ao reusar o token, mas obtive %v", err)
+		}
+	})
+}
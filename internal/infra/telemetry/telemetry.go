@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exportadores de spans suportados.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// instrumentationName identifica os spans criados pelo código da aplicação.
+const instrumentationName = "lucienne"
+
+// Options configura o rastreamento.
+type Options struct {
+	Exporter    string
+	Endpoint    string
+	ServiceName string
+	Environment string
+	SampleRatio float64
+}
+
+// Setup configura o TracerProvider e o propagador globais e retorna a função que envia os spans pendentes e
+// encerra o provider. Com o exportador "none" os spans continuam sendo propagados, mas não são exportados.
+func Setup(ctx context.Context, options Options) (func(ctx context.Context) error, error) {
+	// O contexto de rastreamento é sempre extraído dos cabeçalhos recebidos, mesmo sem exportador.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch options.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		var exporterOptions []otlptracehttp.Option
+		if options.Endpoint != "" {
+			exporterOptions = append(exporterOptions, otlptracehttp.WithEndpointURL(options.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, exporterOptions...)
+	default:
+		return nil, fmt.Errorf("exportador de spans desconhecido: %s", options.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o exportador de spans: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(options.ServiceName),
+		semconv.DeploymentEnvironmentName(options.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer retorna o tracer usado pelo código da aplicação.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
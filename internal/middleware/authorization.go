@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"lucienne/internal/auth"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// LoginPath é a página de login para onde os visitantes anônimos são levados.
+	LoginPath = "/login"
+	// ForbiddenPage é a view renderizada quando o usuário não tem permissão para a rota.
+	ForbiddenPage = "errors/403.html"
+	// TwoFactorSetupPath é a página onde o usuário ativa a verificação em duas etapas.
+	TwoFactorSetupPath = "/profile/2fa"
+)
+
+// RequireUser só deixa passar requisições com um usuário autenticado. Visitantes anônimos que tentam abrir
+// uma página são redirecionados para o login, que depois os devolve à página pedida; as demais requisições
+// recebem 401. Depende do middleware de sessão ter carregado o usuário no contexto.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.CurrentUser(r.Context()) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			http.Redirect(w, r, LoginPath+"?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+		http.Error(w, "É preciso entrar para continuar", http.StatusUnauthorized)
+	})
+}
+
+// RequirePermission só deixa passar usuários com a permissão, respondendo 403 com a página de acesso negado
+// aos demais. Visitantes anônimos são tratados como em RequireUser. Quem ainda precisa ativar a verificação
+// em duas etapas exigida pelo seu papel é levado à página de ativação.
+func RequirePermission(permission string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if auth.TwoFactorSetupRequired(r.Context()) {
+				if r.Method == http.MethodGet || r.Method == http.MethodHead {
+					http.Redirect(w, r, TwoFactorSetupPath, http.StatusSeeOther)
+					return
+				}
+				http.Error(w, "Ative a verificação em duas etapas para continuar", http.StatusForbidden)
+				return
+			}
+			if !auth.Can(r.Context(), permission) {
+				slog.WarnContext(r.Context(), "acesso negado", "permission", permission, "user_id", auth.CurrentUser(r.Context()).ID, "path", r.URL.Path)
+				Forbidden(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
+
+// Forbidden responde 403 com a página de acesso negado. Pode ser usado pelos handlers que verificam
+// permissões com auth.Can.
+func Forbidden(w http.ResponseWriter, r *http.Request) {
+	writeErrorPage(w, r, http.StatusForbidden, ForbiddenPage, "Acesso negado")
+}
+
+// RequireAPIScope só deixa passar requisições autenticadas por um token de API com o escopo, respondendo em JSON
+// 401 sem token e 403 com um token sem o escopo. Quando o escopo exige uma permissão, o dono do token também
+// precisa tê-la, já que ela pode ter sido retirada depois de o token ser criado.
+func RequireAPIScope(scope string) Middleware {
+	definition, ok := auth.LookupScope(scope)
+	if !ok {
+		panic(fmt.Sprintf("middleware: escopo de API desconhecido %q", scope))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := auth.CurrentAPIToken(r.Context())
+			if token == nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeAPIError(w, http.StatusUnauthorized, "unauthorized", "Envie um token de API no cabeçalho Authorization: Bearer <token>")
+				return
+			}
+			if !token.HasScope(scope) || (definition.Permission != "" && !auth.Can(r.Context(), definition.Permission)) {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, scope))
+				writeAPIError(w, http.StatusForbidden, "insufficient_scope", "O token não tem o escopo "+scope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": code, "message": message})
+}
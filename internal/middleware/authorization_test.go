@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRequireUser(t *testing.T) {
+	handler := RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		method   string
+		user     *domain.User
+		status   int
+		location string
+	}{
+		{name: "Usuário autenticado", method: "DELETE", user: &domain.User{ID: 1}, status: http.StatusOK},
+		{name: "Página sem login redireciona", method: "GET", status: http.StatusSeeOther, location: "/login?next=%2Fauthors%2F1%2Fedit%3Ftab%3Ddados"},
+		{name: "Escrita sem login", method: "DELETE", status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/authors/1/edit?tab=dados", nil)
+			if tt.user != nil {
+				req = req.WithContext(auth.WithUser(req.Context(), tt.user))
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Errorf("Expected: %d, Got: %d", tt.status, rr.Code)
+			}
+			if location := rr.Header().Get("Location"); location != tt.location {
+				t.Errorf("Expected: %s, Got: %s", tt.location, location)
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	renderer.HTML.Configure("", fstest.MapFS{ForbiddenPage: {Data: []byte("<h2>Acesso negado</h2>")}}, nil)
+
+	handler := RequirePermission("authors.manage")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		user   *domain.User
+		status int
+	}{
+		{name: "Usuário com a permissão", user: &domain.User{ID: 1, Permissions: []string{"authors.manage"}}, status: http.StatusOK},
+		{name: "Usuário sem a permissão", user: &domain.User{ID: 2, Roles: []string{"member"}}, status: http.StatusForbidden},
+		{name: "Visitante anônimo", status: http.StatusSeeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/authors/new", nil)
+			if tt.user != nil {
+				req = req.WithContext(auth.WithUser(req.Context(), tt.user))
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Errorf("Expected: %d, Got: %d", tt.status, rr.Code)
+			}
+			if tt.status == http.StatusForbidden && !strings.Contains(rr.Body.String(), "Acesso negado") {
+				t.Errorf("esperava a página de acesso negado, obteve %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequirePermissionWithTwoFactorSetup(t *testing.T) {
+	twoFactor := auth.NewTwoFactor(nil, nil, nil, auth.TwoFactorOptions{RequiredRoles: []string{auth.RoleLibrarian}})
+	handler := twoFactor.Middleware(RequirePermission(auth.PermissionManageAuthors)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	tests := []struct {
+		name     string
+		method   string
+		user     *domain.User
+		status   int
+		location string
+	}{
+		{name: "Bibliotecário com a verificação ativada", method: "GET", user: &domain.User{ID: 1, PasswordHash: "x", Roles: []string{auth.RoleLibrarian}, Permissions: []string{auth.PermissionManageAuthors}, TwoFactorEnabled: true}, status: http.StatusOK},
+		{name: "Página sem a verificação ativada", method: "GET", user: &domain.User{ID: 1, PasswordHash: "x", Roles: []string{auth.RoleLibrarian}, Permissions: []string{auth.PermissionManageAuthors}}, status: http.StatusSeeOther, location: TwoFactorSetupPath},
+		{name: "Escrita sem a verificação ativada", method: "DELETE", user: &domain.User{ID: 1, PasswordHash: "x", Roles: []string{auth.RoleLibrarian}, Permissions: []string{auth.PermissionManageAuthors}}, status: http.StatusForbidden},
+		{name: "Usuário do login único", method: "GET", user: &domain.User{ID: 1, Roles: []string{auth.RoleLibrarian}, Permissions: []string{auth.PermissionManageAuthors}}, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/authors/1", nil)
+			req = req.WithContext(auth.WithUser(req.Context(), tt.user))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Errorf("Expected: %d, Got: %d", tt.status, rr.Code)
+			}
+			if location := rr.Header().Get("Location"); location != tt.location {
+				t.Errorf("Expected: %s, Got: %s", tt.location, location)
+			}
+		})
+	}
+}
+
+func TestRequireAPIScope(t *testing.T) {
+	handler := RequireAPIScope(auth.ScopeAuthorsWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	librarian := &domain.User{ID: 1, Permissions: []string{auth.PermissionManageAuthors}}
+	member := &domain.User{ID: 2}
+
+	tests := []struct {
+		name   string
+		user   *domain.User
+		token  *domain.APIToken
+		status int
+	}{
+		{name: "Token com o escopo", user: librarian, token: &domain.APIToken{Scopes: []string{auth.ScopeAuthorsWrite}}, status: http.StatusCreated},
+		{name: "Sem token, mesmo com sessão", user: librarian, status: http.StatusUnauthorized},
+		{name: "Token sem o escopo", user: librarian, token: &domain.APIToken{Scopes: []string{auth.ScopeAuthorsRead}}, status: http.StatusForbidden},
+		{name: "Dono do token perdeu a permissão", user: member, token: &domain.APIToken{Scopes: []string{auth.ScopeAuthorsWrite}}, status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.WithUser(context.Background(), tt.user)
+			if tt.token != nil {
+				ctx = auth.WithAPIToken(ctx, tt.token)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, httptest.NewRequest("POST", "/api/authors", nil).WithContext(ctx))
+
+			if rr.Code != tt.status {
+				t.Errorf("Expected: %d, Got: %d", tt.status, rr.Code)
+			}
+			if tt.status != http.StatusCreated && rr.Header().Get("Content-Type") != "application/json" {
+				t.Errorf("Expected: application/json, Got: %s", rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
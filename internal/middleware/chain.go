@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Middleware envolve um handler, como os aceitos por mux.Router.Use.
+type Middleware = func(http.Handler) http.Handler
+
+// Chain compõe os middlewares em um só. O primeiro da lista é o mais externo, ou seja, o primeiro a receber a requisição.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// Unmatched faz as respostas 404 (nenhuma rota) e 405 (método não permitido) do roteador passarem pelo middleware,
+// já que o mux só aplica os registrados com router.Use quando alguma rota é encontrada.
+func Unmatched(router *mux.Router, middleware Middleware) {
+	router.NotFoundHandler = middleware(http.NotFoundHandler())
+	router.MethodNotAllowedHandler = middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+}
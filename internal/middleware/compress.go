@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressibleTypes lista os tipos de conteúdo comprimidos. Imagens e fontes já são comprimidas.
+var compressibleTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+}
+
+var (
+	gzipWriters   = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+	brotliWriters = sync.Pool{New: func() any { return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression) }}
+)
+
+// Compress comprime as respostas textuais com brotli ou gzip, conforme o Accept-Encoding da requisição.
+// Respostas que já definem Content-Encoding (como as de /metrics) passam sem alteração.
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding escolhe br ou gzip, nessa ordem de preferência, entre os aceitos pelo cliente.
+func negotiateEncoding(acceptEncoding string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				continue
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	for _, encoding := range []string{"br", "gzip"} {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressWriter adia o envio do cabeçalho até a primeira escrita, para conhecer o Content-Type
+// (mesmo quando só é detectado a partir do conteúdo) e decidir se a resposta será comprimida.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	writer      io.WriteCloser
+	status      int
+	wroteHeader bool
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	// Respostas informativas (1xx) não encerram o cabeçalho.
+	if status >= http.StatusContinue && status < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		cw.sendHeader()
+	}
+	if cw.writer == nil {
+		return cw.ResponseWriter.Write(b)
+	}
+	return cw.writer.Write(b)
+}
+
+func (cw *compressWriter) sendHeader() {
+	cw.wroteHeader = true
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+
+	header := cw.Header()
+	if shouldCompress(cw.status, header) {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		cw.writer = cw.newWriter()
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+// Flush envia o que já foi comprimido, para respostas transmitidas aos poucos.
+func (cw *compressWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.sendHeader()
+	}
+	if flusher, ok := cw.writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Close finaliza o fluxo comprimido e devolve o writer ao pool.
+func (cw *compressWriter) Close() error {
+	if !cw.wroteHeader && cw.status != 0 {
+		// O handler definiu o status mas não escreveu corpo.
+		cw.wroteHeader = true
+		cw.ResponseWriter.WriteHeader(cw.status)
+	}
+	if cw.writer == nil {
+		return nil
+	}
+	err := cw.writer.Close()
+	switch writer := cw.writer.(type) {
+	case *gzip.Writer:
+		gzipWriters.Put(writer)
+	case *brotli.Writer:
+		brotliWriters.Put(writer)
+	}
+	cw.writer = nil
+	return err
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) newWriter() io.WriteCloser {
+	if cw.encoding == "br" {
+		writer := brotliWriters.Get().(*brotli.Writer)
+		writer.Reset(cw.ResponseWriter)
+		return writer
+	}
+	writer := gzipWriters.Get().(*gzip.Writer)
+	writer.Reset(cw.ResponseWriter)
+	return writer
+}
+
+func shouldCompress(status int, header http.Header) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, compressible := range compressibleTypes {
+		if strings.HasPrefix(mediaType, compressible) {
+			return true
+		}
+	}
+	return false
+}
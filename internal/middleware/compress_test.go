@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestCompress(t *testing.T) {
+	page := strings.Repeat("<p>Autor</p>", 200)
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Como os handlers da aplicação: status explícito e Content-Type detectado a partir do conteúdo.
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(page))
+	}))
+
+	testCases := []struct {
+		name           string
+		acceptEncoding string
+		encoding       string
+		decode         func(io.Reader) io.Reader
+	}{
+		{name: "prefere brotli", acceptEncoding: "gzip, deflate, br", encoding: "br", decode: func(r io.Reader) io.Reader { return brotli.NewReader(r) }},
+		{name: "usa gzip", acceptEncoding: "gzip", encoding: "gzip", decode: func(r io.Reader) io.Reader {
+			reader, err := gzip.NewReader(r)
+			if err != nil {
+				t.Fatalf("resposta gzip inválida: %v", err)
+			}
+			return reader
+		}},
+		{name: "ignora codificações recusadas com q=0", acceptEncoding: "br;q=0, identity", encoding: "", decode: func(r io.Reader) io.Reader { return r }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/authors", nil)
+			request.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, request)
+
+			if got := rr.Header().Get("Content-Encoding"); got != tc.encoding {
+				t.Fatalf("Expected: %q, Got: %q", tc.encoding, got)
+			}
+			body, err := io.ReadAll(tc.decode(rr.Body))
+			if err != nil || string(body) != page {
+				t.Errorf("corpo descomprimido inesperado (err: %v)", err)
+			}
+		})
+	}
+
+	t.Run("não comprime conteúdo já comprimido", func(t *testing.T) {
+		handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write([]byte("jpeg"))
+		}))
+		request := httptest.NewRequest("GET", "/assets/lucienne.jpg", nil)
+		request.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, request)
+
+		if rr.Header().Get("Content-Encoding") != "" || rr.Body.String() != "jpeg" {
+			t.Errorf("esperava a resposta sem compressão, obteve %q", rr.Header().Get("Content-Encoding"))
+		}
+	})
+
+	t.Run("mantém o status de respostas sem corpo", func(t *testing.T) {
+		handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		request := httptest.NewRequest("DELETE", "/authors/1", nil)
+		request.Header.Set("Accept-Encoding", "gzip")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, request)
+
+		if rr.Code != http.StatusNoContent || rr.Header().Get("Content-Encoding") != "" {
+			t.Errorf("Expected: 204 sem Content-Encoding, Got: %d %q", rr.Code, rr.Header().Get("Content-Encoding"))
+		}
+	})
+}
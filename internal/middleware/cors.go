@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configura quais origens podem acessar as rotas JSON a partir do navegador.
+type CORSOptions struct {
+	// AllowedOrigins lista as origens aceitas. "*" aceita qualquer origem, mas não com credenciais.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORS responde às requisições de preflight e adiciona os cabeçalhos de CORS para as origens permitidas.
+// Requisições de outras origens seguem sem os cabeçalhos, e o navegador bloqueia a leitura da resposta.
+// Como middleware do mux, só roda para rotas encontradas, então o roteador precisa aceitar OPTIONS nas rotas protegidas.
+func CORS(options CORSOptions) Middleware {
+	allowAny := slices.Contains(options.AllowedOrigins, "*") && !options.AllowCredentials
+	methods := strings.Join(options.AllowedMethods, ", ")
+	headers := strings.Join(options.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(options.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			w.Header().Add("Vary", "Origin")
+			if origin == "" || !(allowAny || slices.Contains(options.AllowedOrigins, origin)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowAny {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if options.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
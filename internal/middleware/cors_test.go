@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	var called bool
+	handler := CORS(CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		MaxAge:         10 * time.Minute,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	t.Run("responde ao preflight de uma origem permitida", func(t *testing.T) {
+		called = false
+		request := httptest.NewRequest("OPTIONS", "/api/authors", nil)
+		request.Header.Set("Origin", "https://app.example.com")
+		request.Header.Set("Access-Control-Request-Method", "POST")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, request)
+
+		if rr.Code != http.StatusNoContent || called {
+			t.Errorf("esperava 204 sem chamar o handler, obteve %d (chamado: %v)", rr.Code, called)
+		}
+		expected := map[string]string{
+			"Access-Control-Allow-Origin":  "https://app.example.com",
+			"Access-Control-Allow-Methods": "GET, POST",
+			"Access-Control-Allow-Headers": "Authorization, Content-Type",
+			"Access-Control-Max-Age":       "600",
+		}
+		for name, value := range expected {
+			if got := rr.Header().Get(name); got != value {
+				t.Errorf("%s: Expected: %q, Got: %q", name, value, got)
+			}
+		}
+	})
+
+	t.Run("não libera origens desconhecidas", func(t *testing.T) {
+		called = false
+		request := httptest.NewRequest("GET", "/api/authors", nil)
+		request.Header.Set("Origin", "https://evil.example.com")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, request)
+
+		if rr.Header().Get("Access-Control-Allow-Origin") != "" || !called {
+			t.Errorf("esperava a requisição sem cabeçalhos de CORS, obteve %q", rr.Header().Get("Access-Control-Allow-Origin"))
+		}
+	})
+}
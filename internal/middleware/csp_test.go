@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCSP(t *testing.T) {
+	var nonces []string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonces = append(nonces, CSPNonce(r.Context()))
+	})
+
+	t.Run("envia a política com o nonce da requisição", func(t *testing.T) {
+		nonces = nil
+		handler := CSP(CSPOptions{ReportURI: "/csp-report"})(next)
+
+		var policies []string
+		for range 2 {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+			policies = append(policies, rr.Header().Get("Content-Security-Policy"))
+		}
+
+		if nonces[0] == "" || nonces[0] == nonces[1] {
+			t.Fatalf("esperava um nonce diferente por requisição, obteve %v", nonces)
+		}
+		for i, policy := range policies {
+			for _, expected := range []string{"script-src 'self' 'nonce-" + nonces[i] + "'", "object-src 'none'", "report-uri /csp-report"} {
+				if !strings.Contains(policy, expected) {
+					t.Errorf("esperava %q na política, obteve %q", expected, policy)
+				}
+			}
+		}
+	})
+
+	t.Run("usa o cabeçalho report-only", func(t *testing.T) {
+		handler := CSP(CSPOptions{ReportOnly: true})(next)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+		if rr.Header().Get("Content-Security-Policy") != "" || rr.Header().Get("Content-Security-Policy-Report-Only") == "" {
+			t.Errorf("esperava apenas Content-Security-Policy-Report-Only, obteve %v", rr.Header())
+		}
+		if strings.Contains(rr.Header().Get("Content-Security-Policy-Report-Only"), "report-uri") {
+			t.Error("não esperava report-uri sem endereço configurado")
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// CSRFFieldName é o campo de formulário com o token.
+	CSRFFieldName = "csrf_token"
+	// CSRFHeader é o cabeçalho alternativo ao campo, para requisições feitas por JavaScript.
+	CSRFHeader = "X-CSRF-Token"
+
+	csrfTokenLength = 32
+)
+
+type csrfTokenKey struct{}
+
+// CSRFOptions configura a proteção contra CSRF.
+type CSRFOptions struct {
+	// Secure envia o cookie apenas por HTTPS, com o prefixo __Host-.
+	Secure bool
+	// Exempt indica requisições que não precisam de token, como as autenticadas por token de API.
+	Exempt func(r *http.Request) bool
+}
+
+// CSRF protege os formulários com o padrão double submit: o token fica em um cookie HttpOnly e deve ser repetido
+// no campo csrf_token (ou no cabeçalho X-CSRF-Token) de toda requisição que altera estado, que recebe 403 caso contrário.
+// O token enviado na página é mascarado a cada requisição, para não ser revelado pela compressão (BREACH).
+func CSRF(options CSRFOptions) Middleware {
+	cookieName := "csrf"
+	if options.Secure {
+		cookieName = "__Host-csrf"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := csrfCookieToken(r, cookieName)
+			if token == nil {
+				token = make([]byte, csrfTokenLength)
+				rand.Read(token)
+				http.SetCookie(w, &http.Cookie{
+					Name:     cookieName,
+					Value:    base64.RawURLEncoding.EncodeToString(token),
+					Path:     "/",
+					Secure:   options.Secure,
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+			r = r.WithContext(context.WithValue(r.Context(), csrfTokenKey{}, token))
+			// O cookie pode ter mudado, então as respostas não podem ser compartilhadas por caches.
+			w.Header().Add("Vary", "Cookie")
+
+			if safeMethod(r.Method) || (options.Exempt != nil && options.Exempt(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !sameOrigin(r) || !validCSRFToken(token, submittedCSRFToken(r)) {
+				slog.WarnContext(r.Context(), "requisição recusada pela proteção CSRF", "method", r.Method, "path", r.URL.Path)
+				http.Error(w, "Token CSRF inválido ou ausente. Recarregue a página e tente novamente.", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CSRFToken retorna o token mascarado da requisição, para envio em formulários ou cabeçalhos.
+func CSRFToken(ctx context.Context) string {
+	token, _ := ctx.Value(csrfTokenKey{}).([]byte)
+	if token == nil {
+		return ""
+	}
+	return maskCSRFToken(token)
+}
+
+// CSRFField retorna o campo oculto com o token, usado pela função csrfField dos templates.
+func CSRFField(ctx context.Context) template.HTML {
+	return template.HTML(`<input type="hidden" name="` + CSRFFieldName + `" value="` + template.HTMLEscapeString(CSRFToken(ctx)) + `">`)
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func csrfCookieToken(r *http.Request, name string) []byte {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return nil
+	}
+	token, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || len(token) != csrfTokenLength {
+		return nil
+	}
+	return token
+}
+
+func submittedCSRFToken(r *http.Request) string {
+	if token := r.Header.Get(CSRFHeader); token != "" {
+		return token
+	}
+	return r.PostFormValue(CSRFFieldName)
+}
+
+// sameOrigin recusa requisições cujo Origin, quando enviado pelo navegador, é de outro host.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && parsed.Host == r.Host
+}
+
+// maskCSRFToken retorna base64(pad || pad XOR token), com um pad aleatório novo a cada chamada.
+func maskCSRFToken(token []byte) string {
+	masked := make([]byte, 2*len(token))
+	pad := masked[:len(token)]
+	rand.Read(pad)
+	for i := range token {
+		masked[len(token)+i] = pad[i] ^ token[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(masked)
+}
+
+func validCSRFToken(token []byte, submitted string) bool {
+	masked, err := base64.RawURLEncoding.DecodeString(submitted)
+	if err != nil || len(masked) != 2*csrfTokenLength {
+		return false
+	}
+	unmasked := make([]byte, csrfTokenLength)
+	for i := range unmasked {
+		unmasked[i] = masked[i] ^ masked[csrfTokenLength+i]
+	}
+	return subtle.ConstantTimeCompare(token, unmasked) == 1
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	var token string
+	handler := CSRF(CSRFOptions{
+		Exempt: func(r *http.Request) bool { return r.Header.Get("Authorization") != "" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = CSRFToken(r.Context())
+	}))
+
+	// Obtém o cookie e um token como faria o navegador ao abrir o formulário.
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/authors/new", nil))
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly || token == "" {
+		t.Fatalf("esperava um cookie HttpOnly e um token, obteve %v e %q", cookies, token)
+	}
+	formToken := token
+
+	post := func(form url.Values, headers map[string]string) int {
+		request := httptest.NewRequest("POST", "/authors", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.AddCookie(cookies[0])
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, request)
+		return rr.Code
+	}
+
+	testCases := []struct {
+		name           string
+		form           url.Values
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{name: "aceita o token do formulário", form: url.Values{CSRFFieldName: {formToken}}, expectedStatus: http.StatusOK},
+		{name: "aceita o token no cabeçalho", headers: map[string]string{CSRFHeader: formToken}, expectedStatus: http.StatusOK},
+		{name: "recusa a requisição sem token", expectedStatus: http.StatusForbidden},
+		{name: "recusa um token de outro cookie", form: url.Values{CSRFFieldName: {maskCSRFToken(make([]byte, csrfTokenLength))}}, expectedStatus: http.StatusForbidden},
+		{name: "recusa outra origem mesmo com token", form: url.Values{CSRFFieldName: {formToken}}, headers: map[string]string{"Origin": "https://evil.example.com"}, expectedStatus: http.StatusForbidden},
+		{name: "dispensa as requisições isentas", headers: map[string]string{"Authorization": "Bearer abc"}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if status := post(tc.form, tc.headers); status != tc.expectedStatus {
+				t.Errorf("Expected: %d, Got: %d", tc.expectedStatus, status)
+			}
+		})
+	}
+
+	t.Run("mascara o token de forma diferente a cada requisição", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "/authors/new", nil)
+		request.AddCookie(cookies[0])
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		if token == formToken {
+			t.Error("esperava um token mascarado diferente")
+		}
+		if status := post(url.Values{CSRFFieldName: {token}}, nil); status != http.StatusOK {
+			t.Errorf("esperava o novo token aceito, obteve %d", status)
+		}
+	})
+}
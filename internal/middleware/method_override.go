@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MethodOverrideField é o campo oculto dos formulários com o método desejado.
+const MethodOverrideField = "_method"
+
+// MethodOverride permite que formulários HTML, que só enviam GET e POST, façam PUT, PATCH e DELETE
+// com um campo oculto _method em um POST. Precisa envolver o roteador, pois o método é usado para encontrar a rota.
+func MethodOverride(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && isFormRequest(r) {
+			switch method := strings.ToUpper(r.PostFormValue(MethodOverrideField)); method {
+			case http.MethodPut, http.MethodPatch, http.MethodDelete:
+				r.Method = method
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isFormRequest evita ler o corpo de requisições que não são formulários, como as da API em JSON.
+func isFormRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	return strings.HasPrefix(contentType, "application/x-www-form-urlencoded") || strings.HasPrefix(contentType, "multipart/form-data")
+}
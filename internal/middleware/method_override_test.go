@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMethodOverride(t *testing.T) {
+	var method, name string
+	handler := MethodOverride(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		name = r.FormValue("name")
+	}))
+
+	testCases := []struct {
+		name           string
+		method         string
+		contentType    string
+		override       string
+		expectedMethod string
+	}{
+		{name: "troca POST por DELETE", method: "POST", contentType: "application/x-www-form-urlencoded", override: "DELETE", expectedMethod: "DELETE"},
+		{name: "aceita o método em minúsculas", method: "POST", contentType: "application/x-www-form-urlencoded", override: "put", expectedMethod: "PUT"},
+		{name: "ignora métodos não permitidos", method: "POST", contentType: "application/x-www-form-urlencoded", override: "GET", expectedMethod: "POST"},
+		{name: "ignora requisições que não são POST", method: "PUT", contentType: "application/x-www-form-urlencoded", override: "DELETE", expectedMethod: "PUT"},
+		{name: "ignora corpos que não são formulários", method: "POST", contentType: "application/json", override: "DELETE", expectedMethod: "POST"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			form := url.Values{MethodOverrideField: {tc.override}, "name": {"Autor"}}
+			request := httptest.NewRequest(tc.method, "/authors/1", strings.NewReader(form.Encode()))
+			request.Header.Set("Content-Type", tc.contentType)
+
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			if method != tc.expectedMethod {
+				t.Errorf("Expected: %s, Got: %s", tc.expectedMethod, method)
+			}
+			if tc.contentType != "application/json" && name != "Autor" {
+				t.Errorf("esperava os demais campos do formulário disponíveis, obteve %q", name)
+			}
+		})
+	}
+}
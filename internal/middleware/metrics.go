@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"lucienne/internal/infra/metrics"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Metrics registra a contagem e a duração das requisições, rotuladas pelo template da rota do mux
+// (ex.: /authors/{id}) em vez do caminho real, para não gerar uma série por ID.
+// Deve ser registrado com router.Use, que executa o middleware depois de a rota ser encontrada, e com Unmatched
+// para que os 404 e 405 também sejam contados.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newStatusRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		metrics.ObserveRequest(r.Method, routeTemplate(r), strconv.Itoa(recorder.status), time.Since(start))
+	})
+}
+
+// routeTemplate retorna o template da rota encontrada pelo mux para a requisição.
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "unmatched"
+	}
+	if template, err := route.GetPathTemplate(); err == nil {
+		return template
+	}
+	return "unmatched"
+}
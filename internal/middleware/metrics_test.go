@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"lucienne/internal/infra/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMetrics(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(Metrics)
+	router.HandleFunc("/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+
+	before := requestCount(t, "GET", "/authors/{id}", "404")
+	for _, id := range []string{"1", "2", "3"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/authors/"+id, nil))
+	}
+
+	if count := requestCount(t, "GET", "/authors/{id}", "404") - before; count != 3 {
+		t.Errorf("esperava 3 requisições rotuladas com o template /authors/{id}, obteve %v", count)
+	}
+}
+
+func TestMetricsUnmatched(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(Metrics)
+	Unmatched(router, Metrics)
+	router.HandleFunc("/authors", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+
+	notFound := requestCount(t, "GET", "unmatched", "404")
+	methodNotAllowed := requestCount(t, "DELETE", "unmatched", "405")
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/nao-existe", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("handler retornou status code errado: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("DELETE", "/authors", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("handler retornou status code errado: got %v want %v", rr.Code, http.StatusMethodNotAllowed)
+	}
+
+	if count := requestCount(t, "GET", "unmatched", "404") - notFound; count != 1 {
+		t.Errorf("esperava 1 requisição 404 rotulada como unmatched, obteve %v", count)
+	}
+	if count := requestCount(t, "DELETE", "unmatched", "405") - methodNotAllowed; count != 1 {
+		t.Errorf("esperava 1 requisição 405 rotulada como unmatched, obteve %v", count)
+	}
+}
+
+// requestCount retorna o total de requisições registradas com o método, a rota e o status informados.
+func requestCount(t *testing.T, method, route, status string) float64 {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("erro ao coletar métricas: %v", err)
+	}
+
+	var count float64
+	for _, family := range families {
+		if family.GetName() != "lucienne_http_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["route"] == route && labels["method"] == method && labels["status"] == status {
+				count += metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return count
+}
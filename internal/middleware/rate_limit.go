@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"log/slog"
+	"lucienne/internal/auth"
+	"lucienne/pkg/ratelimit"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RateLimitOptions configura o rate limit de um grupo de rotas.
+type RateLimitOptions struct {
+	// Group separa os baldes de cada grupo, para que um cliente tenha um limite por grupo.
+	Group string
+	Store ratelimit.Store
+	Limit ratelimit.Limit
+	// Methods restringe o limite a esses métodos. Vazio limita todos.
+	Methods []string
+	// TrustProxy usa o primeiro endereço de X-Forwarded-For como IP do cliente. Só deve ser ativado atrás de um proxy
+	// que sobrescreva esse cabeçalho, senão qualquer cliente escolhe o próprio IP.
+	TrustProxy bool
+}
+
+// RateLimit limita as requisições pelo token de API que as autenticou ou, sem ele, pelo IP do cliente,
+// respondendo 429 com Retry-After quando o balde está vazio. Se o store falhar, a requisição segue.
+// Para limitar por token, deve ser registrado depois do autenticador; o cabeçalho Authorization em si
+// nunca é usado como chave, senão bastaria trocar o valor a cada requisição para ganhar um balde novo.
+func RateLimit(options RateLimitOptions) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(options.Methods) > 0 && !slices.Contains(options.Methods, r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := options.Group + ":" + clientKey(r, options.TrustProxy)
+			result, err := options.Store.Take(r.Context(), key, options.Limit)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "erro ao consultar o rate limit", "error", err, "group", options.Group)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(options.Limit.Burst))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+				http.Error(w, "Muitas requisições. Tente novamente mais tarde.", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientKey identifica o cliente pelo ID do token de API já autenticado ou pelo IP.
+func clientKey(r *http.Request, trustProxy bool) string {
+	if token := auth.CurrentAPIToken(r.Context()); token != nil {
+		return "token:" + strconv.FormatInt(token.ID, 10)
+	}
+	return "ip:" + ClientIP(r, trustProxy)
+}
+
+// ClientIP retorna o IP do cliente, considerando X-Forwarded-For apenas quando trustProxy for verdadeiro.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"lucienne/internal/auth"
+	"lucienne/internal/domain"
+	"lucienne/pkg/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("banco indisponível")
+}
+
+func TestRateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	limit := ratelimit.Limit{Requests: 1, Period: time.Minute, Burst: 1}
+
+	sendWith := func(handler http.Handler, method string, remoteAddr string, headers map[string]string, token *domain.APIToken) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/authors", nil)
+		request.RemoteAddr = remoteAddr
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		if token != nil {
+			request = request.WithContext(auth.WithAPIToken(request.Context(), token))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, request)
+		return rr
+	}
+	send := func(handler http.Handler, method string, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+		return sendWith(handler, method, remoteAddr, headers, nil)
+	}
+
+	t.Run("responde 429 com Retry-After quando o balde esvazia", func(t *testing.T) {
+		handler := RateLimit(RateLimitOptions{Group: "write", Store: ratelimit.NewMemoryStore(), Limit: limit})(ok)
+
+		if rr := send(handler, "POST", "10.0.0.1:5000", nil); rr.Code != http.StatusOK {
+			t.Fatalf("Expected: 200, Got: %d", rr.Code)
+		}
+		rr := send(handler, "POST", "10.0.0.1:5001", nil)
+		if rr.Code != http.StatusTooManyRequests {
+			t.Fatalf("Expected: 429, Got: %d", rr.Code)
+		}
+		if rr.Header().Get("Retry-After") != "60" {
+			t.Errorf("Expected: Retry-After 60, Got: %q", rr.Header().Get("Retry-After"))
+		}
+		if rr := send(handler, "POST", "10.0.0.2:5000", nil); rr.Code != http.StatusOK {
+			t.Errorf("esperava um balde separado para outro IP, obteve %d", rr.Code)
+		}
+	})
+
+	t.Run("separa os clientes pelo token de API autenticado", func(t *testing.T) {
+		handler := RateLimit(RateLimitOptions{Group: "api", Store: ratelimit.NewMemoryStore(), Limit: limit})(ok)
+
+		sendWith(handler, "GET", "10.0.0.1:5000", nil, &domain.APIToken{ID: 1})
+		if rr := sendWith(handler, "GET", "10.0.0.1:5000", nil, &domain.APIToken{ID: 2}); rr.Code != http.StatusOK {
+			t.Errorf("esperava um balde por token, obteve %d", rr.Code)
+		}
+		if rr := sendWith(handler, "GET", "10.0.0.2:5000", nil, &domain.APIToken{ID: 1}); rr.Code != http.StatusTooManyRequests {
+			t.Errorf("esperava o mesmo balde para o token em outro IP, obteve %d", rr.Code)
+		}
+	})
+
+	t.Run("não cria um balde novo para cada valor de Authorization", func(t *testing.T) {
+		handler := RateLimit(RateLimitOptions{Group: "write", Store: ratelimit.NewMemoryStore(), Limit: limit})(ok)
+
+		send(handler, "POST", "10.0.0.1:5000", map[string]string{"Authorization": "Bearer falso-1"})
+		if rr := send(handler, "POST", "10.0.0.1:5000", map[string]string{"Authorization": "Bearer falso-2"}); rr.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected: 429, Got: %d", rr.Code)
+		}
+	})
+
+	t.Run("conta os tokens inválidos pelo IP antes da autenticação", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		// Recusa qualquer token, como o autenticador faz com os inválidos.
+		authenticator := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			})
+		}
+		handler := Chain(
+			RateLimit(RateLimitOptions{Group: "api-ip", Store: store, Limit: limit}),
+			authenticator,
+			RateLimit(RateLimitOptions{Group: "api", Store: store, Limit: limit}),
+		)(ok)
+
+		if rr := send(handler, "GET", "10.0.0.1:5000", map[string]string{"Authorization": "Bearer falso-1"}); rr.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected: 401, Got: %d", rr.Code)
+		}
+		if rr := send(handler, "GET", "10.0.0.1:5000", map[string]string{"Authorization": "Bearer falso-2"}); rr.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected: 429, Got: %d", rr.Code)
+		}
+	})
+
+	t.Run("só limita os métodos configurados", func(t *testing.T) {
+		handler := RateLimit(RateLimitOptions{Group: "write", Store: ratelimit.NewMemoryStore(), Limit: limit, Methods: []string{"POST"}})(ok)
+
+		for range 3 {
+			if rr := send(handler, "GET", "10.0.0.1:5000", nil); rr.Code != http.StatusOK {
+				t.Fatalf("Expected: 200, Got: %d", rr.Code)
+			}
+		}
+	})
+
+	t.Run("usa X-Forwarded-For apenas atrás de um proxy confiável", func(t *testing.T) {
+		headers := map[string]string{"X-Forwarded-For": "203.0.113.9, 10.0.0.1"}
+		request := httptest.NewRequest("GET", "/", nil)
+		request.RemoteAddr = "10.0.0.1:5000"
+		request.Header.Set("X-Forwarded-For", headers["X-Forwarded-For"])
+
+		if ip := ClientIP(request, true); ip != "203.0.113.9" {
+			t.Errorf("Expected: 203.0.113.9, Got: %s", ip)
+		}
+		if ip := ClientIP(request, false); ip != "10.0.0.1" {
+			t.Errorf("Expected: 10.0.0.1, Got: %s", ip)
+		}
+	})
+
+	t.Run("deixa a requisição passar quando o store falha", func(t *testing.T) {
+		handler := RateLimit(RateLimitOptions{Group: "write", Store: failingStore{}, Limit: limit})(ok)
+
+		if rr := send(handler, "POST", "10.0.0.1:5000", nil); rr.Code != http.StatusOK {
+			t.Errorf("Expected: 200, Got: %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"runtime/debug"
+)
+
+// ErrorPage é a view renderizada quando um handler entra em pânico.
+const ErrorPage = "errors/500.html"
+
+// Recovery recupera pânicos dos handlers, registra o erro com a pilha e responde 500 com a página de erro.
+// Se o handler já tiver começado a responder, a resposta não pode mais ser trocada e a conexão é apenas encerrada.
+func Recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := newStatusRecorder(w)
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// http.ErrAbortHandler é usado para abortar a resposta de propósito e não deve ser registrado.
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			slog.ErrorContext(r.Context(), "pânico ao atender a requisição",
+				"error", fmt.Sprint(recovered),
+				"method", r.Method,
+				"path", r.URL.Path,
+				"stack", string(debug.Stack()),
+			)
+
+			if recorder.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			writeErrorPage(recorder, r, http.StatusInternalServerError, ErrorPage, "Erro interno do servidor")
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// writeErrorPage responde com a view de erro, ou apenas com message se a view não puder ser renderizada.
+func writeErrorPage(w http.ResponseWriter, r *http.Request, status int, view string, message string) {
+	page, err := renderer.HTML.Render(r.Context(), view, nil)
+	if err != nil {
+		http.Error(w, message, status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write(page)
+}
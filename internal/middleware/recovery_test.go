@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"lucienne/pkg/renderer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRecovery(t *testing.T) {
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	renderer.HTML.Configure("", fstest.MapFS{ErrorPage: {Data: []byte("<h2>Algo deu errado</h2>")}}, nil)
+
+	t.Run("responde 500 quando o handler entra em pânico", func(t *testing.T) {
+		handler := Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("falha inesperada")
+		}))
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/authors", nil))
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("Expected: %d, Got: %d", http.StatusInternalServerError, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Algo deu errado") {
+			t.Errorf("esperava a página de erro, obteve %q", rr.Body.String())
+		}
+		if strings.Contains(rr.Body.String(), "falha inesperada") {
+			t.Errorf("o valor do pânico não deve ser exposto na resposta: %q", rr.Body.String())
+		}
+	})
+
+	t.Run("aborta a resposta quando o handler já começou a responder", func(t *testing.T) {
+		handler := Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("parcial"))
+			panic("falha inesperada")
+		}))
+
+		defer func() {
+			if recovered := recover(); recovered != http.ErrAbortHandler {
+				t.Errorf("Expected: http.ErrAbortHandler, Got: %v", recovered)
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/authors", nil))
+	})
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"lucienne/pkg/logger"
+	"net/http"
+)
+
+// RequestIDHeader é o cabeçalho usado para receber e devolver o ID da requisição.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limita o tamanho de um ID recebido de um proxy, para não poluir os logs.
+const maxRequestIDLength = 128
+
+// RequestID associa um ID à requisição, reaproveitando o recebido em X-Request-ID quando for válido.
+// O ID é guardado no contexto, incluído nos logs feitos com ele e devolvido no cabeçalho da resposta.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID aceita apenas caracteres visíveis comuns em IDs (letras, dígitos, "-", "_", "." e ":").
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"lucienne/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRequestID(t *testing.T) {
+	var received string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = logger.RequestID(r.Context())
+	}))
+
+	testCases := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "gera um ID quando não há cabeçalho", incoming: "", keep: false},
+		{name: "reaproveita um ID válido", incoming: "req-42.a:b_c", keep: true},
+		{name: "descarta um ID com caracteres inválidos", incoming: "abc\n<script>", keep: false},
+		{name: "descarta um ID longo demais", incoming: strings.Repeat("a", 200), keep: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/", nil)
+			request.Header.Set(RequestIDHeader, tc.incoming)
+			response := httptest.NewRecorder()
+
+			handler.ServeHTTP(response, request)
+
+			returned := response.Header().Get(RequestIDHeader)
+			if returned == "" || returned != received {
+				t.Fatalf("esperava o mesmo ID no contexto e na resposta, obteve %q e %q", received, returned)
+			}
+			if tc.keep && returned != tc.incoming {
+				t.Errorf("Expected: %s, Got: %s", tc.incoming, returned)
+			}
+			if !tc.keep && returned == tc.incoming {
+				t.Errorf("esperava um novo ID, obteve o recebido %q", returned)
+			}
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var output bytes.Buffer
+	log, _ := logger.New(&output, logger.FormatJSON, "info")
+	previous := slog.Default()
+	slog.SetDefault(log)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	router := mux.NewRouter()
+	router.Use(RequestID, AccessLog)
+	router.HandleFunc("/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Autor não encontrado", http.StatusNotFound)
+	}).Methods("GET")
+
+	request := httptest.NewRequest("GET", "/authors/9", nil)
+	request.Header.Set(RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	var record map[string]any
+	if err := json.Unmarshal(output.Bytes(), &record); err != nil {
+		t.Fatalf("esperava um registro JSON, obteve %s", output.String())
+	}
+	expected := map[string]any{"request_id": "req-1", "route": "/authors/{id}", "path": "/authors/9", "status": float64(404), "method": "GET"}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("%s: Expected: %v, Got: %v", key, value, record[key])
+		}
+	}
+	if _, ok := record["latency"]; !ok {
+		t.Error("esperava a latência no registro")
+	}
+}
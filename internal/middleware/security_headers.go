@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SecurityHeadersOptions configura os cabeçalhos de segurança enviados em todas as respostas.
+type SecurityHeadersOptions struct {
+	// HSTSMaxAge é a duração do Strict-Transport-Security. Zero desativa o cabeçalho.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	FrameOptions          string
+	ReferrerPolicy        string
+}
+
+// SecurityHeaders define cabeçalhos de segurança padrão, sem sobrescrever os já definidos pelo handler.
+func SecurityHeaders(options SecurityHeadersOptions) Middleware {
+	headers := map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"X-Frame-Options":        options.FrameOptions,
+		"Referrer-Policy":        options.ReferrerPolicy,
+	}
+	if options.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.Itoa(int(options.HSTSMaxAge.Seconds()))
+		if options.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		headers["Strict-Transport-Security"] = hsts
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range headers {
+				if value != "" && w.Header().Get(name) == "" {
+					w.Header().Set(name, value)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
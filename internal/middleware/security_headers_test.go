@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	handler := SecurityHeaders(SecurityHeadersOptions{
+		HSTSMaxAge:     24 * time.Hour,
+		FrameOptions:   "DENY",
+		ReferrerPolicy: "strict-origin-when-cross-origin",
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+	expected := map[string]string{
+		"Strict-Transport-Security": "max-age=86400",
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+		"X-Frame-Options":           "SAMEORIGIN",
+	}
+	for name, value := range expected {
+		if got := rr.Header().Get(name); got != value {
+			t.Errorf("%s: Expected: %q, Got: %q", name, value, got)
+		}
+	}
+}
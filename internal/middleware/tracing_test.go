@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(t.Context()) })
+
+	var handlerSpan trace.SpanContext
+	router := mux.NewRouter()
+	router.Use(Tracing)
+	router.HandleFunc("/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	}).Methods("GET")
+
+	request := httptest.NewRequest("GET", "/authors/7", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("esperava 1 span, obteve %d", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name != "GET /authors/{id}" {
+		t.Errorf("Expected: GET /authors/{id}, Got: %s", span.Name)
+	}
+	if span.SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("esperava continuar o trace recebido, obteve %s", span.SpanContext.TraceID())
+	}
+	if span.Parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("esperava o span remoto como pai, obteve %s", span.Parent.SpanID())
+	}
+	if handlerSpan.SpanID() != span.SpanContext.SpanID() {
+		t.Error("esperava o span da requisição no contexto do handler")
+	}
+
+	attributes := map[string]string{}
+	for _, attribute := range span.Attributes {
+		attributes[string(attribute.Key)] = attribute.Value.Emit()
+	}
+	if attributes["http.route"] != "/authors/{id}" || attributes["http.response.status_code"] != "500" {
+		t.Errorf("atributos inesperados: %v", attributes)
+	}
+	if span.Status.Code.String() != "Error" {
+		t.Errorf("Expected: Error, Got: %s", span.Status.Code)
+	}
+}
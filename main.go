@@ -1,19 +1,34 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
+	"log/slog"
 	"lucienne/config"
+	"lucienne/internal/auth"
 	"lucienne/internal/handlers"
 	"lucienne/internal/infra/database"
+	"lucienne/internal/infra/metrics"
 	"lucienne/internal/infra/repository"
+	"lucienne/internal/infra/telemetry"
+	"lucienne/internal/middleware"
+	"lucienne/pkg/health"
+	"lucienne/pkg/logger"
+	"lucienne/pkg/mailer"
+	"lucienne/pkg/ratelimit"
 	"lucienne/pkg/renderer"
+	"lucienne/pkg/server"
+	"lucienne/pkg/worker"
 	"net/http"
-	"path"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/gorilla/mux"
 )
 
@@ -23,15 +38,135 @@ const (
 	AssetsBuildFilePath = "public/build.json"
 	ViewsPath           = "internal/views"
 	AssetsServerPath    = "/assets"
-	MigrationsPath      = "file://db/migrations"
-	SeedsPath           = "file://db/seeds"
+	MigrationsPath      = "db/migrations"
+	SeedsPath           = "db/seeds"
 )
 
+const usage = `Uso: lucienne [comando]
+
+Comandos:
+  serve            inicia o servidor HTTP (padrão)
+  seed [set]       aplica um conjunto de seeds (padrão: o conjunto do ambiente atual)
+  seed --list      lista os conjuntos de seeds disponíveis
+  config check     valida a configuração e termina com erro se algum valor for inválido
+  config print     lista as variáveis de configuração, sua origem e valor (segredos ocultos)
+  config example   gera o conteúdo do arquivo .env.example`
+
 func main() {
+	command := "serve"
+	args := os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		loadConfig()
+		serve()
+	case "seed":
+		loadConfig()
+		seed(args)
+	case "config":
+		configCommand(args)
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+	default:
+		log.Fatalf("Comando desconhecido: %s\n%s", command, usage)
+	}
+}
+
+// loadConfig carrega as variáveis de ambiente e configura a aplicação, encerrando o processo se forem inválidas.
+func loadConfig() {
+	if err := config.EnvVariables.Load(); err != nil {
+		log.Fatal(err)
+	}
+	if err := logger.Setup(os.Stderr, config.EnvVariables.Log.Format, config.EnvVariables.Log.Level); err != nil {
+		log.Fatal(err)
+	}
+	config.Application.Configure(config.EnvVariables.AppEnv)
+	if files, ok := embeddedFS(); ok {
+		config.Application.UseFS(files)
+	}
+}
+
+// serve prepara o banco de dados e inicia o servidor HTTP.
+func serve() {
+	shutdownTracing, err := telemetry.Setup(context.Background(), telemetry.Options{
+		Exporter:    config.EnvVariables.Tracing.Exporter,
+		Endpoint:    config.EnvVariables.Tracing.Endpoint,
+		ServiceName: config.EnvVariables.Tracing.ServiceName,
+		Environment: config.Application.Environment,
+		SampleRatio: config.EnvVariables.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("Erro ao configurar o rastreamento: %v", err)
+	}
+
+	setupDatabase()
+
+	if set, ok := database.SeedSetFor(config.Application.Environment); ok && config.Application.IsDevelopment() {
+		slog.Info("Ambiente de desenvolvimento detectado. Aplicando seed", "set", set)
+		applySeedSet(set)
+	}
+
+	config.Assets.Configure(AssetsPath, CompiledAssetsPath, AssetsBuildFilePath)
+	renderer.HTML.Configure(AssetsServerPath, subFS(ViewsPath), config.Assets.AssetsMapping)
+	renderer.HTML.AddContextFunc("cspNonce", func(ctx context.Context, _ ...string) any { return middleware.CSPNonce(ctx) })
+	renderer.HTML.AddContextFunc("csrfField", func(ctx context.Context, _ ...string) any { return middleware.CSRFField(ctx) })
+	renderer.HTML.AddContextFunc("can", func(ctx context.Context, args ...string) any { return len(args) == 1 && auth.Can(ctx, args[0]) })
+
+	workers := worker.NewGroup()
 	r := mux.NewRouter()
+	// Recovery fica por último para que o log de acesso, as métricas e o trace registrem o 500 de um pânico.
+	// Os 404 e 405 não passam pelo r.Use, então recebem a mesma cadeia, rotulados como "unmatched".
+	observe := middleware.Chain(middleware.Tracing, middleware.AccessLog, middleware.Metrics, middleware.Recovery)
+	r.Use(observe)
+	middleware.Unmatched(r, observe)
+
+	rateLimits := config.EnvVariables.RateLimit
+	writeLimit := ratelimit.Limit{Requests: rateLimits.WriteRequests, Period: rateLimits.WritePeriod, Burst: rateLimits.WriteBurst}
+	apiLimit := ratelimit.Limit{Requests: rateLimits.APIRequests, Period: rateLimits.APIPeriod, Burst: rateLimits.APIBurst}
+	apiIPLimit := ratelimit.Limit{Requests: rateLimits.APIIPRequests, Period: rateLimits.APIIPPeriod, Burst: rateLimits.APIIPBurst}
+	rateLimitStore, cleanupRateLimits := newRateLimitStore(rateLimits.Store, max(writeLimit.RefillTime(), apiLimit.RefillTime(), apiIPLimit.RefillTime()))
+	workers.Go("rate-limit-cleanup", func(ctx context.Context) error {
+		return cleanupRateLimits(ctx, rateLimits.CleanupInterval)
+	})
+	r.Use(middleware.RateLimit(middleware.RateLimitOptions{
+		Group:      "write",
+		Store:      rateLimitStore,
+		Limit:      writeLimit,
+		Methods:    []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+		TrustProxy: rateLimits.TrustProxy,
+	}))
+	r.Use(middleware.CSRF(middleware.CSRFOptions{
+		Secure: config.EnvVariables.Security.CookieSecure,
+		Exempt: func(r *http.Request) bool {
+			// Os navegadores enviam os relatórios de CSP sem token, e as chamadas à API com token de acesso
+			// não dependem de cookies, então não podem ser forjadas por outro site.
+			if r.URL.Path == config.EnvVariables.CSP.ReportURI {
+				return true
+			}
+			return strings.HasPrefix(r.URL.Path, "/api/") && strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ")
+		},
+	}))
+
+	userRepo := repository.NewPostgresUserRepository()
+	sessionRepo := repository.NewPostgresSessionRepository()
+	sessionConfig := config.EnvVariables.Session
+	sessions := auth.NewSessionManager(sessionRepo, userRepo, auth.SessionOptions{
+		IdleTimeout: sessionConfig.IdleTimeout,
+		Lifetime:    sessionConfig.Lifetime,
+		Secure:      config.EnvVariables.Security.CookieSecure,
+	})
+	workers.Go("session-cleanup", func(ctx context.Context) error {
+		return sessionRepo.Run(ctx, sessionConfig.CleanupInterval, sessionConfig.IdleTimeout)
+	})
+	// Carrega o usuário da sessão, usado por middleware.RequireUser nas rotas que exigem login.
+	r.Use(sessions.Middleware)
+	metrics.RegisterPool(database.Conn)
 
 	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		page, err := renderer.HTML.Render("home.html", nil)
+		page, err := renderer.HTML.Render(r.Context(), "home.html", nil)
 		if err != nil {
 			w.WriteHeader(500)
 			w.Write([]byte("Ocorreu um erro ao renderizar a página"))
@@ -39,107 +174,250 @@ func main() {
 		}
 		w.Write(page)
 	}).Methods("GET")
-	r.PathPrefix(AssetsServerPath).Handler(http.StripPrefix(AssetsServerPath, http.FileServer(http.Dir(CompiledAssetsPath))))
+	r.PathPrefix(AssetsServerPath).Handler(http.StripPrefix(AssetsServerPath, http.FileServer(http.FS(subFS(CompiledAssetsPath)))))
 
 	// Injeção de Dependência
 	authorRepo := repository.NewPostgresAuthorRepository()
 	authorHandler := handlers.NewAuthorHandler(authorRepo)
+	authorHandler.EnableHistory(repository.NewPostgresHistoryRepository())
 	publisherRepo := repository.NewPostgresPublisherRepository()
 	publisherHandler := handlers.NewPublisherHandler(publisherRepo)
+	userTokenRepo := repository.NewPostgresUserTokenRepository()
+	accountConfig := config.EnvVariables.Account
+	mail := newMailer()
+	accounts := auth.NewAccountTokens(userRepo, userTokenRepo, mail, auth.AccountTokenOptions{
+		BaseURL:              config.EnvVariables.AppURL,
+		PasswordResetTTL:     accountConfig.PasswordResetTTL,
+		EmailVerificationTTL: accountConfig.EmailVerificationTTL,
+	})
+	workers.Go("user-token-cleanup", func(ctx context.Context) error {
+		return userTokenRepo.Run(ctx, accountConfig.TokenCleanupInterval)
+	})
+	workers.Go("password-reset-mail", accounts.Run)
+	userHandler := handlers.NewUserHandler(userRepo, accounts)
+	passwordResetHandler := handlers.NewPasswordResetHandler(accounts)
+	twoFactorConfig := config.EnvVariables.TwoFactor
+	twoFactor := auth.NewTwoFactor(repository.NewPostgresTwoFactorRepository(), userTokenRepo, userRepo, auth.TwoFactorOptions{
+		Issuer:        twoFactorConfig.Issuer,
+		RequiredRoles: twoFactorConfig.RequiredRoles,
+		LoginTTL:      twoFactorConfig.LoginTTL,
+		Secure:        config.EnvVariables.Security.CookieSecure,
+	})
+	// Retira as permissões de quem ainda precisa ativar a verificação em duas etapas. Vem depois do
+	// middleware de sessão, que carrega o usuário.
+	r.Use(twoFactor.Middleware)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactor)
+	lockoutConfig := config.EnvVariables.Lockout
+	loginThrottleRepo := repository.NewPostgresLoginThrottleRepository()
+	loginGuard := auth.NewLoginGuard(loginThrottleRepo, userRepo, mail, auth.LoginGuardOptions{
+		DelayAfter:    lockoutConfig.DelayAfter,
+		BaseDelay:     lockoutConfig.BaseDelay,
+		MaxFailures:   lockoutConfig.MaxFailures,
+		IPMaxFailures: lockoutConfig.IPMaxFailures,
+		LockDuration:  lockoutConfig.Duration,
+		FailureWindow: lockoutConfig.FailureWindow,
+		BaseURL:       config.EnvVariables.AppURL,
+	})
+	workers.Go("login-throttle-cleanup", func(ctx context.Context) error {
+		return loginThrottleRepo.Run(ctx, lockoutConfig.CleanupInterval, lockoutConfig.FailureWindow)
+	})
+	sessionHandler := handlers.NewSessionHandler(userRepo, sessions)
+	sessionHandler.EnableTwoFactor(twoFactor)
+	sessionHandler.EnableLoginGuard(loginGuard, rateLimits.TrustProxy)
+	roleRepo := repository.NewPostgresRoleRepository()
+	if config.EnvVariables.OIDC.Issuer != "" {
+		sessionHandler.EnableSingleSignOn(newOIDCProvider(userRepo, roleRepo))
+	}
+	adminUserHandler := handlers.NewAdminUserHandler(userRepo, roleRepo, twoFactor)
+	adminLockoutHandler := handlers.NewAdminLockoutHandler(loginGuard)
+	apiTokenRepo := repository.NewPostgresAPITokenRepository()
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenRepo)
+	apiHandler := handlers.NewAPIHandler(authorRepo, publisherRepo)
+	auditHandler := handlers.NewAuditHandler(repository.NewPostgresAuditEventRepository())
+	trashConfig := config.EnvVariables.Trash
+	trashRepo := repository.NewPostgresTrashRepository()
+	workers.Go("trash-purge", func(ctx context.Context) error {
+		return trashRepo.Run(ctx, trashConfig.PurgeInterval, trashConfig.Retention)
+	})
+	trashHandler := handlers.NewTrashHandler(trashRepo, trashConfig.Retention)
+
+	healthHandler := handlers.NewHealthHandler(healthChecks(workers))
 
-	handlers.ReturnHealth(r)
+	healthHandler.DefineHealth(r)
+	handlers.NewCSPHandler(config.EnvVariables.CSP.ReportURI).DefineCSP(r)
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
 	authorHandler.DefineAuthors(r)
 	publisherHandler.DefinePublishers(r)
+	userHandler.DefineUsers(r)
+	passwordResetHandler.DefinePasswordReset(r)
+	sessionHandler.DefineSessions(r)
+	twoFactorHandler.DefineTwoFactor(r)
+	adminUserHandler.DefineAdminUsers(r)
+	adminLockoutHandler.DefineAdminLockouts(r)
+	auditHandler.DefineAudit(r)
+	trashHandler.DefineTrash(r)
+	apiTokenHandler.DefineAPITokens(r)
 
-	log.Println("Rodando na porta: " + config.EnvVariables.AppPort)
-	log.Fatal(http.ListenAndServe(":"+config.EnvVariables.AppPort, r))
-}
+	api := r.PathPrefix("/api").Subrouter()
+	api.Use(middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins:   config.EnvVariables.CORS.AllowedOrigins,
+		AllowedMethods:   config.EnvVariables.CORS.AllowedMethods,
+		AllowedHeaders:   config.EnvVariables.CORS.AllowedHeaders,
+		AllowCredentials: config.EnvVariables.CORS.AllowCredentials,
+		MaxAge:           config.EnvVariables.CORS.MaxAge,
+	}), middleware.RateLimit(middleware.RateLimitOptions{
+		// Antes do autenticador, por IP, para que os tokens inválidos também sejam contados.
+		Group:      "api-ip",
+		Store:      rateLimitStore,
+		Limit:      apiIPLimit,
+		TrustProxy: rateLimits.TrustProxy,
+	}), auth.NewAPITokenAuthenticator(apiTokenRepo, userRepo).Middleware, middleware.RateLimit(middleware.RateLimitOptions{
+		// Depois do autenticador, para contar por token válido e não pelo valor enviado em Authorization.
+		Group:      "api",
+		Store:      rateLimitStore,
+		Limit:      apiLimit,
+		TrustProxy: rateLimits.TrustProxy,
+	}), twoFactor.Middleware)
+	apiHandler.DefineAPI(api)
+	auditHandler.DefineAuditAPI(api)
+	// O preflight precisa encontrar uma rota para que o middleware de CORS seja executado.
+	api.Methods(http.MethodOptions).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
 
-func init() {
-	config.EnvVariables.Load()
-	config.Application.Configure(config.EnvVariables.AppEnv)
-	config.Assets.Configure(AssetsPath, CompiledAssetsPath, AssetsBuildFilePath)
-	renderer.HTML.Configure(AssetsServerPath, path.Join(config.Application.RootPath, ViewsPath), config.Assets.AssetsMapping)
-	database.ConnectDB()
+	// Middlewares que valem também para as requisições que não encontram rota.
+	handler := middleware.Chain(
+		middleware.RequestID,
+		middleware.SecurityHeaders(middleware.SecurityHeadersOptions{
+			HSTSMaxAge:            config.EnvVariables.Security.HSTSMaxAge,
+			HSTSIncludeSubdomains: config.EnvVariables.Security.HSTSIncludeSubdomains,
+			FrameOptions:          config.EnvVariables.Security.FrameOptions,
+			ReferrerPolicy:        config.EnvVariables.Security.ReferrerPolicy,
+		}),
+		middleware.CSP(middleware.CSPOptions{
+			ReportOnly: config.EnvVariables.CSP.ReportOnly,
+			ReportURI:  config.EnvVariables.CSP.ReportURI,
+		}),
+		middleware.Compress,
+		middleware.MethodOverride,
+	)(r)
 
-	m, err := migrate.New(
-		MigrationsPath,
-		config.EnvVariables.DatabaseURL,
-	)
-	if err != nil {
-		log.Fatal(err)
+	srv := server.New(handler, server.Options{
+		Addr:              ":" + config.EnvVariables.AppPort,
+		ReadTimeout:       config.EnvVariables.HTTP.ReadTimeout,
+		ReadHeaderTimeout: config.EnvVariables.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      config.EnvVariables.HTTP.WriteTimeout,
+		IdleTimeout:       config.EnvVariables.HTTP.IdleTimeout,
+		MaxHeaderBytes:    config.EnvVariables.HTTP.MaxHeaderBytes,
+		ShutdownTimeout:   config.EnvVariables.HTTP.ShutdownTimeout,
+	})
+	// A ordem importa: os workers ainda podem usar o banco enquanto terminam.
+	srv.OnShutdown("workers", workers.Stop)
+	srv.OnShutdown("database", database.Close)
+	srv.OnShutdown("tracing", shutdownTracing)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("Servidor iniciado", "port", config.EnvVariables.AppPort, "environment", config.Application.Environment)
+	if err := srv.Run(ctx); err != nil {
+		log.Fatalf("Erro ao encerrar o servidor: %v", err)
 	}
+	slog.Info("Servidor encerrado")
+}
 
-	log.Println("Iniciando migrações...")
-	if err := m.Up(); err != nil {
-		if err == migrate.ErrNoChange {
-			log.Println("Nenhuma migração pendente. Banco de dados já está atualizado.")
-		} else {
-			log.Fatalf("Erro ao aplicar migrações: %v", err)
+// newRateLimitStore cria o store de rate limit configurado e a rotina que descarta os baldes parados.
+func newRateLimitStore(kind string, idle time.Duration) (ratelimit.Store, func(ctx context.Context, interval time.Duration) error) {
+	if kind == "postgres" {
+		store := repository.NewPostgresRateLimitStore()
+		return store, func(ctx context.Context, interval time.Duration) error {
+			return store.Run(ctx, interval, idle)
 		}
-	} else {
-		log.Println("Migrações aplicadas com sucesso.")
 	}
+	store := ratelimit.NewMemoryStore()
+	return store, store.Run
+}
 
-	// Log do estado atual das migrações
-	version, dirty, err := m.Version()
-	if err != nil {
-		log.Fatalf("Erro ao obter versão das migrações: %v", err)
+// newMailer cria o mailer configurado. Cada envio tem o prazo de MAIL_TIMEOUT.
+func newMailer() mailer.Mailer {
+	mail := config.EnvVariables.Mail
+	var m mailer.Mailer
+	switch mail.Driver {
+	case "smtp":
+		m = mailer.NewSMTP(mailer.SMTPOptions{
+			Host:     mail.SMTPHost,
+			Port:     mail.SMTPPort,
+			Username: mail.SMTPUsername,
+			Password: mail.SMTPPassword,
+			From:     mail.From,
+		})
+	case "file":
+		m = mailer.NewFile(mail.Dir, mail.From)
+	default:
+		m = mailer.NewLog(nil)
 	}
-	log.Printf("Versão atual do banco de dados: %d, Dirty: %v", version, dirty)
+	return mailer.WithTimeout(m, mail.Timeout)
+}
 
-	// Fechar a instância de migração para liberar a conexão com o banco de dados.
-	sourceErr, dbErr := m.Close()
-	if sourceErr != nil {
-		log.Fatalf("Erro ao fechar o source da migração: %v", sourceErr)
+// newOIDCProvider consulta o provedor de identidade configurado, encerrando o processo se ele não responder.
+func newOIDCProvider(users repository.UserRepository, roles repository.RoleRepository) *auth.OIDCProvider {
+	oidcConfig := config.EnvVariables.OIDC
+	roleMapping, err := auth.ParseRoleMapping(oidcConfig.RoleMapping)
+	if err != nil {
+		log.Fatal(err)
 	}
-	if dbErr != nil {
-		log.Fatalf("Erro ao fechar a conexão do banco de dados da migração: %v", dbErr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	provider, err := auth.NewOIDCProvider(ctx, auth.OIDCOptions{
+		Name:         oidcConfig.ProviderName,
+		Issuer:       oidcConfig.Issuer,
+		ClientID:     oidcConfig.ClientID,
+		ClientSecret: oidcConfig.ClientSecret,
+		RedirectURL:  oidcConfig.RedirectURL,
+		Scopes:       oidcConfig.Scopes,
+		RolesClaim:   oidcConfig.RolesClaim,
+		RoleMapping:  roleMapping,
+		Secure:       config.EnvVariables.Security.CookieSecure,
+	}, users, repository.NewPostgresIdentityRepository(), roles)
+	if err != nil {
+		log.Fatalf("Erro ao configurar o login único: %v", err)
 	}
+	return provider
+}
 
-	if config.Application.IsDevelopment() {
-		log.Println("Ambiente de desenvolvimento detectado. Aplicando seed...")
+// healthChecks registra as dependências verificadas pela rota de readiness.
+func healthChecks(workers *worker.Group) *health.Registry {
+	timeout := config.EnvVariables.HealthCheckTimeout
+	registry := health.NewRegistry()
 
-		db, err := sql.Open("postgres", config.EnvVariables.DatabaseURL)
-		if err != nil {
-			log.Fatal(err)
+	registry.Register(health.Check{Name: "database", Critical: true, Timeout: timeout, Run: database.Ping})
+	registry.Register(health.Check{Name: "migrations", Critical: true, Timeout: timeout, Run: database.CheckMigrations(subFS(MigrationsPath))})
+	registry.Register(health.Check{Name: "assets", Critical: true, Timeout: timeout, Run: func(ctx context.Context) error {
+		if len(config.Assets.AssetsMapping) == 0 {
+			return errors.New("manifesto de assets não carregado")
 		}
-		defer db.Close()
+		return nil
+	}})
+	registry.Register(health.Check{Name: "workers", Critical: false, Timeout: timeout, Run: workers.Check})
 
-		driver, err := postgres.WithInstance(db, &postgres.Config{
-			MigrationsTable: "schema_seeders",
-		})
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		seed, err := migrate.NewWithDatabaseInstance(
-			SeedsPath,
-			"postgres",
-			driver,
-		)
-		if err != nil {
-			log.Fatal(err)
-		}
+	return registry
+}
 
-		log.Println("Iniciando seed...")
-		if err := seed.Up(); err != nil {
-			if err == migrate.ErrNoChange {
-				log.Println("Nenhum seed pendente. Banco de dados já está atualizado.")
-			} else {
-				log.Fatalf("Erro ao aplicar seeds: %v", err)
-			}
-		} else {
-			log.Println("Seed aplicadas com sucesso.")
-		}
+// setupDatabase conecta ao banco de dados e aplica as migrações pendentes.
+func setupDatabase() {
+	database.ConnectDB()
 
-		sourceErr, dbErr := seed.Close()
-		if sourceErr != nil {
-			log.Fatalf("Erro ao fechar o source do seed: %v", sourceErr)
-		}
-		if dbErr != nil {
-			log.Fatalf("Erro ao fechar a conexão do banco de dados do seed: %v", dbErr)
-		}
+	if err := database.Migrate(subFS(MigrationsPath), config.EnvVariables.DatabaseURL); err != nil {
+		log.Fatal(err)
 	}
+}
 
+// subFS retorna o subdiretório dir dos arquivos da aplicação, estejam eles em disco ou embutidos no binário.
+func subFS(dir string) fs.FS {
+	files, err := fs.Sub(config.Application.FS, dir)
+	if err != nil {
+		log.Fatalf("Erro ao acessar o diretório %s: %v", dir, err)
+	}
+	return files
 }
@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status values reported by checks and reports.
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDegraded = "degraded"
+)
+
+const defaultTimeout = 2 * time.Second
+
+// Check is a named dependency verification. A failing critical check makes the application not ready.
+type Check struct {
+	Name     string
+	Critical bool
+	// Timeout bounds each run of the check. Defaults to 2 seconds.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Result is the outcome of a single check run.
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report aggregates the results of every registered check.
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Healthy reports whether every critical check passed.
+func (report Report) Healthy() bool {
+	return report.Status != StatusFail
+}
+
+// Registry holds the checks used to decide whether the application is ready to receive traffic.
+type Registry struct {
+	mu     sync.RWMutex
+	checks []Check
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a check to the registry.
+func (registry *Registry) Register(check Check) {
+	if check.Timeout == 0 {
+		check.Timeout = defaultTimeout
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.checks = append(registry.checks, check)
+}
+
+// Run executes every check concurrently and returns their results in registration order.
+func (registry *Registry) Run(ctx context.Context) Report {
+	registry.mu.RLock()
+	checks := append([]Check(nil), registry.checks...)
+	registry.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status == StatusOK {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusFail
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	result := Result{Name: check.Name, Critical: check.Critical, Status: StatusOK}
+	start := time.Now()
+
+	errs := make(chan error, 1)
+	go func() { errs <- check.Run(ctx) }()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
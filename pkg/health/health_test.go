@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryRun(t *testing.T) {
+	passing := Check{Name: "passing", Critical: true, Run: func(ctx context.Context) error { return nil }}
+	failing := func(critical bool) Check {
+		return Check{Name: "failing", Critical: critical, Run: func(ctx context.Context) error { return errors.New("boom") }}
+	}
+
+	testCases := []struct {
+		name           string
+		checks         []Check
+		expectedStatus string
+		healthy        bool
+	}{
+		{name: "ok when every check passes", checks: []Check{passing}, expectedStatus: StatusOK, healthy: true},
+		{name: "degraded when a non critical check fails", checks: []Check{passing, failing(false)}, expectedStatus: StatusDegraded, healthy: true},
+		{name: "fail when a critical check fails", checks: []Check{passing, failing(false), failing(true)}, expectedStatus: StatusFail, healthy: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := NewRegistry()
+			for _, check := range tc.checks {
+				registry.Register(check)
+			}
+
+			report := registry.Run(context.Background())
+			if report.Status != tc.expectedStatus {
+				t.Errorf("Expected: %s, Got: %s", tc.expectedStatus, report.Status)
+			}
+			if report.Healthy() != tc.healthy {
+				t.Errorf("Expected healthy: %v, Got: %v", tc.healthy, report.Healthy())
+			}
+			if len(report.Checks) != len(tc.checks) {
+				t.Errorf("Expected: %d results, Got: %d", len(tc.checks), len(report.Checks))
+			}
+		})
+	}
+
+	t.Run("fails checks that exceed their timeout", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.Register(Check{Name: "slow", Critical: true, Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}})
+
+		report := registry.Run(context.Background())
+		result := report.Checks[0]
+		if result.Status != StatusFail || result.Error != context.DeadlineExceeded.Error() {
+			t.Errorf("Expected: failed with deadline exceeded, Got: %+v", result)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("adds the request ID from the context", func(t *testing.T) {
+		var output bytes.Buffer
+		logger, err := New(&output, FormatJSON, "info")
+		if err != nil {
+			t.Fatalf("Expected: no error, Got: %s", err)
+		}
+
+		ctx := WithRequestID(context.Background(), "abc123")
+		logger.With("component", "test").InfoContext(ctx, "hello")
+
+		var record map[string]any
+		if err := json.Unmarshal(output.Bytes(), &record); err != nil {
+			t.Fatalf("Expected: a JSON record, Got: %s", output.String())
+		}
+		if record["request_id"] != "abc123" || record["component"] != "test" {
+			t.Errorf("Unexpected record: %v", record)
+		}
+	})
+
+	t.Run("filters records below the level", func(t *testing.T) {
+		var output bytes.Buffer
+		logger, _ := New(&output, FormatText, "warn")
+
+		logger.Info("ignored")
+		logger.Warn("kept")
+
+		if strings.Contains(output.String(), "ignored") || !strings.Contains(output.String(), "kept") {
+			t.Errorf("Unexpected output: %s", output.String())
+		}
+	})
+
+	t.Run("rejects unknown formats and levels", func(t *testing.T) {
+		if _, err := New(&bytes.Buffer{}, "xml", "info"); err == nil {
+			t.Error("Expected: an error for the format, Got: nothing")
+		}
+		if _, err := New(&bytes.Buffer{}, FormatJSON, "verbose"); err == nil {
+			t.Error("Expected: an error for the level, Got: nothing")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// File writes each message as an .eml file in a directory, to be opened by an email client during development.
+type File struct {
+	dir  string
+	from string
+	now  func() time.Time
+}
+
+// NewFile returns a File mailer. The directory is created on the first message.
+func NewFile(dir string, from string) *File {
+	return &File{dir: dir, from: from, now: time.Now}
+}
+
+// Send writes the message to <dir>/<time>-<recipient>.eml.
+func (f *File) Send(ctx context.Context, msg Message) error {
+	now := f.now()
+	data, err := Format(f.from, msg, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return err
+	}
+
+	recipient := strings.NewReplacer("/", "_", "\\", "_", " ", "_", "<", "", ">", "").Replace(msg.To)
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), recipient)
+	path := filepath.Join(f.dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "email written to file", "to", msg.To, "subject", msg.Subject, "path", path)
+	return nil
+}
+
+// Log writes the messages to the application log instead of delivering them.
+type Log struct {
+	logger *slog.Logger
+}
+
+// NewLog returns a Log mailer. A nil logger uses slog.Default.
+func NewLog(logger *slog.Logger) *Log {
+	return &Log{logger: logger}
+}
+
+// Send logs the recipient, the subject and the body.
+func (l *Log) Send(ctx context.Context, msg Message) error {
+	logger := l.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.InfoContext(ctx, "email not delivered: log mailer", "to", msg.To, "subject", msg.Subject, "text", msg.Text)
+	return nil
+}
@@ -0,0 +1,86 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Mailer delivers messages. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// ErrInvalidAddress is returned when the sender or the recipient is not a valid address.
+var ErrInvalidAddress = errors.New("mailer: invalid address")
+
+// Format encodes the message as RFC 5322, with a UTF-8 quoted-printable body.
+func Format(from string, msg Message, now time.Time) ([]byte, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, from)
+	}
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, msg.To)
+	}
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", sender.String())
+	header("To", recipient.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(sender.Address))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	body.Write([]byte(strings.ReplaceAll(msg.Text, "\n", "\r\n")))
+	body.Close()
+	return buf.Bytes(), nil
+}
+
+// messageID builds a unique Message-ID on the sender domain.
+func messageID(sender string) string {
+	domain := "localhost"
+	if _, d, ok := strings.Cut(sender, "@"); ok {
+		domain = d
+	}
+	return fmt.Sprintf("<%s@%s>", strings.ToLower(rand.Text()), domain)
+}
+
+// timeoutMailer bounds each delivery of the wrapped mailer.
+type timeoutMailer struct {
+	mailer  Mailer
+	timeout time.Duration
+}
+
+// WithTimeout returns a Mailer that gives up on each message after timeout, even if the caller's context has no deadline.
+func WithTimeout(m Mailer, timeout time.Duration) Mailer {
+	return &timeoutMailer{mailer: m, timeout: timeout}
+}
+
+func (t *timeoutMailer) Send(ctx context.Context, msg Message) error {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	return t.mailer.Send(ctx, msg)
+}
@@ -0,0 +1,178 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testMessage = Message{
+	To:      "Ana <ana@example.com>",
+	Subject: "Redefinição de senha",
+	Text:    "Olá, Ana!\nUse o link abaixo.",
+}
+
+// readMessage parses an encoded message and decodes its body.
+func readMessage(t *testing.T, data []byte) (*mail.Message, string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected: a valid message, Got: %s", err)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatalf("Expected: a quoted-printable body, Got: %s", err)
+	}
+	return msg, string(body)
+}
+
+func TestFormat(t *testing.T) {
+	t.Run("encodes the headers and the body", func(t *testing.T) {
+		data, err := Format("lucienne <no-reply@lucienne.local>", testMessage, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("Expected: no error, Got: %s", err)
+		}
+
+		msg, body := readMessage(t, data)
+		subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+		if subject != testMessage.Subject {
+			t.Errorf("Expected: %q, Got: %q", testMessage.Subject, subject)
+		}
+		if to := msg.Header.Get("To"); to != `"Ana" <ana@example.com>` {
+			t.Errorf("Expected: %q, Got: %q", `"Ana" <ana@example.com>`, to)
+		}
+		if date := msg.Header.Get("Date"); date != "Fri, 02 Jan 2026 03:04:05 +0000" {
+			t.Errorf("Expected: the message date, Got: %q", date)
+		}
+		if id := msg.Header.Get("Message-ID"); !strings.HasSuffix(id, "@lucienne.local>") {
+			t.Errorf("Expected: a Message-ID on the sender domain, Got: %q", id)
+		}
+		if body != "Olá, Ana!\r\nUse o link abaixo." {
+			t.Errorf("Expected: the body with CRLF line endings, Got: %q", body)
+		}
+	})
+
+	t.Run("rejects invalid addresses", func(t *testing.T) {
+		if _, err := Format("lucienne", testMessage, time.Now()); !errors.Is(err, ErrInvalidAddress) {
+			t.Errorf("Expected: %s, Got: %v", ErrInvalidAddress, err)
+		}
+		if _, err := Format("no-reply@lucienne.local", Message{To: "ana"}, time.Now()); !errors.Is(err, ErrInvalidAddress) {
+			t.Errorf("Expected: %s, Got: %v", ErrInvalidAddress, err)
+		}
+	})
+}
+
+func TestFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	if err := NewFile(dir, "no-reply@lucienne.local").Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Expected: no error, Got: %s", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 || !strings.HasSuffix(files[0], "-Ana_ana@example.com.eml") {
+		t.Fatalf("Expected: one .eml file, Got: %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if _, body := readMessage(t, data); !strings.Contains(body, "Use o link abaixo.") {
+		t.Errorf("Expected: the message body, Got: %q", body)
+	}
+}
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewLog(slog.New(slog.NewTextHandler(&buf, nil)))
+	if err := mailer.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Expected: no error, Got: %s", err)
+	}
+	if !strings.Contains(buf.String(), "to=\"Ana <ana@example.com>\"") || !strings.Contains(buf.String(), "Use o link abaixo.") {
+		t.Errorf("Expected: the recipient and the body in the log, Got: %q", buf.String())
+	}
+}
+
+// fakeSMTPServer accepts one message without TLS or authentication and returns what it received.
+func fakeSMTPServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var transcript strings.Builder
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 fake ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250 fake")
+			case strings.HasPrefix(command, "MAIL FROM"), strings.HasPrefix(command, "RCPT TO"):
+				transcript.WriteString(strings.TrimSpace(line) + "\n")
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					transcript.WriteString(line)
+				}
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				received <- transcript.String()
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSMTP(t *testing.T) {
+	addr, received := fakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(addr)
+	portNumber, _ := strconv.Atoi(port)
+
+	mailer := NewSMTP(SMTPOptions{Host: host, Port: portNumber, From: "lucienne <no-reply@lucienne.local>"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := mailer.Send(ctx, testMessage); err != nil {
+		t.Fatalf("Expected: no error, Got: %s", err)
+	}
+
+	transcript := <-received
+	for _, expected := range []string{"MAIL FROM:<no-reply@lucienne.local>", "RCPT TO:<ana@example.com>", "Subject: =?utf-8?q?Redefini=C3=A7=C3=A3o_de_senha?="} {
+		if !strings.Contains(transcript, expected) {
+			t.Errorf("Expected: %q in the transcript, Got: %q", expected, transcript)
+		}
+	}
+}
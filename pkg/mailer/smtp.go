@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPOptions configures the SMTP server connection.
+type SMTPOptions struct {
+	Host string
+	Port int
+	// Username and Password enable PLAIN authentication, which is only sent over TLS.
+	Username string
+	Password string
+	From     string
+}
+
+// SMTP delivers the messages to an SMTP server, upgrading the connection with STARTTLS when the server offers it.
+type SMTP struct {
+	options SMTPOptions
+	now     func() time.Time
+}
+
+// NewSMTP returns an SMTP mailer. Each message opens its own connection.
+func NewSMTP(options SMTPOptions) *SMTP {
+	return &SMTP{options: options, now: time.Now}
+}
+
+// Send delivers the message, giving up when ctx is done.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	data, err := Format(s.options.From, msg, s.now())
+	if err != nil {
+		return err
+	}
+	sender, _ := mail.ParseAddress(s.options.From)
+	recipient, _ := mail.ParseAddress(msg.To)
+
+	addr := net.JoinHostPort(s.options.Host, strconv.Itoa(s.options.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("mailer: could not connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Closing the connection unblocks the client when ctx is canceled without a deadline.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, s.options.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.options.Host}); err != nil {
+			return err
+		}
+	}
+	if s.options.Username != "" {
+		// smtp.PlainAuth refuses to send the password without TLS, except to localhost.
+		if err := client.Auth(smtp.PlainAuth("", s.options.Username, s.options.Password, s.options.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(sender.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,61 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testParams = Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHashAndVerify(t *testing.T) {
+	previous := DefaultParams
+	DefaultParams = testParams
+	t.Cleanup(func() { DefaultParams = previous })
+
+	hash, err := Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Expected: no error, Got: %s", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Expected: PHC encoded argon2id hash, Got: %s", hash)
+	}
+
+	t.Run("matches the right password", func(t *testing.T) {
+		match, needsRehash, err := Verify("correct horse battery staple", hash)
+		if err != nil || !match || needsRehash {
+			t.Errorf("Expected: match without rehash, Got: match=%v needsRehash=%v err=%v", match, needsRehash, err)
+		}
+	})
+
+	t.Run("rejects a wrong password", func(t *testing.T) {
+		if match, _, _ := Verify("Correct horse battery staple", hash); match {
+			t.Error("Expected: no match, Got: match")
+		}
+	})
+
+	t.Run("salts every hash", func(t *testing.T) {
+		other, _ := Hash("correct horse battery staple")
+		if other == hash {
+			t.Error("Expected: different hashes for the same password")
+		}
+	})
+
+	t.Run("asks for a rehash when the parameters change", func(t *testing.T) {
+		DefaultParams.Iterations = 2
+		t.Cleanup(func() { DefaultParams.Iterations = 1 })
+
+		match, needsRehash, _ := Verify("correct horse battery staple", hash)
+		if !match || !needsRehash {
+			t.Errorf("Expected: match with rehash, Got: match=%v needsRehash=%v", match, needsRehash)
+		}
+	})
+
+	t.Run("rejects malformed hashes", func(t *testing.T) {
+		for _, encoded := range []string{"", "plain", "$2a$10$bcrypthash", "$argon2id$v=19$m=x$salt$key"} {
+			if _, _, err := Verify("password", encoded); !errors.Is(err, ErrInvalidHash) {
+				t.Errorf("Expected: ErrInvalidHash for %q, Got: %v", encoded, err)
+			}
+		}
+	})
+}
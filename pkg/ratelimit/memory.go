@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the buckets in the process memory. Limits are not shared across replicas.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	now     func() time.Time
+}
+
+type memoryBucket struct {
+	Bucket
+	limit Limit
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*memoryBucket{}, now: time.Now}
+}
+
+// Take consumes a token from the bucket identified by key.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{Bucket: NewBucket(limit, now)}
+		s.buckets[key] = bucket
+	}
+	bucket.limit = limit
+	return bucket.Take(limit, now), nil
+}
+
+// Cleanup forgets the buckets that are full again, which behave the same as missing ones.
+func (s *MemoryStore) Cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, bucket := range s.buckets {
+		if bucket.Full(bucket.limit, now) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// Run calls Cleanup every interval until ctx is done. It is meant to be started as a worker.
+func (s *MemoryStore) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.Cleanup()
+		}
+	}
+}
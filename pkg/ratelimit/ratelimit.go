@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit describes a token bucket: it refills Requests tokens every Period and holds at most Burst tokens.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// rate returns the refill rate in tokens per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// RefillTime returns how long an empty bucket takes to fill up again. A bucket left alone for longer is full
+// and behaves the same as a missing one, so idle buckets can be discarded after that.
+func (l Limit) RefillTime() time.Duration {
+	return time.Duration(math.Ceil(float64(l.Burst) / l.rate() * float64(time.Second)))
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until the next token is available when the request is not allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. Take must be atomic for a given key.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Bucket is the state of a token bucket, so stores only need to persist Tokens and UpdatedAt.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// NewBucket returns a full bucket.
+func NewBucket(limit Limit, now time.Time) Bucket {
+	return Bucket{Tokens: float64(limit.Burst), UpdatedAt: now}
+}
+
+// Take refills the bucket for the time elapsed since the last update and consumes a token if there is one.
+func (b *Bucket) Take(limit Limit, now time.Time) Result {
+	if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed.Seconds()*limit.rate())
+	}
+	b.UpdatedAt = now
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return Result{Allowed: true, Remaining: int(b.Tokens)}
+	}
+
+	missing := (1 - b.Tokens) / limit.rate()
+	return Result{Allowed: false, RetryAfter: time.Duration(math.Ceil(missing * float64(time.Second)))}
+}
+
+// Full reports whether the bucket has been refilled up to the burst, in which case it can be forgotten.
+func (b Bucket) Full(limit Limit, now time.Time) bool {
+	return b.Tokens+now.Sub(b.UpdatedAt).Seconds()*limit.rate() >= float64(limit.Burst)
+}
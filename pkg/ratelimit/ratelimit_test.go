@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBucketTake(t *testing.T) {
+	limit := Limit{Requests: 1, Period: time.Second, Burst: 2}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	bucket := NewBucket(limit, start)
+
+	for i := range 2 {
+		if result := bucket.Take(limit, start); !result.Allowed {
+			t.Fatalf("Expected: request %d allowed by the burst, Got: %#v", i, result)
+		}
+	}
+
+	result := bucket.Take(limit, start)
+	if result.Allowed || result.RetryAfter != time.Second {
+		t.Errorf("Expected: denied with RetryAfter 1s, Got: %#v", result)
+	}
+
+	result = bucket.Take(limit, start.Add(500*time.Millisecond))
+	if result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Errorf("Expected: denied with RetryAfter 500ms, Got: %#v", result)
+	}
+
+	if result := bucket.Take(limit, start.Add(time.Second)); !result.Allowed {
+		t.Errorf("Expected: allowed after the refill, Got: %#v", result)
+	}
+	if bucket.Full(limit, start.Add(time.Second)) || !bucket.Full(limit, start.Add(3*time.Second)) {
+		t.Error("Expected: bucket full only after refilling the burst")
+	}
+}
+
+func TestLimitRefillTime(t *testing.T) {
+	limit := Limit{Requests: 30, Period: time.Minute, Burst: 10}
+	if got := limit.RefillTime(); got != 20*time.Second {
+		t.Errorf("Expected: 20s, Got: %s", got)
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	bucket := Bucket{Tokens: 0, UpdatedAt: start}
+	if !bucket.Full(limit, start.Add(limit.RefillTime())) {
+		t.Error("Expected: an empty bucket full after RefillTime")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 10, Period: time.Minute, Burst: 1}
+	ctx := context.Background()
+
+	t.Run("keeps one bucket per key", func(t *testing.T) {
+		if result, _ := store.Take(ctx, "ip:1", limit); !result.Allowed {
+			t.Errorf("Expected: first request of ip:1 allowed, Got: %#v", result)
+		}
+		if result, _ := store.Take(ctx, "ip:1", limit); result.Allowed {
+			t.Errorf("Expected: second request of ip:1 denied, Got: %#v", result)
+		}
+		if result, _ := store.Take(ctx, "ip:2", limit); !result.Allowed {
+			t.Errorf("Expected: first request of ip:2 allowed, Got: %#v", result)
+		}
+	})
+
+	t.Run("forgets full buckets on cleanup", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		store.Cleanup()
+		if len(store.buckets) != 0 {
+			t.Errorf("Expected: no buckets, Got: %d", len(store.buckets))
+		}
+	})
+}
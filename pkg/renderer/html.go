@@ -2,14 +2,29 @@ package renderer
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"html/template"
+	"io/fs"
 	"path"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("lucienne/pkg/renderer")
+
+// ContextFunc is a template function whose value depends on the request being rendered, such as a CSP nonce.
+// It receives the arguments given in the template, as in {{ can "authors.manage" }}.
+type ContextFunc func(ctx context.Context, args ...string) any
+
 type templateConfig struct {
 	assetsUrlPath string
-	viewsDir      string
+	views         fs.FS
 	assetsMapping map[string]string
+	contextFuncs  map[string]ContextFunc
 }
 
 // HTML gerencia a renderização de páginas html
@@ -18,21 +33,48 @@ var HTML templateConfig = templateConfig{
 }
 
 // Configure sets values to be used over the HTML rendering
-// It receives the Asset URL path to be used when the page is rendered, the root views dir and the asset mapping to be parsed when some asset is provided
-func (tc *templateConfig) Configure(assetsUrlPath string, viewsDir string, assetsMapping map[string]string) {
+// It receives the Asset URL path to be used when the page is rendered, the file system rooted at the views dir and the asset mapping to be parsed when some asset is provided
+func (tc *templateConfig) Configure(assetsUrlPath string, views fs.FS, assetsMapping map[string]string) {
 	tc.assetsUrlPath = assetsUrlPath
-	tc.viewsDir = viewsDir
+	tc.views = views
 	tc.assetsMapping = assetsMapping
 }
 
+// AddContextFunc makes fn available to the templates under name, evaluated with the Render context.
+// It must be called during the setup, before any page is rendered.
+func (tc *templateConfig) AddContextFunc(name string, fn ContextFunc) {
+	if tc.contextFuncs == nil {
+		tc.contextFuncs = map[string]ContextFunc{}
+	}
+	tc.contextFuncs[name] = fn
+}
+
 // Render builds an HTML with the sent data
 // It receives a view to rendered, that must be on Go Template format, along with the data to be include and returns an []byte and an error.
 // This function aims to encapsulate the HTML rendering implementation and provide functions to be used on the template file
-func (tc templateConfig) Render(view string, data any) ([]byte, error) {
+// The rendering is recorded as a child span of the one carried by ctx.
+func (tc templateConfig) Render(ctx context.Context, view string, data any) (_ []byte, err error) {
+	_, span := tracer.Start(ctx, "render "+view, trace.WithAttributes(attribute.String("template.name", view)))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if tc.views == nil {
+		return nil, errors.New("renderer: views not configured")
+	}
+
 	baseFile := path.Base(view)
-	tmpl, err := template.New(baseFile).Funcs(template.FuncMap{
+	funcs := template.FuncMap{
 		"assetsPath": tc.getPathToAssets,
-	}).ParseFiles(path.Join(tc.viewsDir, view))
+	}
+	for name, fn := range tc.contextFuncs {
+		funcs[name] = func(args ...string) any { return fn(ctx, args...) }
+	}
+	tmpl, err := template.New(baseFile).Funcs(funcs).ParseFS(tc.views, view)
 
 	if err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
 package renderer
 
 import (
+	"context"
 	"os"
 	"path"
 	"strings"
@@ -9,25 +10,53 @@ import (
 
 func TestRederingHTML(t *testing.T) {
 	tempDir := t.TempDir()
-	HTML.Configure("/assets", tempDir, map[string]string{"some_path/something.asset": "some_path/other_path/random.asset"})
+	HTML.Configure("/assets", os.DirFS(tempDir), map[string]string{"some_path/something.asset": "some_path/other_path/random.asset"})
 	setupHTMLFile(t, path.Join(tempDir, "test.html"))
 
 	t.Run("render inner variables", func(t *testing.T) {
-		page, _ := HTML.Render("test.html", map[string]string{"TestContent": "some content"})
+		page, _ := HTML.Render(context.Background(), "test.html", map[string]string{"TestContent": "some content"})
 		if !strings.Contains(string(page), "some content") {
 			t.Error("Expected: contains rendered value \"some content\", got: nothing")
 		}
 	})
 
 	t.Run("render asset path", func(t *testing.T) {
-		page, _ := HTML.Render("test.html", map[string]string{"TestContent": "some content"})
+		page, _ := HTML.Render(context.Background(), "test.html", map[string]string{"TestContent": "some content"})
 		if !strings.Contains(string(page), "<script src=/assets/some_path/other_path/random.asset></script>") {
 			t.Error("Expected: contains rendered asset path \"/assets/some_path/other_path/random.asset\", got: nothing")
 		}
 	})
 
+	t.Run("render context functions", func(t *testing.T) {
+		type key struct{}
+		os.WriteFile(path.Join(tempDir, "nonce.html"), []byte(`<script nonce="{{ cspNonce }}"></script>`), 0644)
+		HTML.AddContextFunc("cspNonce", func(ctx context.Context, _ ...string) any { return ctx.Value(key{}) })
+
+		page, err := HTML.Render(context.WithValue(context.Background(), key{}, "abc123"), "nonce.html", nil)
+		if err != nil {
+			t.Fatalf("Expected: no error, Got: %s", err)
+		}
+		if !strings.Contains(string(page), `nonce="abc123"`) {
+			t.Errorf("Expected: contains nonce=\"abc123\", Got: %s", page)
+		}
+	})
+
+	t.Run("render context functions with arguments", func(t *testing.T) {
+		type key struct{}
+		os.WriteFile(path.Join(tempDir, "can.html"), []byte(`{{ if can "edit" }}edit{{ end }}{{ if can "delete" }}delete{{ end }}`), 0644)
+		HTML.AddContextFunc("can", func(ctx context.Context, args ...string) any { return args[0] == ctx.Value(key{}) })
+
+		page, err := HTML.Render(context.WithValue(context.Background(), key{}, "edit"), "can.html", nil)
+		if err != nil {
+			t.Fatalf("Expected: no error, Got: %s", err)
+		}
+		if string(page) != "edit" {
+			t.Errorf("Expected: edit, Got: %s", page)
+		}
+	})
+
 	t.Run("returns an error when file does not exist", func(t *testing.T) {
-		_, err := HTML.Render("missing.html", map[string]string{"TestContent": "some content"})
+		_, err := HTML.Render(context.Background(), "missing.html", map[string]string{"TestContent": "some content"})
 		if err == nil {
 			t.Error("Expected: some error, got: nothing")
 		}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Options holds the HTTP server settings.
+type Options struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// ShutdownTimeout is the deadline to drain in-flight requests and run the shutdown hooks.
+	ShutdownTimeout time.Duration
+}
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Server wraps an http.Server adding graceful shutdown and ordered release of resources.
+type Server struct {
+	httpServer      *http.Server
+	shutdownTimeout time.Duration
+	hooks           []shutdownHook
+}
+
+// New builds a Server that serves handler using the given options.
+func New(handler http.Handler, options Options) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              options.Addr,
+			Handler:           handler,
+			ReadTimeout:       options.ReadTimeout,
+			ReadHeaderTimeout: options.ReadHeaderTimeout,
+			WriteTimeout:      options.WriteTimeout,
+			IdleTimeout:       options.IdleTimeout,
+			MaxHeaderBytes:    options.MaxHeaderBytes,
+		},
+		shutdownTimeout: options.ShutdownTimeout,
+	}
+}
+
+// OnShutdown registers a hook executed after the server stops accepting requests and drains the in-flight ones.
+// Hooks run in registration order, so register them from the most dependent resource to the least dependent one
+// (e.g. background workers before the database pool they use).
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Run listens on the configured address and serves until ctx is done, then shuts down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on listener until ctx is done, then shuts down gracefully.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.httpServer.Serve(listener)
+	}()
+
+	var errs []error
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("http server: %w", err))
+	}
+	for _, hook := range s.hooks {
+		if err := hook.fn(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hook.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
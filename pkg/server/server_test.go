@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestServerGracefulShutdown(t *testing.T) {
+	requestStarted := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requestStarted)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+
+	srv := New(handler, Options{ShutdownTimeout: time.Second})
+	var calls []string
+	srv.OnShutdown("workers", func(ctx context.Context) error {
+		calls = append(calls, "workers")
+		return nil
+	})
+	srv.OnShutdown("database", func(ctx context.Context) error {
+		calls = append(calls, "database")
+		return errors.New("already closed")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(ctx, listener) }()
+
+	responseBody := make(chan string, 1)
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responseBody <- err.Error()
+			return
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		responseBody <- string(body)
+	}()
+
+	<-requestStarted
+	cancel()
+
+	t.Run("drains in-flight requests", func(t *testing.T) {
+		if body := <-responseBody; body != "done" {
+			t.Errorf("Expected: done, Got: %s", body)
+		}
+	})
+
+	err = <-serveErr
+
+	t.Run("runs the shutdown hooks in registration order", func(t *testing.T) {
+		if !slices.Equal(calls, []string{"workers", "database"}) {
+			t.Errorf("Expected: [workers database], Got: %v", calls)
+		}
+	})
+
+	t.Run("returns the hooks errors", func(t *testing.T) {
+		if err == nil || err.Error() != "database: already closed" {
+			t.Errorf("Expected: database: already closed, Got: %v", err)
+		}
+	})
+}
+
+func TestServerOptions(t *testing.T) {
+	srv := New(http.NotFoundHandler(), Options{
+		Addr:              ":9090",
+		ReadTimeout:       time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      3 * time.Second,
+		IdleTimeout:       4 * time.Second,
+		MaxHeaderBytes:    1024,
+	})
+
+	httpServer := srv.httpServer
+	if httpServer.Addr != ":9090" || httpServer.ReadTimeout != time.Second || httpServer.ReadHeaderTimeout != 2*time.Second ||
+		httpServer.WriteTimeout != 3*time.Second || httpServer.IdleTimeout != 4*time.Second || httpServer.MaxHeaderBytes != 1024 {
+		t.Errorf("http.Server built with unexpected options: %+v", httpServer)
+	}
+}
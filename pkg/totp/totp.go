@@ -0,0 +1,110 @@
+// Package totp implements time-based one-time passwords (RFC 6238) as used by authenticator apps:
+// HMAC-SHA1, 6 digits and 30-second steps.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits is the length of the generated codes.
+	Digits = 6
+	// modulo is 10^Digits.
+	modulo = 1_000_000
+	// Period is the duration of each time step.
+	Period = 30 * time.Second
+	// secretLength is the secret size in bytes, the HMAC-SHA1 output size recommended by RFC 4226.
+	secretLength = 20
+)
+
+// ErrInvalidSecret is returned when a secret is not valid base32.
+var ErrInvalidSecret = errors.New("totp: invalid secret")
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random secret, base32 encoded without padding.
+func GenerateSecret() string {
+	secret := make([]byte, secretLength)
+	rand.Read(secret)
+	return encoding.EncodeToString(secret)
+}
+
+// Step returns the time step of t.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code of secret for the time step.
+func Code(secret string, step int64) (string, error) {
+	key, err := decode(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, step), nil
+}
+
+// Validate checks code against the steps from skew steps before t to skew steps after it, tolerating clock drift
+// between the server and the authenticator. It returns the matched step, which callers should record to refuse
+// the same code again.
+func Validate(secret string, input string, t time.Time, skew int64) (int64, bool, error) {
+	key, err := decode(secret)
+	if err != nil {
+		return 0, false, err
+	}
+	input = strings.ReplaceAll(input, " ", "")
+	if len(input) != Digits {
+		return 0, false, nil
+	}
+
+	current := Step(t)
+	for step := current - skew; step <= current+skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(code(key, step)), []byte(input)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// URI returns the otpauth:// URI read by authenticator apps, usually shown as a QR code.
+func URI(issuer string, account string, secret string) string {
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(Digits)},
+		"period":    {fmt.Sprint(int(Period / time.Second))},
+	}
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+func decode(secret string) ([]byte, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
+
+// code is the HOTP value (RFC 4226) of the step, truncated to Digits.
+func code(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%modulo)
+}
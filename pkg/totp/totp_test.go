@@ -0,0 +1,96 @@
+package totp
+
+import (
+	"encoding/base32"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// The RFC vectors have 8 digits; the 6-digit codes are their last 6 digits.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("Expected: no error, Got: %s", err)
+		}
+		if code != expected {
+			t.Errorf("Expected: %s at %d, Got: %s", expected, unix, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current, _ := Code(rfcSecret, Step(now))
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	old, _ := Code(rfcSecret, Step(now)-2)
+
+	tests := []struct {
+		name  string
+		input string
+		valid bool
+		step  int64
+	}{
+		{name: "current step", input: current, valid: true, step: Step(now)},
+		{name: "with spaces", input: current[:3] + " " + current[3:], valid: true, step: Step(now)},
+		{name: "previous step within the skew", input: previous, valid: true, step: Step(now) - 1},
+		{name: "step outside the skew", input: old, valid: false},
+		{name: "wrong length", input: "12345", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, valid, err := Validate(rfcSecret, tt.input, now, 1)
+			if err != nil {
+				t.Fatalf("Expected: no error, Got: %s", err)
+			}
+			if valid != tt.valid || step != tt.step {
+				t.Errorf("Expected: %v at step %d, Got: %v at step %d", tt.valid, tt.step, valid, step)
+			}
+		})
+	}
+
+	if _, _, err := Validate("not base32!", current, now, 1); !errors.Is(err, ErrInvalidSecret) {
+		t.Errorf("Expected: %s, Got: %v", ErrInvalidSecret, err)
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret := GenerateSecret()
+	if len(secret) != 32 || secret == GenerateSecret() {
+		t.Errorf("Expected: a random 32-character secret, Got: %q", secret)
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Errorf("Expected: a usable secret, Got: %s", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("lucienne", "ana@example.com", "JBSWY3DPEHPK3PXP"))
+	if err != nil {
+		t.Fatalf("Expected: a valid URI, Got: %s", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/lucienne:ana@example.com" {
+		t.Errorf("Expected: otpauth://totp/lucienne:ana@example.com, Got: %s", uri)
+	}
+	query := uri.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "lucienne" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("Expected: the secret and parameters in the query, Got: %s", uri.RawQuery)
+	}
+	if strings.Contains(uri.String(), " ") {
+		t.Errorf("Expected: an escaped URI, Got: %s", uri)
+	}
+}
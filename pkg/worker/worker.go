@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Group runs named background workers bound to a shared context, so they can be stopped together.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]bool
+	errors  map[string]error
+}
+
+// NewGroup creates an empty Group.
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{
+		ctx:     ctx,
+		cancel:  cancel,
+		running: map[string]bool{},
+		errors:  map[string]error{},
+	}
+}
+
+// Go starts fn in its own goroutine. fn must return when ctx is done.
+func (g *Group) Go(name string, fn func(ctx context.Context) error) {
+	g.mu.Lock()
+	g.running[name] = true
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := fn(g.ctx)
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.running[name] = false
+		if err != nil && !errors.Is(err, context.Canceled) {
+			g.errors[name] = err
+		}
+	}()
+}
+
+// Status reports, for every worker started, whether it is still running.
+func (g *Group) Status() map[string]bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return maps.Clone(g.running)
+}
+
+// Err returns the error a worker stopped with, ignoring the cancellation caused by Stop.
+func (g *Group) Err(name string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.errors[name]
+}
+
+// Check returns an error listing the workers that are no longer running, to be used as a health check.
+func (g *Group) Check(_ context.Context) error {
+	var stopped []string
+	for name, running := range g.Status() {
+		if !running {
+			stopped = append(stopped, name)
+		}
+	}
+	if len(stopped) > 0 {
+		slices.Sort(stopped)
+		return fmt.Errorf("workers not running: %s", strings.Join(stopped, ", "))
+	}
+	return nil
+}
+
+// Stop cancels every worker and waits for them to return or for ctx to be done.
+func (g *Group) Stop(ctx context.Context) error {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
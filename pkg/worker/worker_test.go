@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("stops every worker", func(t *testing.T) {
+		group := NewGroup()
+		group.Go("ticker", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		if !group.Status()["ticker"] {
+			t.Fatalf("Expected: ticker running, Got: %v", group.Status())
+		}
+
+		if err := group.Stop(context.Background()); err != nil {
+			t.Fatalf("Expected: no error, Got: %s", err)
+		}
+		if group.Status()["ticker"] {
+			t.Errorf("Expected: ticker stopped, Got: %v", group.Status())
+		}
+		if err := group.Err("ticker"); err != nil {
+			t.Errorf("Expected: no error after Stop, Got: %s", err)
+		}
+	})
+
+	t.Run("records workers that fail", func(t *testing.T) {
+		group := NewGroup()
+		group.Go("failing", func(ctx context.Context) error {
+			return errors.New("boom")
+		})
+		group.Stop(context.Background())
+
+		if group.Status()["failing"] {
+			t.Errorf("Expected: failing worker stopped, Got: %v", group.Status())
+		}
+		if err := group.Err("failing"); err == nil || err.Error() != "boom" {
+			t.Errorf("Expected: boom, Got: %v", err)
+		}
+	})
+
+	t.Run("returns when the deadline expires", func(t *testing.T) {
+		group := NewGroup()
+		release := make(chan struct{})
+		t.Cleanup(func() { close(release) })
+		group.Go("stuck", func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := group.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected: context.DeadlineExceeded, Got: %v", err)
+		}
+	})
+}
+
+func TestGroupCheck(t *testing.T) {
+	group := NewGroup()
+	group.Go("running", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	t.Cleanup(func() { group.Stop(context.Background()) })
+
+	if err := group.Check(context.Background()); err != nil {
+		t.Errorf("Expected: no error, Got: %s", err)
+	}
+
+	done := make(chan struct{})
+	group.Go("finished", func(ctx context.Context) error {
+		defer close(done)
+		return nil
+	})
+	<-done
+	for group.Status()["finished"] {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := group.Check(context.Background()); err == nil || err.Error() != "workers not running: finished" {
+		t.Errorf("Expected: workers not running: finished, Got: %v", err)
+	}
+}
@@ -48,3 +48,16 @@ func TestApplicationIsDevelopment(t *testing.T) {
 		}
 	})
 }
+
+func TestApplicationRootPathOutsideSourceTree(t *testing.T) {
+	currentPath, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(currentPath) })
+
+	tempDir := t.TempDir()
+	os.Chdir(tempDir)
+
+	config.Application.Configure("")
+	if config.Application.RootPath != tempDir {
+		t.Errorf("Expected: %s, Got: %s", tempDir, config.Application.RootPath)
+	}
+}
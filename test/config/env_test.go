@@ -1,8 +1,10 @@
 package config_test
 
 import (
+	"errors"
 	"lucienne/config"
 	"lucienne/test/test_support"
+	"strings"
 	"testing"
 )
 
@@ -17,18 +19,19 @@ func TestAppEnvWasLoaded(t *testing.T) {
 		}
 	})
 
-	t.Run("panics if AppEnv is not defined", func(t *testing.T) {
+	t.Run("returns an error if AppEnv is not defined", func(t *testing.T) {
 		test_support.SetupEnvVars(t, map[string]string{
 			"APP_ENV": "",
 		})
 
-		defer func() {
-			if r := recover(); r == nil {
-				t.Errorf("Expected: panic, Got: %s", config.EnvVariables.AppEnv)
-			}
-		}()
-
-		config.EnvVariables.Load()
+		err := config.EnvVariables.Load()
+		var loadErr *config.LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("Expected: *config.LoadError, Got: %v", err)
+		}
+		if !strings.Contains(err.Error(), "APP_ENV: is required") {
+			t.Errorf("Expected: error listing APP_ENV, Got: %s", err)
+		}
 	})
 
 	t.Run("returns right value for AppPort", func(t *testing.T) {